@@ -0,0 +1,113 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"io"
+	"strings"
+)
+
+// AppendNote adds the content read from the reader to the end of the note.
+// See addToNote for how the content is handled.
+func AppendNote(db Storager, ns NotestoreClient, title string, r io.Reader, opts NoteOption) error {
+	return addToNote(db, ns, title, r, opts, false)
+}
+
+// PrependNote adds the content read from the reader to the beginning of the note.
+// See addToNote for how the content is handled.
+func PrependNote(db Storager, ns NotestoreClient, title string, r io.Reader, opts NoteOption) error {
+	return addToNote(db, ns, title, r, opts, true)
+}
+
+// addToNote merges the content read from the reader into the note's content.
+// The content is Markdown unless the RawNote option is set. It can start with
+// the same header as the note in the editor, in which case the tags in the header
+// are added to the note. The rest of the header is ignored. See updateNoteContent
+// for how the change is saved.
+func addToNote(db Storager, ns NotestoreClient, title string, r io.Reader, opts NoteOption, prepend bool) error {
+	input := new(Note)
+	if err := parseInput(r, input, opts); err != nil {
+		return err
+	}
+	content, err := noteFragment(input, opts&RawNote != 0)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(content) == "" {
+		return ErrNoContent
+	}
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	return updateNoteContent(db, ns, n, input.Tags, func(body string) string {
+		if prepend {
+			return content + body
+		}
+		return body + content
+	})
+}
+
+// updateNoteContent gets the note's content and replaces the body with the one
+// returned by change. The tags are added to the note's tags. The note before
+// and after the change is saved to the note history. If the server can't be
+// reached, the change is queued and ErrChangeQueued is returned.
+func updateNoteContent(db Storager, ns NotestoreClient, n *Note, tags []*Tag, change func(body string) string) error {
+	err := loadNoteContent(ns, n)
+	if err != nil {
+		return err
+	}
+	if n.Notebook, err = GetNotebook(ns, n.Notebook.GUID); err != nil {
+		return err
+	}
+	// Keep the current content in the history before it's changed.
+	current, err := newNoteSnapshot(n, enmlDocument(n.Body))
+	if err != nil {
+		return err
+	}
+	n.Body = enmlDocument(change(n.Body))
+	n.Tags = mergeTags(n.Tags, tags)
+	err = saveChanges(ns, n, false, false)
+	if err == ErrOffline {
+		err = queueOfflineChange(db, ChangeUpdate, n)
+	}
+	if err != nil && err != ErrChangeQueued {
+		return err
+	}
+	if snapErr := saveEditSnapshots(db, current, n); snapErr != nil {
+		return snapErr
+	}
+	return err
+}
+
+// mergeTags returns the tags with the added tags that aren't already in the list.
+func mergeTags(tags, added []*Tag) []*Tag {
+	for _, t := range added {
+		found := false
+		for _, existing := range tags {
+			if existing.Name == t.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
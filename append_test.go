@@ -0,0 +1,79 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddToNote(t *testing.T) {
+	assert := assert.New(t)
+	newNS := func(saved **Note) *mockNS {
+		note := &Note{Title: "Log", GUID: "GUID", Notebook: &Notebook{GUID: "NB1"}, Tags: []*Tag{&Tag{GUID: "Tag1"}}}
+		ns := nsWithNote(note)
+		ns.getNoteContent = func(guid string) (string, error) {
+			return XMLHeader + "<en-note><div>Existing</div></en-note>", nil
+		}
+		ns.listTags = func() ([]*Tag, error) { return []*Tag{&Tag{GUID: "Tag1", Name: "log"}}, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Work"}, nil }
+		ns.updateNote = func(n *Note) error { *saved = n; return nil }
+		return ns
+	}
+	var snapshots []*NoteSnapshot
+	store := &mockStore{
+		getSearch:        func() ([]*Note, error) { return nil, nil },
+		getNoteSnapshots: func(guid string) ([]*NoteSnapshot, error) { return snapshots, nil },
+		saveNoteSnapshot: func(s *NoteSnapshot) error { snapshots = append(snapshots, s); return nil },
+	}
+
+	t.Run("append", func(t *testing.T) {
+		snapshots = nil
+		var saved *Note
+		err := AppendNote(store, newNS(&saved), "Log", strings.NewReader("**New**\n"), DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><div>Existing</div><p><strong>New</strong></p>\n</en-note>", saved.Body, "Content should be added at the end")
+		assert.Len(snapshots, 2, "The note before and after the change should be saved to the history")
+	})
+	t.Run("prepend", func(t *testing.T) {
+		var saved *Note
+		err := PrependNote(store, newNS(&saved), "Log", strings.NewReader("<p>New</p>"), RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><p>New</p><div>Existing</div></en-note>", saved.Body, "Content should be added at the beginning")
+	})
+	t.Run("add tags from header", func(t *testing.T) {
+		var saved *Note
+		err := AppendNote(store, newNS(&saved), "Log", strings.NewReader("---\ntitle: Ignored\ntags: log, build\n---\nNew\n"), DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Log", saved.Title, "Title should not be changed")
+		assert.Equal([]string{"log", "build"}, tagNames(saved.Tags), "New tags should be added")
+	})
+	t.Run("no content", func(t *testing.T) {
+		var saved *Note
+		err := AppendNote(store, newNS(&saved), "Log", strings.NewReader("\n"), DefaultNoteOption)
+		assert.Equal(ErrNoContent, err, "Wrong error returned")
+		assert.Nil(saved, "Note should not be updated")
+	})
+	t.Run("invalid raw content", func(t *testing.T) {
+		var saved *Note
+		err := AppendNote(store, newNS(&saved), "Log", strings.NewReader("<p>Unclosed"), RawNote)
+		assert.IsType(&ENMLError{}, err, "Should return a validation error")
+	})
+}
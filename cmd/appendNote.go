@@ -0,0 +1,97 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var appendNoteCmd = &cobra.Command{
+	Use:   "append \"note title\"|INDEX --stdin",
+	Short: "Add content to the end of a note.",
+	Long: `
+Append adds the content read from stdin to the end of the note,
+for example:
+
+  date | clinote note append "Backup log" --stdin
+
+The content is Markdown unless the raw flag is given. It can start
+with the same header as the note in the editor, in which case the
+tags in the header are added to the note.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addToNote(cmd, args, false)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(appendNoteCmd)
+	addToNoteFlags(appendNoteCmd)
+}
+
+// addToNoteFlags adds the flags used by the append and prepend commands.
+func addToNoteFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("stdin", false, "Read the content from stdin.")
+	cmd.Flags().Bool("raw", false, "The content is ENML instead of Markdown.")
+}
+
+// addToNote adds the content read from stdin to the note given by the
+// arguments. If prepend is true, the content is added to the beginning.
+func addToNote(cmd *cobra.Command, args []string, prepend bool) {
+	stdin, err := cmd.Flags().GetBool("stdin")
+	if err != nil {
+		fmt.Println("Error when parsing stdin flag:", err)
+		return
+	}
+	if len(args) != 1 || !stdin {
+		cmd.Usage()
+		return
+	}
+	raw, err := cmd.Flags().GetBool("raw")
+	if err != nil {
+		fmt.Println("Error when parsing raw parameter:", err)
+		return
+	}
+	opts := clinote.DefaultNoteOption
+	if raw {
+		opts |= clinote.RawNote
+	}
+	client := defaultClient()
+	defer client.Close()
+	ns, err := getNoteStore(client)
+	if err != nil {
+		fmt.Println("Failed to get notestore:", err)
+		return
+	}
+	add := clinote.AppendNote
+	if prepend {
+		add = clinote.PrependNote
+	}
+	err = add(client.Config.Store(), ns, args[0], os.Stdin, opts)
+	if err == clinote.ErrChangeQueued {
+		fmt.Println(err)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error when adding to the note:", err)
+		os.Exit(1)
+	}
+}
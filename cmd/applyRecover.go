@@ -0,0 +1,57 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var applyRecoverCmd = &cobra.Command{
+	Use:   "apply ID",
+	Short: "Save a recovery point.",
+	Long: `
+Apply saves the note in the recovery point to the server without
+opening it in the editor. The recovery point is removed once the
+note has been saved.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		index, ok := parseRecoveryIndex(args)
+		if !ok {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		err = clinote.ApplyRecoveryPoint(client.Config.Store(), ns, index)
+		if err != nil {
+			fmt.Println("Error when saving the recovery point:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	recoverCmd.AddCommand(applyRecoverCmd)
+}
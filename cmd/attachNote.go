@@ -0,0 +1,64 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var attachNoteCmd = &cobra.Command{
+	Use:   "attach \"note title\" FILE...",
+	Short: "Attach files to a note.",
+	Long: `
+Attach uploads the files and attaches them to the note.
+The files are added to the end of the note.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			fmt.Println("Error, a note and at least one file has to be given.")
+			return
+		}
+		resources := make([]*clinote.Resource, 0, len(args)-1)
+		for _, fp := range args[1:] {
+			r, err := clinote.NewResourceFromFile(fp)
+			if err != nil {
+				fmt.Println("Error when reading the file:", err)
+				os.Exit(1)
+			}
+			resources = append(resources, r)
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := client.GetNoteStore()
+		if err != nil {
+			return
+		}
+		err = clinote.AttachResources(client.Config.Store(), ns, args[0], resources)
+		if err != nil {
+			fmt.Println("Error when attaching the files:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(attachNoteCmd)
+}
@@ -0,0 +1,70 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var checkTodoCmd = &cobra.Command{
+	Use:   "check \"note title\"|INDEX N",
+	Short: "Check or uncheck a checkbox in a note.",
+	Long: `
+Check checks the checkbox N in the note, or unchecks it if it's
+already checked. N is the index from the todo list command. Only
+the checkbox is changed, the rest of the note is kept as is.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Println("Error when parsing the checkbox index:", err)
+			os.Exit(1)
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		item, err := clinote.CheckTodo(client.Config.Store(), ns, args[0], index)
+		if err == clinote.ErrChangeQueued {
+			fmt.Println(err)
+		} else if err != nil {
+			fmt.Println("Error when checking the checkbox:", err)
+			os.Exit(1)
+		}
+		state := "Unchecked:"
+		if item.Checked {
+			state = "Checked:"
+		}
+		fmt.Println(state, item.Text)
+	},
+}
+
+func init() {
+	todoCmd.AddCommand(checkTodoCmd)
+}
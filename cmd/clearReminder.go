@@ -0,0 +1,55 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var clearReminderCmd = &cobra.Command{
+	Use:   "clear \"note title\"|INDEX",
+	Short: "Remove the reminder from a note.",
+	Long: `
+Clear removes the reminder from the note. The note can be given by
+its title or by the index from the reminder list command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		if err = clinote.ClearReminder(client.Config.Store(), ns, args[0]); err != nil {
+			fmt.Println("Error when clearing the reminder:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	reminderCmd.AddCommand(clearReminderCmd)
+}
@@ -29,7 +29,7 @@ var deleteNoteCmd = &cobra.Command{
 	Use:   "delete \"note title\"",
 	Short: "Delete note.",
 	Long: `Moves the note into the trash. The note may still be undeleted, unless it is expunged.
-To expunge the note you need to use the official client or the web client.`,
+Notes in the trash are restored and expunged with the trash commands.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			fmt.Println("Error, a note title has to be given")
@@ -42,7 +42,7 @@ To expunge the note you need to use the official client or the web client.`,
 		}
 		client := defaultClient()
 		defer client.Close()
-		ns, err := client.GetNoteStore()
+		ns, err := getNoteStore(client)
 		if err != nil {
 			return
 		}
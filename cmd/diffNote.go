@@ -0,0 +1,72 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var diffNoteCmd = &cobra.Command{
+	Use:   "diff \"note title\"",
+	Short: "Show the changes between versions of a note.",
+	Long: `
+Diff shows the changes between two versions of the note as a
+unified diff of the markdown content. The versions are given
+with the version number from the history command. By default
+the most recent prior version is compared with the current note.
+Use 0 for the current note.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		from, err := cmd.Flags().GetInt32("from")
+		if err != nil {
+			fmt.Println("Error when parsing from version:", err)
+			return
+		}
+		to, err := cmd.Flags().GetInt32("to")
+		if err != nil {
+			fmt.Println("Error when parsing to version:", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		diff, err := clinote.DiffNoteVersions(client.Config.Store(), ns, args[0], from, to)
+		if err != nil {
+			fmt.Println("Error when comparing the note versions:", err)
+			os.Exit(1)
+		}
+		fmt.Print(diff)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(diffNoteCmd)
+	diffNoteCmd.Flags().Int32("from", clinote.PreviousVersion, "Version to compare from, the most recent prior version if not set.")
+	diffNoteCmd.Flags().Int32("to", clinote.CurrentVersion, "Version to compare to, the current note if not set.")
+}
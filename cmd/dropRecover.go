@@ -0,0 +1,49 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var dropRecoverCmd = &cobra.Command{
+	Use:   "drop ID",
+	Short: "Remove a recovery point.",
+	Long:  `Removes the recovery point without saving the note.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		index, ok := parseRecoveryIndex(args)
+		if !ok {
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		err := clinote.DropRecoveryPoint(client.Config.Store(), index)
+		if err != nil {
+			fmt.Println("Error when removing the recovery point:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	recoverCmd.AddCommand(dropRecoverCmd)
+}
@@ -18,8 +18,10 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -38,7 +40,16 @@ the note content.
 To change to title, the title flag can be used.
 
 The note can be moved to another notebook by defining the new notebook
-with the notebook flag.`,
+with the notebook flag.
+
+If the note is changed on the server while it's edited, the changes are
+merged. Conflicting changes are marked and the editor is reopened.
+If the content is not valid ENML, the editor is reopened with the error
+written above the note header.
+
+Notes that failed to save can be edited again with the recover flag. The
+recovery point can be given as the argument, otherwise the recovery points
+are listed and one can be selected.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		raw, err := cmd.Flags().GetBool("raw")
 		if err != nil {
@@ -61,7 +72,7 @@ with the notebook flag.`,
 		}
 		client := defaultClient()
 		defer client.Close()
-		ns, err := client.GetNoteStore()
+		ns, err := getNoteStore(client)
 		if err != nil {
 			fmt.Println("Failed to get notestore:", err)
 			return
@@ -71,8 +82,18 @@ with the notebook flag.`,
 			opts = opts | clinote.RawNote
 		}
 		if recover {
+			id := ""
+			if len(args) == 1 {
+				id = args[0]
+			} else {
+				id = selectRecoveryPoint(client.Config.Store())
+			}
 			c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
-			err := clinote.EditNote(c, "", opts|clinote.UseRecoveryPointNote)
+			err := clinote.EditNote(c, id, opts|clinote.UseRecoveryPointNote)
+			if err == clinote.ErrChangeQueued {
+				fmt.Println(err)
+				return
+			}
 			if err != nil {
 				fmt.Println("Error when edit recovery note:", err)
 				os.Exit(1)
@@ -93,6 +114,10 @@ with the notebook flag.`,
 		if title == "" && notebook == "" {
 			c := clinote.NewClient(client.Config, client.Config.Store(), ns, clinote.DefaultClientOptions)
 			err := clinote.EditNote(c, args[0], opts)
+			if err == clinote.ErrChangeQueued {
+				fmt.Println(err)
+				return
+			}
 			if err != nil {
 				fmt.Println("Error when editing the note:", err)
 				os.Exit(1)
@@ -101,6 +126,24 @@ with the notebook flag.`,
 	},
 }
 
+// selectRecoveryPoint lists the recovery points and asks the user to select one.
+// If only one recovery point exists, it's used without asking.
+func selectRecoveryPoint(db clinote.Storager) string {
+	rps, err := clinote.GetRecoveryPoints(db)
+	if err != nil {
+		fmt.Println("Error when getting the recovery points:", err)
+		os.Exit(1)
+	}
+	if len(rps) < 2 {
+		return ""
+	}
+	clinote.WriteRecoveryListing(os.Stdout, rps)
+	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Print("Select recovery point: ")
+	scanner.Scan()
+	return strings.TrimSpace(scanner.Text())
+}
+
 func init() {
 	noteCmd.AddCommand(editNoteCmd)
 	editNoteCmd.Flags().StringP("title", "t", "", "Change the note title to.")
@@ -33,14 +33,19 @@ Edit a notebook. The notebook's name can be changed using the
 name flag.
 
 To move the notebook to another stack, use the stack flag to
-define the new stack.`,
+define the new stack.
+
+The default flag makes the notebook the default notebook. The
+publish flag publishes the notebook to the public with the URI
+as the last part of its public URL and the unpublish flag stops
+publishing it. Changes not allowed for the notebook are rejected.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			fmt.Println("Error, a notebook has to be given.")
 			return
 		}
 		change := false
-		notebook := new(clinote.Notebook)
+		notebook := new(clinote.NotebookChanges)
 		name, err := cmd.Flags().GetString("name")
 		if err != nil {
 			fmt.Println("Error when parsing new notebook name:", err)
@@ -61,6 +66,31 @@ define the new stack.`,
 			change = true
 		}
 
+		if notebook.Default, err = cmd.Flags().GetBool("default"); err != nil {
+			fmt.Println("Error when parsing the default flag:", err)
+			return
+		}
+		uri, err := cmd.Flags().GetString("publish")
+		if err != nil {
+			fmt.Println("Error when parsing the public URI:", err)
+			return
+		}
+		unpublish, err := cmd.Flags().GetBool("unpublish")
+		if err != nil {
+			fmt.Println("Error when parsing the unpublish flag:", err)
+			return
+		}
+		if uri != "" && unpublish {
+			fmt.Println("Error, the publish and unpublish flags can't be combined.")
+			return
+		}
+		if uri != "" || unpublish {
+			publish := !unpublish
+			notebook.Publish = &publish
+			notebook.PublicURI = uri
+		}
+		change = change || notebook.Default || notebook.Publish != nil
+
 		if !change {
 			fmt.Println("No changes detected, aborting.")
 			return
@@ -83,4 +113,7 @@ func init() {
 	notebookCmd.AddCommand(editNotebookCmd)
 	editNotebookCmd.Flags().StringP("name", "n", "", "Change notebook name to.")
 	editNotebookCmd.Flags().StringP("stack", "s", "", "Change notebook stack to.")
+	editNotebookCmd.Flags().Bool("default", false, "Make the notebook the default notebook.")
+	editNotebookCmd.Flags().String("publish", "", "Publish the notebook with the public URI.")
+	editNotebookCmd.Flags().Bool("unpublish", false, "Stop publishing the notebook.")
 }
@@ -0,0 +1,88 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export notes.",
+	Long: `
+Export writes the notes in a notebook to a file. If no notebook
+is given, all notes are exported.
+
+The notes are exported in Evernote's ENEX format, including
+the note's tags and attachments. If no file is given, the
+notes are written to stdout.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing notebook name:", err)
+			return
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			fmt.Println("Error when parsing the format:", err)
+			return
+		}
+		output, err := cmd.Flags().GetString("file")
+		if err != nil {
+			fmt.Println("Error when parsing the output file:", err)
+			return
+		}
+		exportNotes(notebook, format, output)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("notebook", "b", "", "The notebook to export, if not set all notes are exported.")
+	exportCmd.Flags().StringP("format", "f", clinote.ENEXFormat, "The export format.")
+	exportCmd.Flags().StringP("file", "o", "", "The file to write the notes to.")
+}
+
+func exportNotes(notebook, format, output string) {
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			fmt.Println("Error when creating the output file:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	err = clinote.ExportNotebook(w, client.Config.Store(), ns, notebook, format)
+	if err != nil {
+		fmt.Println("Error when exporting the notes:", err)
+		os.Exit(1)
+	}
+}
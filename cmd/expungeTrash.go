@@ -0,0 +1,67 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var expungeTrashCmd = &cobra.Command{
+	Use:   "expunge \"note title\"|INDEX",
+	Short: "Permanently remove a note in the trash.",
+	Long: `
+Expunge permanently removes the note in the trash. The note
+can be given by its title or by the index from the trash list
+command. An expunged note can't be restored.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		db := client.Config.Store()
+		n, err := clinote.GetTrashNote(db, ns, args[0])
+		if err != nil {
+			fmt.Println("Error when getting the note:", err)
+			os.Exit(1)
+		}
+		if !confirm(cmd, "Permanently remove \""+n.Title+"\"?") {
+			return
+		}
+		err = clinote.ExpungeTrashNote(ns, n)
+		if err != nil {
+			fmt.Println("Error when expunging the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(expungeTrashCmd)
+	expungeTrashCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation.")
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/evernote"
 	"github.com/TcM1911/clinote/storage"
+	"github.com/spf13/cobra"
 )
 
 func defaultClient() *evernote.Client {
@@ -17,6 +23,64 @@ func defaultClient() *evernote.Client {
 	return evernote.NewClient(cfg)
 }
 
+// getNoteStore returns the notestore for the user. The local note store is used
+// if the offline flag is set or if the server can't be reached and the notes have
+// been synced. Changes made to the local note store are queued until the next sync.
+func getNoteStore(client *evernote.Client) (clinote.NotestoreClient, error) {
+	db := client.Config.Store()
+	if offline {
+		return clinote.NewLocalNotestore(db), nil
+	}
+	ns, err := client.GetNoteStore()
+	if err != nil && err != evernote.ErrNotLoggedIn && clinote.HasLocalCopy(db) {
+		return clinote.NewLocalNotestore(db), nil
+	}
+	return ns, err
+}
+
+// getFormatter returns the formatter for the output format flag. The
+// program exits if the format is not supported.
+func getFormatter() clinote.Formatter {
+	f, err := clinote.NewFormatter(output)
+	if err != nil {
+		fmt.Println("Error when parsing the output format:", err)
+		os.Exit(1)
+	}
+	return f
+}
+
+// getListingFormatter returns the formatter for the format flag if it's set.
+// Otherwise the formatter for the output format flag is returned.
+func getListingFormatter(cmd *cobra.Command) clinote.Formatter {
+	tmpl, err := cmd.Flags().GetString("format")
+	if err != nil {
+		fmt.Println("Error when parsing the format:", err)
+		os.Exit(1)
+	}
+	if tmpl == "" {
+		return getFormatter()
+	}
+	f, err := clinote.NewTemplateFormatter(tmpl)
+	if err != nil {
+		fmt.Println("Error when parsing the format:", err)
+		os.Exit(1)
+	}
+	return f
+}
+
+// confirm asks the user to confirm the action. True is returned if the
+// user answers yes or if the yes flag is set.
+func confirm(cmd *cobra.Command, question string) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Print(question + " [y/N]: ")
+	scanner.Scan()
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes"
+}
+
 func newClient(opts clinote.ClientOption) *clinote.Client {
 	cfg := new(clinote.DefaultConfig)
 	db, err := storage.Open(cfg.GetConfigFolder())
@@ -26,7 +90,7 @@ func newClient(opts clinote.ClientOption) *clinote.Client {
 	cfg.DB = db
 	cfg.UDB = db
 	ec := evernote.NewClient(cfg)
-	ns, err := ec.GetNoteStore()
+	ns, err := getNoteStore(ec)
 	if err != nil {
 		panic("Error when getting notestore: " + err.Error())
 	}
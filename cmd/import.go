@@ -0,0 +1,75 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE.enex",
+	Short: "Import notes.",
+	Long: `
+Import creates a new note for each note in the ENEX file. The
+original created and updated times, tags and attachments are
+kept.
+
+If no notebook is given, the default notebook will be used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, an ENEX file has to be given.")
+			return
+		}
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing notebook name:", err)
+			return
+		}
+		importNotes(args[0], notebook)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringP("notebook", "b", "", "The notebook to import the notes to, if not set the default notebook will be used.")
+}
+
+func importNotes(fp, notebook string) {
+	f, err := os.Open(fp)
+	if err != nil {
+		fmt.Println("Error when opening the file:", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	n, err := clinote.ImportNotes(f, client.Config.Store(), ns, notebook)
+	fmt.Printf("Imported %d notes.\n", n)
+	if err != nil {
+		fmt.Println("Error when importing the notes:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+// journalDateFormat is the layout of the date flag.
+const journalDateFormat = "2006-01-02"
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Open the journal note for the day.",
+	Long: `
+Journal opens the journal note for today in the editor. If the
+note doesn't exist, it's created. Another day can be opened with
+the date flag.
+
+With the append flag, the text is added as a bullet with the
+current time to the end of the journal note, without opening the
+editor, for example:
+
+  clinote journal --append "Deployed the new release"
+
+The title of the journal notes is set with "user set
+journal-title" and the notebook with "user set journal-notebook".
+The title is a template that can use {{.Date}}, {{.Week}} and
+{{.Year}}. The default title is "` + clinote.DefaultJournalTitle + `".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		d, err := cmd.Flags().GetString("date")
+		if err != nil {
+			fmt.Println("Error when parsing the date:", err)
+			return
+		}
+		date := time.Now()
+		if d != "" {
+			date, err = time.ParseInLocation(journalDateFormat, d, time.Local)
+			if err != nil {
+				fmt.Println("Error when parsing the date, expected the format " + journalDateFormat)
+				return
+			}
+		}
+		text, err := cmd.Flags().GetString("append")
+		if err != nil {
+			fmt.Println("Error when parsing the text:", err)
+			return
+		}
+		openJournal(date, text)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(journalCmd)
+	journalCmd.Flags().StringP("date", "d", "", "The date of the journal note as "+journalDateFormat+", if not set today is used.")
+	journalCmd.Flags().StringP("append", "a", "", "Append the text to the journal note instead of opening the editor.")
+}
+
+func openJournal(date time.Time, text string) {
+	client := newClient(clinote.DefaultClientOptions)
+	defer client.Store.Close()
+	var err error
+	if text != "" {
+		err = clinote.AppendJournal(client, date, text, time.Now())
+	} else {
+		err = clinote.EditJournal(client, date)
+	}
+	if err == clinote.ErrChangeQueued {
+		fmt.Println(err)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error when updating the journal:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var listAttachmentsCmd = &cobra.Command{
+	Use:   "attachments \"note title\"",
+	Short: "List the note's attachments.",
+	Long: `
+Attachments lists the files attached to the note.
+
+The attachments can be saved to a folder by using the
+extract flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			fmt.Println("Error, a note has to be given.")
+			return
+		}
+		dir, err := cmd.Flags().GetString("extract")
+		if err != nil {
+			fmt.Println("Error when parsing the extract folder:", err)
+			return
+		}
+		listAttachments(args[0], dir)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(listAttachmentsCmd)
+	listAttachmentsCmd.Flags().StringP("extract", "x", "", "Save the attachments to the folder.")
+}
+
+func listAttachments(title, dir string) {
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	resources, err := clinote.GetNoteResources(client.Config.Store(), ns, title)
+	if err != nil {
+		fmt.Println("Error when getting the attachments:", err)
+		os.Exit(1)
+	}
+	if dir == "" {
+		clinote.WriteResourceListing(os.Stdout, resources)
+		return
+	}
+	if err = os.MkdirAll(dir, os.ModeDir|0700); err != nil {
+		fmt.Println("Error when creating the folder:", err)
+		os.Exit(1)
+	}
+	for _, r := range resources {
+		fp, err := clinote.ExtractResource(ns, r, dir)
+		if err != nil {
+			fmt.Printf("Error when saving %s: %s\n", r.Filename, err)
+			os.Exit(1)
+		}
+		fmt.Println(fp)
+	}
+}
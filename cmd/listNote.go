@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -36,34 +37,128 @@ to be used. The search can be restricted to a notebook
 by using the notebook flag.
 
 Count can be used to restrict the maximum number of notes
-returned.
+returned. Later pages of the result can be listed with the
+page flag or by giving the offset of the first note. The all
+flag lists all the matching notes. The notes are requested
+count notes at a time and are shown as they are received.
 
 If no search term is given, a wild card search will be used.
-The notes will be sorted by the modified time.`,
+The notes will be sorted by the modified time, the most recent
+first. The sort flag can be used to sort by title, created,
+updated or relevance and the asc flag reverses the order.
+
+The search can be restricted to notes with all the given tags
+and to notes created or updated within a date range. Dates are
+given as YYYY-MM-DD. The after flags include the given date and
+the before flags exclude it.
+
+The trash flag searches the notes in the trash instead.
+
+The output flag writes the notes as json, jsonl, csv, tsv or yaml
+instead of a table. The format flag writes each note using a Go
+template, for example:
+
+  --format '{{.Title}}\t{{.Notebook.Name}}\t{{.Updated | date}}'
+
+The template is executed on the note and can use the functions
+date, truncate and join:
+
+  {{.Updated | date}} or {{date "2006-01-02 15:04" .Updated}}
+  {{.Title | truncate 20}}
+  {{.Tags | join ", "}}`,
 	Run: func(cmd *cobra.Command, args []string) {
-		findNotes(cmd, args)
+		trash, err := cmd.Flags().GetBool("trash")
+		if err != nil {
+			fmt.Println("Error when parsing trash flag:", err)
+			return
+		}
+		findNotes(cmd, trash)
 	},
 }
 
 func init() {
 	noteCmd.AddCommand(listNoteCmd)
-	listNoteCmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
-	listNoteCmd.Flags().StringP("search", "s", "", "Search term.")
-	listNoteCmd.Flags().StringP("notebook", "b", "", "Restrict search to notebook.")
+	addNoteSearchFlags(listNoteCmd)
+	listNoteCmd.Flags().Bool("trash", false, "Search the notes in the trash.")
 }
 
-func findNotes(cmd *cobra.Command, args []string) {
+// addNoteSearchFlags adds the flags used by findNotes to the command.
+func addNoteSearchFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("count", "c", 20, "How many notes to show in the result.")
+	cmd.Flags().IntP("page", "p", 0, "Show the page of the result, starting at 1.")
+	cmd.Flags().Int("offset", 0, "Show the result starting at the offset.")
+	cmd.Flags().BoolP("all", "a", false, "Show all the notes in the result.")
+	cmd.Flags().StringP("search", "s", "", "Search term.")
+	cmd.Flags().StringP("notebook", "b", "", "Restrict search to notebook.")
+	cmd.Flags().StringSliceP("tag", "t", nil, "Restrict search to notes with the tag.")
+	cmd.Flags().String("created-after", "", "Restrict search to notes created on or after the date.")
+	cmd.Flags().String("created-before", "", "Restrict search to notes created before the date.")
+	cmd.Flags().String("updated-after", "", "Restrict search to notes updated on or after the date.")
+	cmd.Flags().String("updated-before", "", "Restrict search to notes updated before the date.")
+	cmd.Flags().String("sort", "updated", "Sort by title, created, updated or relevance.")
+	cmd.Flags().Bool("asc", false, "Sort in ascending order.")
+	cmd.Flags().StringP("format", "f", "", "Write each note using the Go template.")
+}
+
+// searchDateFormat is the date format used by the search flags.
+const searchDateFormat = "2006-01-02"
+
+// parseDateFlag parses the date flag and returns the start of the date in
+// milliseconds. Zero is returned if the flag isn't set.
+func parseDateFlag(cmd *cobra.Command, flag string) (int64, error) {
+	s, err := cmd.Flags().GetString(flag)
+	if err != nil || s == "" {
+		return 0, err
+	}
+	t, err := time.ParseInLocation(searchDateFormat, s, time.Local)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
+
+// findNotes lists the notes matching the search flags. If trash is true,
+// the notes in the trash are searched instead of the active notes.
+func findNotes(cmd *cobra.Command, trash bool) {
+	f := getListingFormatter(cmd)
+	// The table is written by the note listing writer.
+	table := output == clinote.TableOutput && !cmd.Flags().Changed("format")
 	client := defaultClient()
 	defer client.Close()
 
 	// Create filter
-	filter := &clinote.NoteFilter{}
-	filter.Order = clinote.NoteFilterOrderUpdated
+	filter := &clinote.NoteFilter{Inactive: trash}
 	c, err := cmd.Flags().GetInt("count")
 	if err != nil {
 		fmt.Println("Error when parsing count value, using default:", err)
 		c = 20
 	}
+	offset, err := cmd.Flags().GetInt("offset")
+	if err != nil {
+		fmt.Println("Error when parsing offset:", err)
+		return
+	}
+	page, err := cmd.Flags().GetInt("page")
+	if err != nil {
+		fmt.Println("Error when parsing page:", err)
+		return
+	}
+	if page > 0 {
+		if cmd.Flags().Changed("offset") {
+			fmt.Println("Error when parsing page: can't be combined with offset")
+			return
+		}
+		offset = (page - 1) * c
+	}
+	if c < 1 || offset < 0 {
+		fmt.Println("Error when parsing count and offset: count has to be positive and offset can't be negative")
+		return
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		fmt.Println("Error when parsing all flag:", err)
+		return
+	}
 	searchBook, err := cmd.Flags().GetString("notebook")
 	if err != nil {
 		fmt.Println("Error when parsing notebook:", err)
@@ -78,8 +173,43 @@ func findNotes(cmd *cobra.Command, args []string) {
 	if search != "" {
 		filter.Words = search
 	}
+	sortName, err := cmd.Flags().GetString("sort")
+	if err != nil {
+		fmt.Println("Error when parsing sort order:", err)
+		return
+	}
+	if filter.Order, err = clinote.ParseSortOrder(sortName); err != nil {
+		fmt.Println("Error when parsing sort order:", err)
+		return
+	}
+	if filter.Ascending, err = cmd.Flags().GetBool("asc"); err != nil {
+		fmt.Println("Error when parsing asc flag:", err)
+		return
+	}
+	dates := []struct {
+		flag string
+		ts   *int64
+	}{
+		{"created-after", &filter.CreatedAfter},
+		{"created-before", &filter.CreatedBefore},
+		{"updated-after", &filter.UpdatedAfter},
+		{"updated-before", &filter.UpdatedBefore},
+	}
+	for _, d := range dates {
+		if *d.ts, err = parseDateFlag(cmd, d.flag); err != nil {
+			fmt.Println("Error when parsing "+d.flag+":", err)
+			return
+		}
+	}
+	// The server uses the time zone for relative dates in the search term.
+	filter.TimeZone = os.Getenv("TZ")
+	tags, err := cmd.Flags().GetStringSlice("tag")
+	if err != nil {
+		fmt.Println("Error when parsing tags:", err)
+		return
+	}
 
-	ns, err := client.GetNoteStore()
+	ns, err := getNoteStore(client)
 	if err != nil {
 		return
 	}
@@ -91,21 +221,70 @@ func findNotes(cmd *cobra.Command, args []string) {
 		}
 		filter.NotebookGUID = book.GUID
 	}
+	for _, name := range tags {
+		tag, err := clinote.FindTag(ns, name)
+		if err != nil {
+			fmt.Println("Error when trying to filter by tag "+name+":", err)
+			os.Exit(1)
+		}
+		filter.TagGUIDs = append(filter.TagGUIDs, tag.GUID)
+	}
 
-	list, err := clinote.FindNotes(ns, filter, 0, c)
+	nbs, err := clinote.GetNotebooks(client.Config.Store(), ns, false)
+	if err != nil {
+		fmt.Println("Failed to get all notebooks:", err)
+		return
+	}
+	w := clinote.NewNoteListingWriter(os.Stdout, nbs)
+
+	if all {
+		// The table, template and JSON lines output are written as the pages are
+		// received. The other formats are written when all the notes have been received.
+		stream := table || cmd.Flags().Changed("format") || output == clinote.JSONLinesOutput
+		// The notes are saved even if a later page fails, so the
+		// listed notes can be referenced by the index.
+		var notes []*clinote.Note
+		err = clinote.FindAllNotes(ns, filter, offset, c, func(list *clinote.NoteList) error {
+			notes = append(notes, list.Notes...)
+			if table {
+				w.WritePage(list)
+			} else if stream {
+				return f.Write(os.Stdout, clinote.NewNoteListing(list.Notes, list.StartIndex, nbs))
+			}
+			return nil
+		})
+		if !stream || (table && len(notes) == 0) {
+			if writeErr := f.Write(os.Stdout, clinote.NewNoteListing(notes, offset, nbs)); writeErr != nil {
+				fmt.Println("Error when writing the notes:", writeErr)
+			}
+		}
+		if saveErr := clinote.SaveSearch(client.Config.Store(), offset, notes); saveErr != nil {
+			log.Fatal(saveErr)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	list, err := clinote.FindNotes(ns, filter, offset, c)
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = client.Config.Store().SaveSearch(list)
+	err = clinote.SaveSearch(client.Config.Store(), offset, list.Notes)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	nbs, err := clinote.GetNotebooks(client.Config.Store(), ns, false)
-	if err != nil {
-		fmt.Println("Failed to get all notebooks:", err)
+	if !table {
+		if err = f.Write(os.Stdout, clinote.NewNoteListing(list.Notes, offset, nbs)); err != nil {
+			fmt.Println("Error when writing the notes:", err)
+			os.Exit(1)
+		}
 		return
 	}
-
-	clinote.WriteNoteListing(os.Stdout, list, nbs)
+	w.WritePage(list)
+	if len(list.Notes) > 0 && len(list.Notes) < list.TotalNotes {
+		fmt.Printf("Showing notes %d-%d of %d.\n", offset+1, offset+len(list.Notes), list.TotalNotes)
+	}
 }
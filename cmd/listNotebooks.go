@@ -29,26 +29,43 @@ var listNotebooksCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List notebooks.",
 	Long: `
-List notebooks returns all active notebooks.`,
+List notebooks returns all active notebooks. The tree flag
+shows the stacks with their notebooks below them.
+
+The format flag writes each notebook using a Go template, for example:
+
+  --format '{{.Name}}\t{{.Stack}}'`,
 	Run: func(cmd *cobra.Command, args []string) {
 		sync, err := cmd.Flags().GetBool("sync")
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
-		listNotebooks(sync)
+		listNotebooks(cmd, sync)
 	},
 }
 
 func init() {
 	notebookCmd.AddCommand(listNotebooksCmd)
 	listNotebooksCmd.Flags().BoolP("sync", "s", false, "Force a resync of notebooks from the server.")
+	listNotebooksCmd.Flags().StringP("format", "f", "", "Write each notebook using the Go template.")
+	listNotebooksCmd.Flags().Bool("tree", false, "Show the notebooks grouped by stack.")
 }
 
-func listNotebooks(sync bool) {
+func listNotebooks(cmd *cobra.Command, sync bool) {
+	f := getListingFormatter(cmd)
+	tree, err := cmd.Flags().GetBool("tree")
+	if err != nil {
+		fmt.Println("Error when parsing tree flag:", err)
+		return
+	}
+	if tree && (output != clinote.TableOutput || cmd.Flags().Changed("format")) {
+		fmt.Println("Error, the tree flag can't be combined with the output or format flags.")
+		return
+	}
 	client := defaultClient()
 	defer client.Close()
-	ns, err := client.GetNoteStore()
+	ns, err := getNoteStore(client)
 	if err != nil {
 		return
 	}
@@ -57,5 +74,13 @@ func listNotebooks(sync bool) {
 		fmt.Println("Error when getting notebooks:", err)
 		os.Exit(1)
 	}
-	clinote.WriteNotebookListing(os.Stdout, bs)
+	if tree {
+		err = clinote.WriteNotebookTree(os.Stdout, bs)
+	} else {
+		err = f.Write(os.Stdout, clinote.NewNotebookListing(bs))
+	}
+	if err != nil {
+		fmt.Println("Error when writing the notebooks:", err)
+		os.Exit(1)
+	}
 }
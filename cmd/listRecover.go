@@ -0,0 +1,48 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var listRecoverCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recovery points.",
+	Long: `
+List shows all the notes that failed to save, the oldest first.
+The ID in the first column is used by the other recover commands.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := defaultClient()
+		defer client.Close()
+		rps, err := clinote.GetRecoveryPoints(client.Config.Store())
+		if err != nil {
+			fmt.Println("Error when getting the recovery points:", err)
+			os.Exit(1)
+		}
+		clinote.WriteRecoveryListing(os.Stdout, rps)
+	},
+}
+
+func init() {
+	recoverCmd.AddCommand(listRecoverCmd)
+}
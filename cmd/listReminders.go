@@ -0,0 +1,84 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var listRemindersCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notes with reminders.",
+	Long: `
+List returns the notes with reminders that haven't been completed,
+sorted by when they are due. The due flag limits the listing to the
+reminders due today or the reminders that are overdue.
+
+The notes can be referenced by their index in the other commands.
+The format flag writes each note using a Go template, for example:
+
+  --format '{{.Title}}\t{{date .Attributes.ReminderTime}}'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := getListingFormatter(cmd)
+		name, err := cmd.Flags().GetString("due")
+		if err != nil {
+			fmt.Println("Error when parsing the due filter:", err)
+			return
+		}
+		due, err := clinote.ParseReminderDue(name)
+		if err != nil {
+			fmt.Println("Error when parsing the due filter:", err)
+			os.Exit(1)
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			return
+		}
+		notes, err := clinote.GetReminders(ns, due, time.Now())
+		if err != nil {
+			fmt.Println("Error when getting the reminders:", err)
+			os.Exit(1)
+		}
+		if err = clinote.SaveSearch(client.Config.Store(), 0, notes); err != nil {
+			fmt.Println("Error when saving the listing:", err)
+			os.Exit(1)
+		}
+		nbs, err := clinote.GetNotebooks(client.Config.Store(), ns, false)
+		if err != nil {
+			fmt.Println("Failed to get all notebooks:", err)
+			return
+		}
+		if err = f.Write(os.Stdout, clinote.NewReminderListing(notes, nbs)); err != nil {
+			fmt.Println("Error when writing the reminders:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	reminderCmd.AddCommand(listRemindersCmd)
+	listRemindersCmd.Flags().String("due", "", "Only list the reminders due \"today\" or the \"overdue\" reminders.")
+	listRemindersCmd.Flags().StringP("format", "f", "", "Write each note using the Go template.")
+}
@@ -0,0 +1,60 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var listStacksCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stacks.",
+	Long: `
+List returns the stacks and the notebooks in each stack.
+
+The format flag writes each stack using a Go template, for example:
+
+  --format '{{.Name}}\t{{len .Notebooks}}'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := getListingFormatter(cmd)
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			return
+		}
+		stacks, err := clinote.GetStacks(client.Config.Store(), ns)
+		if err != nil {
+			fmt.Println("Error when getting stacks:", err)
+			os.Exit(1)
+		}
+		if err = f.Write(os.Stdout, clinote.NewStackListing(stacks)); err != nil {
+			fmt.Println("Error when writing the stacks:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	stackCmd.AddCommand(listStacksCmd)
+	listStacksCmd.Flags().StringP("format", "f", "", "Write each stack using the Go template.")
+}
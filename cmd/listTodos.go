@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var listTodosCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the checkboxes in notes.",
+	Long: `
+List returns the checkboxes in the notes, the most recently updated
+notes first. The index is the checkbox's position in the note and is
+used by the todo check command.
+
+The format flag writes each checkbox using a Go template, for example:
+
+  --format '{{.Note.Title}}\t{{.Index}}\t{{.Text}}'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := getListingFormatter(cmd)
+		notebook, err := cmd.Flags().GetString("notebook")
+		if err != nil {
+			fmt.Println("Error when parsing notebook:", err)
+			return
+		}
+		open, err := cmd.Flags().GetBool("open")
+		if err != nil {
+			fmt.Println("Error when parsing open flag:", err)
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			return
+		}
+		items, err := clinote.GetTodos(client.Config.Store(), ns, notebook, open)
+		if err != nil {
+			fmt.Println("Error when getting the checkboxes:", err)
+			os.Exit(1)
+		}
+		if err = f.Write(os.Stdout, clinote.NewTodoListing(items)); err != nil {
+			fmt.Println("Error when writing the checkboxes:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	todoCmd.AddCommand(listTodosCmd)
+	listTodosCmd.Flags().StringP("notebook", "b", "", "Only list the checkboxes in the notebook.")
+	listTodosCmd.Flags().Bool("open", false, "Only list the checkboxes that aren't checked.")
+	listTodosCmd.Flags().StringP("format", "f", "", "Write each checkbox using the Go template.")
+}
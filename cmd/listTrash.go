@@ -0,0 +1,40 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var listTrashCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the notes in the trash.",
+	Long: `
+List returns the notes in the trash, the most recently
+modified first. The flags are the same as for the note
+list command. The index in the first column can be used
+by the restore and expunge commands.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		findNotes(cmd, true)
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(listTrashCmd)
+	addNoteSearchFlags(listTrashCmd)
+}
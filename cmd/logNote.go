@@ -0,0 +1,63 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var logNoteCmd = &cobra.Command{
+	Use:   "log \"note title\"",
+	Short: "List the local snapshots of a note.",
+	Long: `
+Log lists the snapshots of the note saved by clinote, the most
+recent first. A snapshot is saved each time the note is edited
+with clinote and is kept locally, independent of the server.
+The number in the first column is used by the revert command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		f := getFormatter()
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		snapshots, err := clinote.GetNoteSnapshots(client.Config.Store(), ns, args[0])
+		if err != nil {
+			fmt.Println("Error when getting the note snapshots:", err)
+			os.Exit(1)
+		}
+		if err = f.Write(os.Stdout, clinote.NewNoteSnapshotListing(snapshots)); err != nil {
+			fmt.Println("Error when writing the note snapshots:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(logNoteCmd)
+}
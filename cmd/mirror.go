@@ -0,0 +1,76 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror NOTEBOOK DIR",
+	Short: "Mirror a notebook to a folder.",
+	Long: `
+Mirror keeps a folder of Markdown files in sync with a notebook.
+Each note is saved as a file with a header that has the note's
+title, GUID, notebook, tags and update time.
+
+Files changed since the last mirror are saved to the notes and
+notes changed on the server are written to the files. New files
+are created as notes, and the notes of removed files are moved
+to the trash.
+
+If a note has been changed both in the folder and on the server,
+the file is kept and the server's version is written next to it
+with the ".conflict" extension. The file is saved to the note
+once the conflict file has been removed. A changed file whose note
+has been moved to another notebook or to the trash is kept and the
+note isn't changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			return
+		}
+		mirrorNotebook(args[0], args[1])
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(mirrorCmd)
+}
+
+func mirrorNotebook(notebook, dir string) {
+	client := defaultClient()
+	defer client.Close()
+	ns, err := getNoteStore(client)
+	if err != nil {
+		fmt.Println("Failed to get notestore:", err)
+		return
+	}
+	changes, err := clinote.MirrorNotebook(client.Config.Store(), ns, notebook, dir)
+	for _, c := range changes {
+		fmt.Printf("%-9s %s\n", c.Action.String()+":", c.File)
+	}
+	if err != nil {
+		fmt.Println("Error when mirroring the notebook:", err)
+		os.Exit(1)
+	}
+}
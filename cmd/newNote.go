@@ -19,6 +19,9 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
@@ -34,7 +37,26 @@ note.
 If no notebook is given, the default notebook will be used.
 
 The new note can be open in the $EDITOR by using the edit
-flag.`,
+flag.
+
+With the stdin flag, the content is read from stdin, for example:
+
+  echo "Backup done" | clinote note new --title "Backup log" --stdin
+
+The content can start with the same header as the note in the
+editor. The title, notebook and tags in the header are used
+instead of the flags.
+
+With the template flag, the note is created from a Markdown
+template in the templates folder in the config folder. The
+template can use {{.Date}}, {{.Time}}, {{.Week}}, {{.Year}} and
+the variables given with the var flag, for example:
+
+  clinote note new --template standup --var project=clinote
+
+uses {{.Var "project"}} in standup.md. The note is opened in the
+editor before it's saved, unless --edit=false is given. The title
+and notebook flags replace the values in the template's header.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		title, err := cmd.Flags().GetString("title")
 		if err != nil {
@@ -46,7 +68,25 @@ flag.`,
 			fmt.Println("Error when parsing edit flag:", err)
 			return
 		}
-		if title == "" && !edit {
+		stdin, err := cmd.Flags().GetBool("stdin")
+		if err != nil {
+			fmt.Println("Error when parsing stdin flag:", err)
+			return
+		}
+		tmpl, err := cmd.Flags().GetString("template")
+		if err != nil {
+			fmt.Println("Error when parsing template name:", err)
+			return
+		}
+		// Notes created from a template are edited unless the edit flag is set to false.
+		if tmpl != "" && !cmd.Flags().Changed("edit") {
+			edit = true
+		}
+		if stdin && (edit || tmpl != "") {
+			fmt.Println("The stdin flag can't be combined with the edit or template flag")
+			return
+		}
+		if title == "" && !edit && !stdin && tmpl == "" {
 			fmt.Println("Note title has to be given")
 			return
 		}
@@ -60,7 +100,21 @@ flag.`,
 			fmt.Println("Error when parsing raw parameter:", err)
 			return
 		}
-		createNote(title, notebook, edit, raw)
+		if raw && tmpl != "" {
+			fmt.Println("The raw flag can't be combined with the template flag")
+			return
+		}
+		a, err := cmd.Flags().GetStringArray("var")
+		if err != nil {
+			fmt.Println("Error when parsing template variables:", err)
+			return
+		}
+		vars, err := clinote.ParseTemplateVars(a)
+		if err != nil {
+			fmt.Println("Error when parsing template variables:", err)
+			return
+		}
+		createNote(title, notebook, tmpl, vars, edit, raw, stdin)
 	},
 }
 
@@ -68,19 +122,38 @@ func init() {
 	noteCmd.AddCommand(newNoteCmd)
 	newNoteCmd.Flags().StringP("title", "t", "", "Note title.")
 	newNoteCmd.Flags().StringP("notebook", "b", "", "The notebook to save note to, if not set the default notebook will be used.")
-	newNoteCmd.Flags().BoolP("edit", "e", false, "Open note in the editor.")
+	newNoteCmd.Flags().BoolP("edit", "e", false, "Open note in the editor. Notes created from a template are opened unless set to false.")
 	newNoteCmd.Flags().Bool("raw", false, "Edit the content in raw mode.")
+	newNoteCmd.Flags().Bool("stdin", false, "Read the content from stdin.")
+	newNoteCmd.Flags().String("template", "", "Create the note from the template.")
+	newNoteCmd.Flags().StringArray("var", nil, "Template variable as name=value, can be given multiple times.")
 }
 
-func createNote(title, notebook string, edit, raw bool) {
+func createNote(title, notebook, tmpl string, vars map[string]string, edit, raw, stdin bool) {
 	c := newClient(clinote.DefaultClientOptions)
 	defer c.Store.Close()
 
 	note := new(clinote.Note)
-	if title == "" {
-		note.Title = "Untitled note"
-	} else {
+	if tmpl != "" {
+		var err error
+		note, err = clinote.NewNoteFromTemplate(c, tmpl, clinote.NewTemplateData(time.Now(), vars))
+		if err == clinote.ErrNoTemplateFound {
+			names, _ := clinote.GetTemplates(c.Config)
+			fmt.Printf("Error when creating the note from the template: %s in %s\n", err, clinote.TemplateFolder(c.Config))
+			if len(names) > 0 {
+				fmt.Println("Available templates:", strings.Join(names, ", "))
+			}
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Println("Error when creating the note from the template:", err)
+			os.Exit(1)
+		}
+	}
+	if title != "" {
 		note.Title = title
+	} else if note.Title == "" && !stdin {
+		note.Title = "Untitled note"
 	}
 	if notebook != "" {
 		nb, err := clinote.FindNotebook(c.Store, c.NoteStore, notebook)
@@ -94,11 +167,40 @@ func createNote(title, notebook string, edit, raw bool) {
 	if raw {
 		opts |= clinote.RawNote
 	}
+	if stdin {
+		err := clinote.CreateNoteFromReader(c, note, os.Stdin, opts)
+		if err == clinote.ErrChangeQueued {
+			fmt.Println(err)
+			return
+		}
+		if err != nil {
+			fmt.Println("Error when creating the note:", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if edit {
-		if err := clinote.CreateAndEditNewNote(c, note, opts); err != nil {
+		err := clinote.CreateAndEditNewNote(c, note, opts)
+		if err == clinote.ErrChangeQueued {
+			fmt.Println(err)
+			return
+		}
+		if err != nil {
 			fmt.Println("Error when editing the note:", err)
 		}
 		return
 	}
+	if tmpl != "" {
+		err := clinote.CreateNote(c, note, opts)
+		if err == clinote.ErrChangeQueued {
+			fmt.Println(err)
+			return
+		}
+		if err != nil {
+			fmt.Println("Error when creating the note:", err)
+			os.Exit(1)
+		}
+		return
+	}
 	clinote.SaveNewNote(c.NoteStore, note, raw)
 }
@@ -0,0 +1,76 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var newTagCmd = &cobra.Command{
+	Use:   "new \"tag name\"",
+	Short: "Create a new tag.",
+	Long: `
+New creates a new tag. The tag can be nested under another
+tag by using the parent flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		createTag(cmd, args)
+	},
+}
+
+func init() {
+	tagCmd.AddCommand(newTagCmd)
+	newTagCmd.Flags().StringP("parent", "p", "", "Nest the tag under the parent tag.")
+}
+
+func createTag(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		fmt.Println("No tag name given")
+		os.Exit(1)
+	}
+	tag := &clinote.Tag{Name: args[0]}
+
+	parent, err := cmd.Flags().GetString("parent")
+	if err != nil {
+		fmt.Println("Error when parsing parent tag:", err)
+		os.Exit(1)
+	}
+
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	if parent != "" {
+		p, err := clinote.FindTag(ns, parent)
+		if err != nil {
+			fmt.Println("Error when searching for parent tag:", err)
+			os.Exit(1)
+		}
+		tag.ParentGUID = p.GUID
+	}
+	err = clinote.CreateTag(ns, tag)
+	if err != nil {
+		fmt.Println("Error when creating the tag:", err)
+		os.Exit(1)
+	}
+}
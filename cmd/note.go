@@ -28,7 +28,9 @@ import (
 var noteCmd = &cobra.Command{
 	Use:   "note \"note title\"",
 	Short: "View, edit and create a note.",
-	Long:  `Displays the content of a note.`,
+	Long: `
+Displays the content of a note. With the output flag, the note's
+metadata and content can be written as json, jsonl, csv, tsv or yaml.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 1 {
 			cmd.Usage()
@@ -54,9 +56,10 @@ func getNote(cmd *cobra.Command, args []string) {
 		fmt.Println("Error when paring raw flag:", err)
 		return
 	}
+	f := getFormatter()
 	client := defaultClient()
 	defer client.Close()
-	ns, err := client.GetNoteStore()
+	ns, err := getNoteStore(client)
 	if err != nil {
 		return
 	}
@@ -65,5 +68,12 @@ func getNote(cmd *cobra.Command, args []string) {
 		fmt.Println("Error when getting the note:", err.Error())
 		os.Exit(1)
 	}
-	clinote.WriteNote(os.Stdout, n, opts)
+	if output == clinote.TableOutput {
+		clinote.WriteNote(os.Stdout, n, opts)
+		return
+	}
+	if err = f.Write(os.Stdout, clinote.NewNoteDetails(n, opts)); err != nil {
+		fmt.Println("Error when writing the note:", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,38 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var prependNoteCmd = &cobra.Command{
+	Use:   "prepend \"note title\"|INDEX --stdin",
+	Short: "Add content to the beginning of a note.",
+	Long: `
+Prepend adds the content read from stdin to the beginning of the
+note. The content is handled the same way as by the append command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addToNote(cmd, args, true)
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(prependNoteCmd)
+	addToNoteFlags(prependNoteCmd)
+}
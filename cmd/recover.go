@@ -0,0 +1,56 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "View, apply and drop notes that failed to save.",
+	Long: `
+When a note fails to save, a recovery point is created. The recovery
+points are listed with recover list and can be saved again with recover
+apply or removed with recover drop. A recovery point can also be edited
+with note edit --recover.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(recoverCmd)
+}
+
+// parseRecoveryIndex parses the recovery point ID given as the argument.
+func parseRecoveryIndex(args []string) (int, bool) {
+	if len(args) != 1 {
+		fmt.Println("Error, a recovery point ID has to be given.")
+		return 0, false
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Println("Error when parsing the recovery point ID:", err)
+		return 0, false
+	}
+	return index, true
+}
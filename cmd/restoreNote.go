@@ -0,0 +1,68 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var restoreNoteCmd = &cobra.Command{
+	Use:   "restore \"note title\" --version VERSION",
+	Short: "Restore a prior version of a note.",
+	Long: `
+Restore replaces the title and content of the note with a prior
+version. The version number is listed by the history command.
+Attachments removed from the note since the version was saved
+are added back.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.Usage()
+			return
+		}
+		version, err := cmd.Flags().GetInt32("version")
+		if err != nil {
+			fmt.Println("Error when parsing version:", err)
+			return
+		}
+		if version <= 0 {
+			fmt.Println("A version has to be given with the version flag.")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		err = clinote.RestoreNoteVersion(client.Config.Store(), ns, args[0], version)
+		if err != nil {
+			fmt.Println("Error when restoring the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(restoreNoteCmd)
+	restoreNoteCmd.Flags().Int32P("version", "v", 0, "The version to restore.")
+}
@@ -0,0 +1,67 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var revertNoteCmd = &cobra.Command{
+	Use:   "revert \"note title\" N",
+	Short: "Revert a note to a local snapshot.",
+	Long: `
+Revert replaces the title, notebook and content of the note with
+the snapshot N listed by the log command. The current note is
+saved as a snapshot first so the revert can be undone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.Usage()
+			return
+		}
+		index, err := strconv.Atoi(args[1])
+		if err != nil || index < 1 {
+			fmt.Println("The snapshot has to be given as a number listed by the log command.")
+			return
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		err = clinote.RevertNote(client.Config.Store(), ns, args[0], index)
+		if err == clinote.ErrChangeQueued {
+			fmt.Println(err)
+			return
+		}
+		if err != nil {
+			fmt.Println("Error when reverting the note:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	noteCmd.AddCommand(revertNoteCmd)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/TcM1911/clinote"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +29,12 @@ const version string = "0.7.0-SNAPSHOT"
 
 var cfgFile string
 
+// offline is set if the local note store should be used instead of the server.
+var offline bool
+
+// output is the output format used by the listing commands.
+var output string
+
 var RootCmd = &cobra.Command{
 	Use:   "clinote",
 	Short: "CLInote is a cli client for Evernote.",
@@ -54,4 +61,6 @@ func Execute() {
 
 func init() {
 	RootCmd.Flags().Bool("version", false, "Show the version")
+	RootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Use the local copy of the notes created by sync.")
+	RootCmd.PersistentFlags().StringVar(&output, "output", clinote.TableOutput, "Output format: table, json, jsonl, csv, tsv or yaml.")
 }
@@ -0,0 +1,74 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var setReminderCmd = &cobra.Command{
+	Use:   "set \"note title\"|INDEX",
+	Short: "Set a reminder on a note.",
+	Long: `
+Set adds a reminder to the note that is due at the time given
+by the at flag, for example:
+
+  clinote reminder set "Shopping list" --at "2026-11-01 09:00"
+
+The time is in the local time zone. If only the date is given,
+the reminder is due at the start of the day. A reminder that is
+already set is moved to the new time and a completed reminder is
+reopened.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		at, err := cmd.Flags().GetString("at")
+		if err != nil {
+			fmt.Println("Error when parsing the reminder time:", err)
+			return
+		}
+		if len(args) != 1 || at == "" {
+			cmd.Usage()
+			return
+		}
+		due, err := clinote.ParseReminderTime(at, time.Local)
+		if err != nil {
+			fmt.Println("Error when parsing the reminder time:", err)
+			os.Exit(1)
+		}
+		client := defaultClient()
+		defer client.Close()
+		ns, err := getNoteStore(client)
+		if err != nil {
+			fmt.Println("Failed to get notestore:", err)
+			return
+		}
+		if err = clinote.SetReminder(client.Config.Store(), ns, args[0], due); err != nil {
+			fmt.Println("Error when setting the reminder:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	reminderCmd.AddCommand(setReminderCmd)
+	setReminderCmd.Flags().String("at", "", "When the reminder is due, \"YYYY-MM-DD HH:MM\" or \"YYYY-MM-DD\".")
+}
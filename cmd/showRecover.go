@@ -0,0 +1,60 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var showRecoverCmd = &cobra.Command{
+	Use:   "show ID",
+	Short: "Show a recovery point.",
+	Long:  `Displays the content of the note saved in the recovery point.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		index, ok := parseRecoveryIndex(args)
+		if !ok {
+			return
+		}
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			fmt.Println("Error when parsing raw flag:", err)
+			return
+		}
+		opts := clinote.DefaultNoteOption
+		if raw {
+			opts |= clinote.RawNote
+		}
+		client := defaultClient()
+		defer client.Close()
+		rp, err := clinote.GetRecoveryPoint(client.Config.Store(), index)
+		if err != nil {
+			fmt.Println("Error when getting the recovery point:", err)
+			os.Exit(1)
+		}
+		clinote.WriteNote(os.Stdout, rp.Note, opts)
+	},
+}
+
+func init() {
+	recoverCmd.AddCommand(showRecoverCmd)
+	showRecoverCmd.Flags().Bool("raw", false, "Display raw content instead of markdown encoded.")
+}
@@ -0,0 +1,97 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TcM1911/clinote"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync the local copy of the notes.",
+	Long: `
+Sync pushes the changes made while offline to the server and downloads
+the changes to the user's notes, notebooks and tags since the last sync
+to the local note store. The first sync downloads
+everything. If a sync is interrupted, the next sync continues from
+where it stopped.
+
+A queued change conflicts if the note has been changed on the server
+after the change was made. Conflicting changes are kept in the queue
+unless the force flag is used to overwrite the server's version or the
+discard flag is used to drop the local changes.
+
+The local copy is used by note, note list and notebook list when the
+offline flag is set or when the server can't be reached.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Println("Error when parsing force flag:", err)
+			return
+		}
+		discard, err := cmd.Flags().GetBool("discard")
+		if err != nil {
+			fmt.Println("Error when parsing discard flag:", err)
+			return
+		}
+		policy := clinote.KeepConflicts
+		if force {
+			policy = clinote.OverwriteConflicts
+		} else if discard {
+			policy = clinote.DiscardConflicts
+		}
+		syncNotes(policy)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().BoolP("force", "f", false, "Overwrite the server's version of conflicting notes.")
+	syncCmd.Flags().Bool("discard", false, "Discard local changes that conflict with the server.")
+}
+
+func syncNotes(policy clinote.ConflictPolicy) {
+	client := defaultClient()
+	defer client.Close()
+	ns, err := client.GetNoteStore()
+	if err != nil {
+		return
+	}
+	db := client.Config.Store()
+	result, err := clinote.ReplayChanges(db, ns, policy)
+	if err != nil {
+		fmt.Println("Error when pushing the queued changes:", err)
+		os.Exit(1)
+	}
+	if result.Pushed > 0 {
+		fmt.Printf("Pushed %d changes.\n", result.Pushed)
+	}
+	for _, c := range result.Conflicts {
+		fmt.Printf("Conflict: %s of \"%s\" conflicts with changes on the server.\n", c.Type, c.Note.Title)
+	}
+	n, err := clinote.Sync(db, ns)
+	fmt.Printf("Synced %d notes.\n", n)
+	if err != nil {
+		fmt.Println("Error when syncing:", err)
+		os.Exit(1)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/storage"
@@ -107,6 +108,8 @@ var setConfigOpts = []struct {
 	desc string
 }{
 	{"credential", "An index value.", "Set the active credential for the user."},
+	{"journal-title", "A title template.", "Set the title of the journal notes, for example \"Journal {{.Date}}\"."},
+	{"journal-notebook", "A notebook name.", "Set the notebook of the journal notes."},
 }
 
 func setConfig(store clinote.UserCredentialStore, db clinote.Storager, args []string) {
@@ -117,6 +120,10 @@ func setConfig(store clinote.UserCredentialStore, db clinote.Storager, args []st
 	switch args[0] {
 	case "credential":
 		setCredential(store, db, args[1])
+	case "journal-title":
+		setJournal(db, args[1], "")
+	case "journal-notebook":
+		setJournal(db, "", args[1])
 	default:
 		printConfigOptions()
 	}
@@ -151,6 +158,32 @@ func setCredential(store clinote.UserCredentialStore, db clinote.Storager, strIn
 	}
 }
 
+// setJournal sets the title template or the notebook of the journal notes.
+// Empty values are left unchanged.
+func setJournal(db clinote.Storager, title, notebook string) {
+	if title != "" {
+		if _, err := clinote.JournalTitle(title, time.Now()); err != nil {
+			fmt.Println("Error when parsing the journal title:", err)
+			return
+		}
+	}
+	settings, err := db.GetSettings()
+	if err != nil {
+		fmt.Println("Error when getting the settings:", err)
+		return
+	}
+	if title != "" {
+		settings.JournalTitle = title
+	}
+	if notebook != "" {
+		settings.JournalNotebook = notebook
+	}
+	err = db.StoreSettings(settings)
+	if err != nil {
+		fmt.Println("Error when saving the settings:", err)
+	}
+}
+
 func printConfigOptions() {
 	n := len(setConfigOpts)
 	vals, args, descs := make([]string, n, n), make([]string, n, n), make([]string, n, n)
@@ -168,16 +201,15 @@ func listCredentials(store clinote.UserCredentialStore, cmd *cobra.Command) {
 		fmt.Printf("Error when parsing arguments: %s\n", err.Error())
 		return
 	}
+	f := getFormatter()
 	list, err := clinote.GetAllCredentials(store)
 	if err != nil {
 		fmt.Println("Failed to get all credentials:", err)
 		return
 	}
-	if includeToken {
-		clinote.WriteCredentialListingWithSecret(os.Stdout, list)
-		return
+	if err = f.Write(os.Stdout, clinote.NewCredentialListing(list, includeToken)); err != nil {
+		fmt.Println("Error when writing the credentials:", err)
 	}
-	clinote.WriteCredentialListing(os.Stdout, list)
 }
 
 func rmCredential(store clinote.UserCredentialStore, args []string) {
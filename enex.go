@@ -0,0 +1,272 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// ENEXFormat is the format name for Evernote's export format.
+	ENEXFormat = "enex"
+	// enexHeader is the header of an ENEX document.
+	enexHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">` + "\n"
+	enexApplication = "CLInote"
+	enexVersion     = "CLInote"
+	enexTimeFormat  = "20060102T150405Z"
+	enexEncoding    = "base64"
+	// exportPageSize is the number of notes requested from the notestore per page.
+	exportPageSize = 50
+)
+
+var (
+	// ErrUnsupportedFormat is returned if the export format is not supported.
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	// ErrUnsupportedEncoding is returned if the resource data encoding is not supported.
+	ErrUnsupportedEncoding = errors.New("unsupported resource encoding")
+)
+
+// enexNote is the ENEX representation of a note.
+type enexNote struct {
+	XMLName   xml.Name        `xml:"note"`
+	Title     string          `xml:"title"`
+	Content   enexContent     `xml:"content"`
+	Created   string          `xml:"created,omitempty"`
+	Updated   string          `xml:"updated,omitempty"`
+	Tags      []string        `xml:"tag"`
+	Resources []*enexResource `xml:"resource"`
+}
+
+// enexContent holds the ENML document of the note.
+type enexContent struct {
+	Data string `xml:",cdata"`
+}
+
+// enexResource is the ENEX representation of a resource.
+type enexResource struct {
+	Data       enexData                `xml:"data"`
+	Mime       string                  `xml:"mime"`
+	Attributes *enexResourceAttributes `xml:"resource-attributes,omitempty"`
+}
+
+// enexData is the encoded resource data.
+type enexData struct {
+	Encoding string `xml:"encoding,attr"`
+	Data     string `xml:",chardata"`
+}
+
+// enexResourceAttributes are the resource attributes.
+type enexResourceAttributes struct {
+	Filename string `xml:"file-name,omitempty"`
+}
+
+// ExportNotebook writes all the notes in the notebook to the writer in the given format.
+// If the notebook name is an empty string, all the user's notes are exported.
+func ExportNotebook(w io.Writer, db Storager, ns NotestoreClient, notebook, format string) error {
+	if format != ENEXFormat {
+		return ErrUnsupportedFormat
+	}
+	filter := new(NoteFilter)
+	if notebook != "" {
+		nb, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return err
+		}
+		filter.NotebookGUID = nb.GUID
+	}
+	return ExportNotes(w, ns, filter)
+}
+
+// ExportNotes writes all the notes matching the filter to the writer as an ENEX document.
+// The notes are requested from the notestore in pages and written as they are downloaded.
+func ExportNotes(w io.Writer, ns NotestoreClient, filter *NoteFilter) error {
+	tags, err := ns.ListTags()
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(tags))
+	for _, t := range tags {
+		names[t.GUID] = t.Name
+	}
+	if _, err = io.WriteString(w, enexHeader); err != nil {
+		return err
+	}
+	now := time.Now().UTC().Format(enexTimeFormat)
+	_, err = io.WriteString(w, `<en-export export-date="`+now+`" application="`+enexApplication+`" version="`+enexVersion+`">`+"\n")
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	err = FindAllNotes(ns, filter, 0, exportPageSize, func(list *NoteList) error {
+		for _, n := range list.Notes {
+			en, err := toENEXNote(ns, n, names)
+			if err != nil {
+				return err
+			}
+			if err = enc.Encode(en); err != nil {
+				return err
+			}
+		}
+		return enc.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n</en-export>\n")
+	return err
+}
+
+// toENEXNote downloads the note's content and resources and returns
+// the ENEX representation of the note.
+func toENEXNote(ns NotestoreClient, n *Note, names map[string]string) (*enexNote, error) {
+	content, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return nil, err
+	}
+	en := &enexNote{
+		Title:   n.Title,
+		Content: enexContent{Data: content},
+		Created: formatENEXTime(n.Created),
+		Updated: formatENEXTime(n.Updated),
+	}
+	for _, t := range n.Tags {
+		name := t.Name
+		if name == "" {
+			name = names[t.GUID]
+		}
+		en.Tags = append(en.Tags, name)
+	}
+	for _, r := range n.Resources {
+		data, err := GetResourceData(ns, r)
+		if err != nil {
+			return nil, err
+		}
+		res := &enexResource{
+			Data: enexData{Encoding: enexEncoding, Data: base64.StdEncoding.EncodeToString(data)},
+			Mime: r.Mime,
+		}
+		if r.Filename != "" {
+			res.Attributes = &enexResourceAttributes{Filename: r.Filename}
+		}
+		en.Resources = append(en.Resources, res)
+	}
+	return en, nil
+}
+
+// formatENEXTime converts the timestamp in milliseconds to the ENEX time format.
+func formatENEXTime(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts/1000, (ts%1000)*int64(time.Millisecond)).UTC().Format(enexTimeFormat)
+}
+
+// parseENEXTime converts the ENEX time to a timestamp in milliseconds.
+func parseENEXTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(enexTimeFormat, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano() / int64(time.Millisecond), nil
+}
+
+// ImportNotes creates a new note for each note in the ENEX document. If the notebook
+// name is an empty string, the notes are added to the default notebook. The number
+// of imported notes is returned.
+func ImportNotes(r io.Reader, db Storager, ns NotestoreClient, notebook string) (int, error) {
+	var nb *Notebook
+	if notebook != "" {
+		b, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return 0, err
+		}
+		nb = b
+	}
+	d := xml.NewDecoder(r)
+	imported := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return imported, nil
+		}
+		if err != nil {
+			return imported, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+		en := new(enexNote)
+		if err = d.DecodeElement(en, &start); err != nil {
+			return imported, err
+		}
+		n, err := fromENEXNote(en)
+		if err != nil {
+			return imported, err
+		}
+		n.Notebook = nb
+		if err = ns.CreateNote(n); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+}
+
+// fromENEXNote converts the ENEX note to a note. The content is sanitized
+// so it only includes valid ENML.
+func fromENEXNote(en *enexNote) (*Note, error) {
+	body, err := sanitizeENML(en.Content.Data)
+	if err != nil {
+		return nil, err
+	}
+	n := &Note{Title: en.Title, Body: body}
+	if n.Created, err = parseENEXTime(en.Created); err != nil {
+		return nil, err
+	}
+	if n.Updated, err = parseENEXTime(en.Updated); err != nil {
+		return nil, err
+	}
+	for _, t := range en.Tags {
+		n.Tags = append(n.Tags, &Tag{Name: t})
+	}
+	for _, r := range en.Resources {
+		if r.Data.Encoding != enexEncoding {
+			return nil, ErrUnsupportedEncoding
+		}
+		// The encoded data is usually split over multiple lines.
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(r.Data.Data), ""))
+		if err != nil {
+			return nil, err
+		}
+		filename := ""
+		if r.Attributes != nil {
+			filename = r.Attributes.Filename
+		}
+		n.Resources = append(n.Resources, NewResource(filename, r.Mime, data))
+	}
+	return n, nil
+}
@@ -0,0 +1,184 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportNotes(t *testing.T) {
+	assert := assert.New(t)
+	data := []byte("attachment")
+	content := XMLHeader + "<en-note><p>Body</p></en-note>"
+	t.Run("write notes", func(t *testing.T) {
+		note := &Note{
+			Title:     "Note title",
+			GUID:      "GUID",
+			Created:   int64(1500000000000),
+			Updated:   int64(1500000001000),
+			Tags:      []*Tag{&Tag{GUID: "Tag GUID"}},
+			Resources: []*Resource{&Resource{GUID: "Resource", Filename: "file.txt", Mime: "text/plain", Hash: resourceHash(data)}},
+		}
+		ns := &mockNS{
+			listTags:        func() ([]*Tag, error) { return []*Tag{&Tag{GUID: "Tag GUID", Name: "Tag"}}, nil },
+			findNotes:       func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil },
+			getNoteContent:  func(string) (string, error) { return content, nil },
+			getResourceData: func(string) ([]byte, error) { return data, nil },
+		}
+		buf := new(bytes.Buffer)
+		err := ExportNotes(buf, ns, new(NoteFilter))
+		assert.NoError(err, "Should not return an error")
+		out := buf.String()
+		assert.Contains(out, enexHeader, "Should include the header")
+		assert.Contains(out, "<title>Note title</title>", "Should include the title")
+		assert.Contains(out, "<content><![CDATA["+content+"]]></content>", "Should include the content")
+		assert.Contains(out, "<created>20170714T024000Z</created>", "Should include the created time")
+		assert.Contains(out, "<updated>20170714T024001Z</updated>", "Should include the updated time")
+		assert.Contains(out, "<tag>Tag</tag>", "Should include the tag")
+		assert.Contains(out, `<data encoding="base64">`+base64.StdEncoding.EncodeToString(data)+"</data>", "Should include the resource")
+		assert.Contains(out, "<file-name>file.txt</file-name>", "Should include the filename")
+		assert.Contains(out, "</en-export>", "Should close the document")
+	})
+	t.Run("request all pages", func(t *testing.T) {
+		var offsets []int
+		ns := &mockNS{
+			listTags: func() ([]*Tag, error) { return nil, nil },
+			findNotes: func(f *NoteFilter, offset, count int) (*NoteList, error) {
+				offsets = append(offsets, offset)
+				notes := make([]*Note, count)
+				for i := range notes {
+					notes[i] = &Note{Title: fmt.Sprintf("Note %d", offset+i)}
+				}
+				return &NoteList{Notes: notes, StartIndex: offset, TotalNotes: 2 * count}, nil
+			},
+			getNoteContent: func(string) (string, error) { return content, nil },
+		}
+		buf := new(bytes.Buffer)
+		err := ExportNotes(buf, ns, new(NoteFilter))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]int{0, exportPageSize}, offsets, "Wrong pages requested")
+		assert.Equal(2*exportPageSize, bytes.Count(buf.Bytes(), []byte("<note>")), "Wrong number of notes")
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		err := ExportNotebook(new(bytes.Buffer), new(mockStore), new(mockNS), "", "pdf")
+		assert.Equal(ErrUnsupportedFormat, err, "Wrong error returned")
+	})
+}
+
+func TestImportNotes(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	notebook := &Notebook{Name: "Notebook", GUID: "Notebook GUID"}
+	t.Run("create notes", func(t *testing.T) {
+		var created []*Note
+		ns := &mockNS{
+			getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{notebook}, nil },
+			createNote:      func(n *Note) error { created = append(created, n); return nil },
+		}
+		count, err := ImportNotes(strings.NewReader(testENEX), store, ns, "Notebook")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(2, count, "Wrong number of notes imported")
+		assert.Len(created, 2, "Wrong number of notes created")
+
+		n := created[0]
+		assert.Equal("First note", n.Title, "Wrong title")
+		assert.Equal(notebook, n.Notebook, "Wrong notebook")
+		assert.Equal(int64(1500000000000), n.Created, "Wrong created time")
+		assert.Equal(int64(1500000001000), n.Updated, "Wrong updated time")
+		assert.Equal([]*Tag{&Tag{Name: "Tag1"}, &Tag{Name: "Tag2"}}, n.Tags, "Wrong tags")
+		assert.Equal(XMLHeader+"<en-note><p>Body</p></en-note>", n.Body, "Content should be sanitized")
+		assert.Len(n.Resources, 1, "Resource should be added")
+		assert.Equal([]byte("attachment"), n.Resources[0].Data, "Wrong resource data")
+		assert.Equal("file.txt", n.Resources[0].Filename, "Wrong filename")
+		assert.Equal(resourceHash([]byte("attachment")), n.Resources[0].Hash, "Wrong hash")
+
+		assert.Equal("Second note", created[1].Title, "Wrong title")
+		assert.Nil(created[1].Tags, "Should not have tags")
+	})
+	t.Run("return error from CreateNote", func(t *testing.T) {
+		expectedErr := errors.New("expected")
+		ns := &mockNS{createNote: func(n *Note) error { return expectedErr }}
+		count, err := ImportNotes(strings.NewReader(testENEX), store, ns, "")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+		assert.Equal(0, count, "No notes should be imported")
+	})
+	t.Run("round trip", func(t *testing.T) {
+		data := []byte("attachment")
+		content := XMLHeader + "<en-note><p>Body</p></en-note>"
+		exported := &Note{
+			Title:     "Note",
+			Created:   int64(1500000000000),
+			Tags:      []*Tag{&Tag{Name: "Tag"}},
+			Resources: []*Resource{&Resource{Filename: "file.txt", Mime: "text/plain", Hash: resourceHash(data)}},
+		}
+		buf := new(bytes.Buffer)
+		err := ExportNotes(buf, &mockNS{
+			listTags:        func() ([]*Tag, error) { return nil, nil },
+			findNotes:       func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{exported}}, nil },
+			getNoteContent:  func(string) (string, error) { return content, nil },
+			getResourceData: func(string) ([]byte, error) { return data, nil },
+		}, new(NoteFilter))
+		assert.NoError(err, "Should not return an error")
+		var imported *Note
+		_, err = ImportNotes(buf, store, &mockNS{createNote: func(n *Note) error { imported = n; return nil }}, "")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(exported.Title, imported.Title, "Wrong title")
+		assert.Equal(exported.Created, imported.Created, "Wrong created time")
+		assert.Equal(exported.Tags, imported.Tags, "Wrong tags")
+		assert.Equal(content, imported.Body, "Wrong content")
+		assert.Equal(data, imported.Resources[0].Data, "Wrong resource data")
+	})
+}
+
+const testENEX = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export3.dtd">
+<en-export export-date="20170714T024000Z" application="Evernote" version="Evernote Mac 6.11">
+<note>
+<title>First note</title>
+<content><![CDATA[<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">
+<en-note><p id="p1">Body</p><script>alert(1)</script></en-note>]]></content>
+<created>20170714T024000Z</created>
+<updated>20170714T024001Z</updated>
+<tag>Tag1</tag>
+<tag>Tag2</tag>
+<resource>
+<data encoding="base64">
+YXR0YWNo
+bWVudA==
+</data>
+<mime>text/plain</mime>
+<resource-attributes><file-name>file.txt</file-name></resource-attributes>
+</resource>
+</note>
+<note>
+<title>Second note</title>
+<content><![CDATA[<en-note><div>Second</div></en-note>]]></content>
+</note>
+</en-export>
+`
@@ -0,0 +1,157 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+const enNoteElement = "en-note"
+
+// prohibitedElements are the elements that are not allowed in ENML.
+// The elements are removed together with their content.
+var prohibitedElements = map[string]bool{
+	"applet": true, "base": true, "basefont": true, "bgsound": true, "blink": true,
+	"body": true, "button": true, "dir": true, "embed": true, "fieldset": true,
+	"form": true, "frame": true, "frameset": true, "head": true, "html": true,
+	"iframe": true, "ilayer": true, "input": true, "isindex": true, "label": true,
+	"layer": true, "legend": true, "link": true, "marquee": true, "menu": true,
+	"meta": true, "noframes": true, "noscript": true, "object": true, "optgroup": true,
+	"option": true, "param": true, "plaintext": true, "script": true, "select": true,
+	"style": true, "textarea": true, "xml": true,
+}
+
+// ENMLError is returned if the note content is not valid ENML.
+type ENMLError struct {
+	// Line is the line in the note content where the error was found.
+	// It's 0 if the position is not known.
+	Line int
+	// Column is the column in the line where the error was found.
+	Column int
+	// Reason describes what is wrong with the content.
+	Reason string
+}
+
+func (e *ENMLError) Error() string {
+	if e.Line == 0 {
+		return "invalid note content: " + e.Reason
+	}
+	return fmt.Sprintf("invalid note content at line %d, column %d: %s", e.Line, e.Column, e.Reason)
+}
+
+// validateENML checks that the content of the en-note element is well-formed and
+// only uses the elements and attributes allowed in ENML. An *ENMLError with the
+// position in the content is returned if the content is invalid.
+func validateENML(content string) error {
+	return toENMLError(markdown.Validate(content), true)
+}
+
+// toENMLError converts a validation error to an *ENMLError. If withPosition is false,
+// the position is not included since it doesn't refer to the content the user edited.
+func toENMLError(err error, withPosition bool) error {
+	verr, ok := err.(*markdown.ValidationError)
+	if !ok {
+		return err
+	}
+	if !withPosition {
+		return &ENMLError{Reason: verr.Reason}
+	}
+	return &ENMLError{Line: verr.Line, Column: verr.Column, Reason: verr.Reason}
+}
+
+// sanitizeENML removes prohibited elements and attributes from the note content and
+// returns a complete ENML document. The content can either be a full ENML document or
+// just the content of the en-note element.
+func sanitizeENML(content string) (string, error) {
+	d := xml.NewDecoder(strings.NewReader(content))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.AutoClose = xml.HTMLAutoClose
+	buf := new(bytes.Buffer)
+	enc := xml.NewEncoder(buf)
+	// skip is the depth inside a prohibited element.
+	skip := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || prohibitedElements[t.Name.Local] {
+				skip++
+				continue
+			}
+			// The en-note element is added back when the document is assembled.
+			// Namespaced elements, for example from office documents, and other
+			// elements not allowed in ENML are unwrapped.
+			if unwrapElement(t.Name) {
+				continue
+			}
+			t.Attr = sanitizeAttributes(t.Attr)
+			err = enc.EncodeToken(t)
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if unwrapElement(t.Name) {
+				continue
+			}
+			err = enc.EncodeToken(t)
+		case xml.CharData:
+			if skip == 0 {
+				err = enc.EncodeToken(t.Copy())
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	// Whitespace between the prolog and the en-note element is not part of the content.
+	return XMLHeader + "<" + enNoteElement + ">" + strings.TrimSpace(buf.String()) + "</" + enNoteElement + ">", nil
+}
+
+// unwrapElement returns true if the element should be removed but its content kept.
+func unwrapElement(name xml.Name) bool {
+	return name.Local == enNoteElement || name.Space != "" || !markdown.IsAllowedElement(name.Local)
+}
+
+func sanitizeAttributes(attrs []xml.Attr) []xml.Attr {
+	a := make([]xml.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		name := strings.ToLower(attr.Name.Local)
+		if attr.Name.Space != "" || name == "xmlns" || !markdown.IsAllowedAttribute(name) {
+			continue
+		}
+		a = append(a, attr)
+	}
+	return a
+}
@@ -0,0 +1,73 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeENML(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"full document", XMLHeader + "<en-note><p>Text</p></en-note>", "<p>Text</p>"},
+		{"only content", "<p>Text</p>", "<p>Text</p>"},
+		{"remove prohibited element", "<p>Text</p><script>alert(1)</script><form><p>Inside</p></form>", "<p>Text</p>"},
+		{"remove prohibited attributes", `<p id="a" class="b" onclick="c()" style="color:red">Text</p>`, `<p style="color:red">Text</p>`},
+		{"unwrap namespaced elements", "<p>Text<o:p>More</o:p></p>", "<p>TextMore</p>"},
+		{"unwrap unknown elements", "<article><p>Text</p></article>", "<p>Text</p>"},
+		{"keep media", `<en-media type="image/png" hash="abc"/>`, `<en-media type="image/png" hash="abc"></en-media>`},
+		{"escape text", "<p>a &amp; b &lt; c</p>", "<p>a &amp; b &lt; c</p>"},
+		{"remove comments", "<p>Text<!-- comment --></p>", "<p>Text</p>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := sanitizeENML(test.content)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(XMLHeader+"<en-note>"+test.expected+"</en-note>", actual, "Wrong content")
+		})
+	}
+}
+
+func TestValidateENML(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(validateENML("<p>Text</p>\n<div><en-todo checked=\"false\"/>Todo</div>"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := validateENML("<p>Text</p>\n<p><span class=\"a\">Text</span></p>")
+		if assert.IsType(&ENMLError{}, err, "Should return a validation error") {
+			verr := err.(*ENMLError)
+			assert.Equal(2, verr.Line, "Wrong line")
+			assert.Equal(4, verr.Column, "Wrong column")
+			assert.Equal("invalid note content at line 2, column 4: attribute class is not allowed on span", verr.Error())
+		}
+	})
+
+	t.Run("error without position", func(t *testing.T) {
+		err := &ENMLError{Reason: "element form is not allowed"}
+		assert.Equal("invalid note content: element form is not allowed", err.Error())
+	})
+}
@@ -29,12 +29,20 @@ type Notestore interface {
 	CreateNotebook(apiKey string, notebook *types.Notebook) (r *types.Notebook, err error)
 	// UpdateNotebook sends an updated notebook to the server.
 	UpdateNotebook(apiKey string, notebook *types.Notebook) (r int32, err error)
+	// ExpungeNotebook permanently removes the notebook from the user's account.
+	ExpungeNotebook(authenticationToken string, guid types.GUID) (r int32, err error)
 	// GetNotebook returns a notebook from the notestore.
 	GetNotebook(authenticationToken string, guid types.GUID) (r *types.Notebook, err error)
 	// CreateNote creates a new note on the server.
 	CreateNote(apiKey string, note *types.Note) (r *types.Note, err error)
 	// DeleteNote moves a note to the trash can.
 	DeleteNote(apiKey string, guid types.GUID) (int32, error)
+	// ExpungeNote permanently removes the note from the user's account.
+	ExpungeNote(authenticationToken string, guid types.GUID) (r int32, err error)
+	// ExpungeInactiveNotes permanently removes all the notes in the trash.
+	ExpungeInactiveNotes(authenticationToken string) (r int32, err error)
+	// GetNote returns the current state of the note with the provided GUID.
+	GetNote(authenticationToken string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error)
 	// UpdateNote submits a set of changes to a note to the service.  The provided data
 	// must include the note's guid field for identification. The note's title must also be set.
 	UpdateNote(authenticationToken string, note *types.Note) (r *types.Note, err error)
@@ -43,4 +51,24 @@ type Notestore interface {
 	// GetNoteContent returns XHTML contents of the note with the provided GUID.
 	// If the Note is found in a public notebook, the authenticationToken will be ignored (so it could be an empty string).
 	GetNoteContent(authenticationToken string, guid types.GUID) (r string, err error)
+	// ListTags returns a list of all the user's tags.
+	ListTags(authenticationToken string) (r []*types.Tag, err error)
+	// CreateTag creates a new tag for the user.
+	CreateTag(authenticationToken string, tag *types.Tag) (r *types.Tag, err error)
+	// UpdateTag submits tag changes to the service.
+	UpdateTag(authenticationToken string, tag *types.Tag) (r int32, err error)
+	// ExpungeTag permanently deletes the tag with the provided GUID and removes it from all notes.
+	ExpungeTag(authenticationToken string, guid types.GUID) (r int32, err error)
+	// GetResource returns the current state of the resource with the provided GUID.
+	GetResource(authenticationToken string, guid types.GUID, withData bool, withRecognition bool, withAttributes bool, withAlternateData bool) (r *types.Resource, err error)
+	// GetResourceData returns binary data of the resource with the provided GUID.
+	GetResourceData(authenticationToken string, guid types.GUID) (r []byte, err error)
+	// GetSyncState returns the current state of the account.
+	GetSyncState(authenticationToken string) (r *notestore.SyncState, err error)
+	// GetFilteredSyncChunk returns the changes after the update sequence number matching the filter.
+	GetFilteredSyncChunk(authenticationToken string, afterUSN int32, maxEntries int32, filter *notestore.SyncChunkFilter) (r *notestore.SyncChunk, err error)
+	// ListNoteVersions returns the prior versions of the note saved by the service.
+	ListNoteVersions(authenticationToken string, noteGuid types.GUID) (r []*notestore.NoteVersionId, err error)
+	// GetNoteVersion returns a prior version of the note identified by the update sequence number.
+	GetNoteVersion(authenticationToken string, noteGuid types.GUID, updateSequenceNum int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error)
 }
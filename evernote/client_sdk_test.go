@@ -97,11 +97,27 @@ type mockStore struct {
 	settings *clinote.Settings
 }
 
-func (m *mockStore) SaveNoteRecoveryPoint(*clinote.Note) error {
+func (m *mockStore) SaveNoteRecoveryPoint(*clinote.Note, bool) error {
 	panic("not implemented")
 }
 
-func (m *mockStore) GetNoteRecoveryPoint() (*clinote.Note, error) {
+func (m *mockStore) GetNoteRecoveryPoints() ([]*clinote.RecoveryPoint, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveNoteRecoveryPoint(string) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveNoteSnapshot(*clinote.NoteSnapshot) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetNoteSnapshots(string) ([]*clinote.NoteSnapshot, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveNoteSnapshot(string) error {
 	panic("not implemented")
 }
 
@@ -133,3 +149,67 @@ func (m *mockStore) StoreSettings(s *clinote.Settings) error {
 	m.settings.APIKey = s.APIKey
 	return nil
 }
+
+func (m *mockStore) GetLocalSyncState() (*clinote.LocalSyncState, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveLocalSyncState(*clinote.LocalSyncState) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveLocalNote(*clinote.Note) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetLocalNote(guid string) (*clinote.Note, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetLocalNotes() ([]*clinote.Note, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveLocalNote(guid string) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveLocalNotebook(*clinote.Notebook) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetLocalNotebooks() ([]*clinote.Notebook, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveLocalNotebook(guid string) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) SaveLocalTag(*clinote.Tag) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetLocalTags() ([]*clinote.Tag, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveLocalTag(guid string) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) ClearLocalStore() error {
+	panic("not implemented")
+}
+
+func (m *mockStore) QueueChange(*clinote.Change) error {
+	panic("not implemented")
+}
+
+func (m *mockStore) GetQueuedChanges() ([]*clinote.Change, error) {
+	panic("not implemented")
+}
+
+func (m *mockStore) RemoveQueuedChange(id uint64) error {
+	panic("not implemented")
+}
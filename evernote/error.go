@@ -1,6 +1,12 @@
 package evernote
 
-import "errors"
+import (
+	"errors"
+	"io"
+	"net"
+
+	"github.com/TcM1911/clinote"
+)
 
 var (
 	// ErrNotLoggedIn is returned when the user is trying to perform
@@ -14,8 +20,56 @@ var (
 	ErrTempTokenMismatch = errors.New("temporary token mismatch")
 	// ErrAccessRevoked is returned if the user decline access.
 	ErrAccessRevoked = errors.New("access revoked")
-	// ErrNoGUIDSet is returned if the note does not have a GUID.
+	// ErrNoGUIDSet is returned if the note or notebook does not have a GUID.
 	ErrNoGUIDSet = errors.New("no GUID set.")
 	// ErrNoTitleSet is returned if the not does not have a title.
 	ErrNoTitleSet = errors.New("no title set")
 )
+
+// The thrift transport exception types for timeouts and closed connections.
+const (
+	transportTimedOut  = 3
+	transportEndOfFile = 4
+)
+
+// transportError matches the errors returned by the thrift transport.
+type transportError interface {
+	TypeId() int
+	Err() error
+}
+
+// connectionError returns clinote.ErrOffline if the error is caused by
+// the server not being reachable. Otherwise the error is returned as is.
+func connectionError(err error) error {
+	if isConnectionError(err) {
+		return clinote.ErrOffline
+	}
+	return err
+}
+
+// isConnectionError returns true if the request failed because of a network
+// error, a timeout or a closed connection. Errors returned by the server,
+// including HTTP error responses, are not connection errors.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if te, ok := err.(transportError); ok {
+		if te.TypeId() == transportTimedOut || te.TypeId() == transportEndOfFile {
+			return true
+		}
+		if err = te.Err(); err == nil {
+			return false
+		}
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
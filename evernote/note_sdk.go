@@ -46,9 +46,44 @@ func convert(note *types.Note) *clinote.Note {
 	n.Notebook.GUID = notebookGUID
 	n.Created = int64(note.GetCreated())
 	n.Updated = int64(note.GetUpdated())
+	n.Tags = noteTags(note)
+	n.Resources = convertResources(note.Resources)
+	n.USN = note.GetUpdateSequenceNum()
+	n.Deleted = note.IsSetActive() && !note.GetActive()
+	if note.Attributes != nil {
+		n.Attributes = convertAttributes(note.Attributes)
+	}
 	return n
 }
 
+func convertAttributes(a *types.NoteAttributes) *clinote.NoteAttributes {
+	return &clinote.NoteAttributes{
+		ReminderOrder:    a.GetReminderOrder(),
+		ReminderTime:     int64(a.GetReminderTime()),
+		ReminderDoneTime: int64(a.GetReminderDoneTime()),
+	}
+}
+
+// transferNoteAttributes copies the attributes to the SDK attributes. Zero
+// values unset the attribute.
+func transferNoteAttributes(attrs *clinote.NoteAttributes, a *types.NoteAttributes) {
+	a.ReminderOrder = nil
+	if attrs.ReminderOrder != 0 {
+		order := attrs.ReminderOrder
+		a.ReminderOrder = &order
+	}
+	a.ReminderTime = timestampOrNil(attrs.ReminderTime)
+	a.ReminderDoneTime = timestampOrNil(attrs.ReminderDoneTime)
+}
+
+func timestampOrNil(ts int64) *types.Timestamp {
+	if ts == 0 {
+		return nil
+	}
+	t := types.Timestamp(ts)
+	return &t
+}
+
 func convertNotes(notes []*types.Note) []*clinote.Note {
 	a := make([]*clinote.Note, len(notes))
 	for i, n := range notes {
@@ -18,48 +18,71 @@
 package evernote
 
 import (
-	"sync"
-
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/evernote-sdk-golang/types"
 )
 
-var notebookMu sync.Mutex
-var cachedNotebooks map[types.GUID]*types.Notebook
-
 func convertNotebooks(bs []*types.Notebook) []*clinote.Notebook {
 	a := make([]*clinote.Notebook, len(bs), len(bs))
 	for i, b := range bs {
-		a[i] = &clinote.Notebook{GUID: string(b.GetGUID()), Name: b.GetName(), Stack: b.GetStack()}
+		a[i] = convertNotebook(b)
 	}
 	return a
 }
 
+func convertNotebook(b *types.Notebook) *clinote.Notebook {
+	nb := &clinote.Notebook{
+		GUID:      string(b.GetGUID()),
+		Name:      b.GetName(),
+		Stack:     b.GetStack(),
+		Default:   b.GetDefaultNotebook(),
+		Published: b.GetPublished(),
+	}
+	if b.IsSetPublishing() {
+		nb.PublicURI = b.GetPublishing().GetURI()
+	}
+	if r := b.GetRestrictions(); r != nil {
+		nb.Restrictions = &clinote.NotebookRestrictions{
+			NoUpdateNotebook:     r.GetNoUpdateNotebook(),
+			NoExpungeNotebook:    r.GetNoExpungeNotebook(),
+			NoSetDefaultNotebook: r.GetNoSetDefaultNotebook(),
+			NoSetNotebookStack:   r.GetNoSetNotebookStack(),
+			NoPublishToPublic:    r.GetNoPublishToPublic(),
+		}
+	}
+	return nb
+}
+
 func transferNotebookData(src *clinote.Notebook, dst *types.Notebook) {
 	dst.Name = &(src.Name)
 	if src.Stack != "" {
 		dst.Stack = &(src.Stack)
+	} else {
+		// Remove the notebook from its stack.
+		dst.Stack = nil
 	}
 }
 
-func cacheNotebook(nb *types.Notebook) {
-	notebookMu.Lock()
-	defer notebookMu.Unlock()
-	if cachedNotebooks == nil {
-		cachedNotebooks = make(map[types.GUID]*types.Notebook)
-	}
-	cachedNotebooks[*nb.GUID] = nb
-}
-
-func getCachedNotebook(guid types.GUID) (*types.Notebook, error) {
-	notebookMu.Lock()
-	defer notebookMu.Unlock()
-	if cachedNotebooks == nil {
-		return nil, clinote.ErrNoNotebookCached
+// transferNotebookSettings copies the default and publishing settings to the
+// notebook. A notebook can't be unset as the default notebook, another notebook
+// has to be set as the default instead.
+func transferNotebookSettings(src *clinote.Notebook, dst *types.Notebook) {
+	if src.Default {
+		dst.DefaultNotebook = &src.Default
+	} else {
+		dst.DefaultNotebook = nil
 	}
-	nb, ok := cachedNotebooks[guid]
-	if !ok {
-		return nil, clinote.ErrNoNotebookFound
+	dst.Published = &src.Published
+	if src.Published {
+		if dst.Publishing == nil {
+			dst.Publishing = types.NewPublishing()
+		}
+		if src.PublicURI != "" {
+			dst.Publishing.URI = &src.PublicURI
+		}
+		if dst.Publishing.Order == nil {
+			order := types.NoteSortOrder_UPDATED
+			dst.Publishing.Order = &order
+		}
 	}
-	return nb, nil
 }
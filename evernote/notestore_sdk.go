@@ -5,6 +5,7 @@ import (
 
 	"github.com/TcM1911/clinote"
 	"github.com/TcM1911/clinote/evernote/api"
+	edam "github.com/TcM1911/evernote-sdk-golang/errors"
 	"github.com/TcM1911/evernote-sdk-golang/notestore"
 	"github.com/TcM1911/evernote-sdk-golang/types"
 )
@@ -13,26 +14,41 @@ import (
 type Notestore struct {
 	evernoteNS api.Notestore
 	apiToken   string
+	// notebooks are the notebooks returned by GetNotebook. They are used
+	// by UpdateNotebook so the notebook isn't fetched again.
+	notebooks map[types.GUID]*types.Notebook
 }
 
 // GetAllNotebooks returns all the of users notebooks.
 func (s *Notestore) GetAllNotebooks() ([]*clinote.Notebook, error) {
 	bs, err := s.evernoteNS.ListNotebooks(s.apiToken)
 	if err != nil {
-		return nil, err
+		return nil, connectionError(err)
 	}
 	return convertNotebooks(bs), nil
 }
 
-// UpdateNotebook updates the notebook on the server.
+// UpdateNotebook updates the notebook on the server. The changes are applied
+// to the notebook returned by GetNotebook, or the notebook is fetched from the
+// server, so the fields not handled by clinote are kept.
 func (s *Notestore) UpdateNotebook(b *clinote.Notebook) error {
-	nb, err := getCachedNotebook(types.GUID(b.GUID))
-	if err != nil {
-		return err
+	if b.GUID == "" {
+		return ErrNoGUIDSet
+	}
+	guid := types.GUID(b.GUID)
+	nb, ok := s.notebooks[guid]
+	if ok {
+		delete(s.notebooks, guid)
+	} else {
+		var err error
+		if nb, err = s.evernoteNS.GetNotebook(s.apiToken, guid); err != nil {
+			return connectionError(err)
+		}
 	}
 	transferNotebookData(b, nb)
-	_, err = s.evernoteNS.UpdateNotebook(s.apiToken, nb)
-	return err
+	transferNotebookSettings(b, nb)
+	_, err := s.evernoteNS.UpdateNotebook(s.apiToken, nb)
+	return connectionError(err)
 }
 
 //CreateNotebook creates a new notebook for the user.
@@ -41,23 +57,42 @@ func (s *Notestore) CreateNotebook(b *clinote.Notebook, defaultNotebook bool) er
 	nb.DefaultNotebook = &defaultNotebook
 	transferNotebookData(b, nb)
 	_, err := s.evernoteNS.CreateNotebook(s.apiToken, nb)
-	return err
+	return connectionError(err)
+}
+
+// ExpungeNotebook permanently removes the notebook from the server.
+func (s *Notestore) ExpungeNotebook(guid string) error {
+	_, err := s.evernoteNS.ExpungeNotebook(s.apiToken, types.GUID(guid))
+	return connectionError(err)
 }
 
 // GetNotebook returns the notebook with the specific GUID.
 func (s *Notestore) GetNotebook(guid string) (*clinote.Notebook, error) {
 	nb, err := s.evernoteNS.GetNotebook(s.apiToken, types.GUID(guid))
 	if err != nil {
-		return nil, err
+		return nil, connectionError(err)
 	}
-	return convertNotebooks([]*types.Notebook{nb})[0], nil
+	if s.notebooks == nil {
+		s.notebooks = make(map[types.GUID]*types.Notebook)
+	}
+	s.notebooks[nb.GetGUID()] = nb
+	return convertNotebook(nb), nil
 }
 
-// CreateNote creates a new note and saves it to the server.
+// CreateNote creates a new note and saves it to the server. The note's GUID
+// is set to the GUID assigned by the server.
 func (s *Notestore) CreateNote(n *clinote.Note) error {
 	note := types.NewNote()
-	now := types.Timestamp(time.Now().Unix() * 1000)
-	note.Created = &now
+	// Keep the original timestamps if the note is imported.
+	created := types.Timestamp(n.Created)
+	if created == 0 {
+		created = types.Timestamp(time.Now().Unix() * 1000)
+	}
+	note.Created = &created
+	if n.Updated != 0 {
+		updated := types.Timestamp(n.Updated)
+		note.Updated = &updated
+	}
 	note.Title = &n.Title
 	if n.Body != "" {
 		note.Content = &n.Body
@@ -66,17 +101,43 @@ func (s *Notestore) CreateNote(n *clinote.Note) error {
 		guid := string(n.Notebook.GUID)
 		note.NotebookGuid = &guid
 	}
-	_, err := s.evernoteNS.CreateNote(s.apiToken, note)
-	return err
+	transferNoteTags(n, note)
+	transferNoteResources(n, note)
+	if n.Attributes != nil {
+		note.Attributes = types.NewNoteAttributes()
+		transferNoteAttributes(n.Attributes, note.Attributes)
+	}
+	saved, err := s.evernoteNS.CreateNote(s.apiToken, note)
+	if err != nil {
+		return connectionError(err)
+	}
+	// Keep the GUID assigned by the server so the note can be referenced.
+	if saved != nil && saved.GUID != nil {
+		n.GUID = string(*saved.GUID)
+	}
+	return nil
 }
 
 // DeleteNote removes a note from the user's notebook.
 func (s *Notestore) DeleteNote(guid string) error {
 	_, err := s.evernoteNS.DeleteNote(s.apiToken, types.GUID(guid))
-	return err
+	return connectionError(err)
+}
+
+// ExpungeNote permanently removes the note from the server.
+func (s *Notestore) ExpungeNote(guid string) error {
+	_, err := s.evernoteNS.ExpungeNote(s.apiToken, types.GUID(guid))
+	return connectionError(err)
+}
+
+// ExpungeInactiveNotes permanently removes all the notes in the trash.
+func (s *Notestore) ExpungeInactiveNotes() error {
+	_, err := s.evernoteNS.ExpungeInactiveNotes(s.apiToken)
+	return connectionError(err)
 }
 
-// UpdateNote update's the note.
+// UpdateNote update's the note. A note in the trash is restored if
+// it isn't marked as deleted.
 func (s *Notestore) UpdateNote(note *clinote.Note) error {
 	if note.GUID == "" {
 		return ErrNoGUIDSet
@@ -92,22 +153,162 @@ func (s *Notestore) UpdateNote(note *clinote.Note) error {
 		n.Content = &note.Body
 	}
 	n.NotebookGuid = &note.Notebook.GUID
+	active := !note.Deleted
+	n.Active = &active
+	transferNoteTags(note, n)
+	transferNoteResources(note, n)
+	if note.Attributes != nil {
+		attrs, err := s.noteAttributes(guid)
+		if err != nil {
+			return err
+		}
+		transferNoteAttributes(note.Attributes, attrs)
+		n.Attributes = attrs
+	}
 	_, err := s.evernoteNS.UpdateNote(s.apiToken, n)
-	return err
+	return connectionError(err)
+}
+
+// noteAttributes returns a copy of the note's attributes on the server so the
+// attributes not handled by clinote are kept when the note is updated.
+func (s *Notestore) noteAttributes(guid types.GUID) (*types.NoteAttributes, error) {
+	n, err := s.evernoteNS.GetNote(s.apiToken, guid, false, false, false, false)
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	attrs := types.NewNoteAttributes()
+	if n.Attributes != nil {
+		*attrs = *n.Attributes
+	}
+	return attrs, nil
+}
+
+// GetNote returns the note's metadata. If the note doesn't exist,
+// clinote.ErrNoNoteFound is returned.
+func (s *Notestore) GetNote(guid string) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNote(s.apiToken, types.GUID(guid), false, false, false, false)
+	if _, ok := err.(*edam.EDAMNotFoundException); ok {
+		return nil, clinote.ErrNoNoteFound
+	}
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	return convert(n), nil
 }
 
 // FindNotes searches for the notes based on the filter.
-func (s *Notestore) FindNotes(filter *clinote.NoteFilter, offset, count int) ([]*clinote.Note, error) {
+func (s *Notestore) FindNotes(filter *clinote.NoteFilter, offset, count int) (*clinote.NoteList, error) {
 	r, err := s.evernoteNS.FindNotes(s.apiToken, createFilter(filter), int32(offset), int32(count))
 	if err != nil {
-		return nil, err
+		return nil, connectionError(err)
 	}
-	return convertNotes(r.GetNotes()), nil
+	return &clinote.NoteList{
+		Notes:      convertNotes(r.GetNotes()),
+		StartIndex: int(r.GetStartIndex()),
+		TotalNotes: int(r.GetTotalNotes()),
+	}, nil
 }
 
 // GetNoteContent gets the note's content from the notestore.
 func (s *Notestore) GetNoteContent(guid string) (string, error) {
-	return s.evernoteNS.GetNoteContent(s.apiToken, types.GUID(guid))
+	content, err := s.evernoteNS.GetNoteContent(s.apiToken, types.GUID(guid))
+	return content, connectionError(err)
+}
+
+// ListTags returns all the user's tags.
+func (s *Notestore) ListTags() ([]*clinote.Tag, error) {
+	ts, err := s.evernoteNS.ListTags(s.apiToken)
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	return convertTags(ts), nil
+}
+
+// CreateTag creates a new tag on the server.
+func (s *Notestore) CreateTag(t *clinote.Tag) error {
+	tag := types.NewTag()
+	transferTagData(t, tag)
+	_, err := s.evernoteNS.CreateTag(s.apiToken, tag)
+	return connectionError(err)
+}
+
+// UpdateTag updates the tag on the server.
+func (s *Notestore) UpdateTag(t *clinote.Tag) error {
+	if t.GUID == "" {
+		return ErrNoGUIDSet
+	}
+	tag := types.NewTag()
+	transferTagData(t, tag)
+	_, err := s.evernoteNS.UpdateTag(s.apiToken, tag)
+	return connectionError(err)
+}
+
+// ExpungeTag permanently removes the tag from the server.
+func (s *Notestore) ExpungeTag(guid string) error {
+	_, err := s.evernoteNS.ExpungeTag(s.apiToken, types.GUID(guid))
+	return connectionError(err)
+}
+
+// GetResource returns the resource's metadata without the data.
+func (s *Notestore) GetResource(guid string) (*clinote.Resource, error) {
+	r, err := s.evernoteNS.GetResource(s.apiToken, types.GUID(guid), false, false, true, false)
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	return convertResource(r), nil
+}
+
+// GetResourceData returns the resource's data.
+func (s *Notestore) GetResourceData(guid string) ([]byte, error) {
+	data, err := s.evernoteNS.GetResourceData(s.apiToken, types.GUID(guid))
+	return data, connectionError(err)
+}
+
+// GetSyncState returns the sync state of the user's account.
+func (s *Notestore) GetSyncState() (*clinote.SyncState, error) {
+	state, err := s.evernoteNS.GetSyncState(s.apiToken)
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	return convertSyncState(state), nil
+}
+
+// GetSyncChunk returns the changes after the update sequence number.
+func (s *Notestore) GetSyncChunk(afterUSN int32, maxEntries int) (*clinote.SyncChunk, error) {
+	chunk, err := s.evernoteNS.GetFilteredSyncChunk(s.apiToken, afterUSN, int32(maxEntries), newSyncChunkFilter())
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	return convertSyncChunk(chunk), nil
+}
+
+// ListNoteVersions returns the prior versions of the note saved by the server.
+func (s *Notestore) ListNoteVersions(guid string) ([]*clinote.NoteVersion, error) {
+	vs, err := s.evernoteNS.ListNoteVersions(s.apiToken, types.GUID(guid))
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	versions := make([]*clinote.NoteVersion, len(vs))
+	for i, v := range vs {
+		versions[i] = &clinote.NoteVersion{
+			USN:     v.GetUpdateSequenceNum(),
+			Title:   v.GetTitle(),
+			Updated: int64(v.GetUpdated()),
+			Saved:   int64(v.GetSaved()),
+		}
+	}
+	return versions, nil
+}
+
+// GetNoteVersion returns the prior version of the note with the content and the resource data.
+func (s *Notestore) GetNoteVersion(guid string, usn int32) (*clinote.Note, error) {
+	n, err := s.evernoteNS.GetNoteVersion(s.apiToken, types.GUID(guid), usn, true, false, false)
+	if err != nil {
+		return nil, connectionError(err)
+	}
+	note := convert(n)
+	note.Body = n.GetContent()
+	return note, nil
 }
 
 func createFilter(filter *clinote.NoteFilter) *notestore.NoteFilter {
@@ -116,8 +317,27 @@ func createFilter(filter *clinote.NoteFilter) *notestore.NoteFilter {
 		guid := types.GUID(filter.NotebookGUID)
 		searchFilter.NotebookGuid = &guid
 	}
-	if filter.Words != "" {
-		searchFilter.Words = &(filter.Words)
+	if words := filter.SearchQuery(); words != "" {
+		searchFilter.Words = &words
+	}
+	if filter.Order != 0 {
+		order := filter.Order
+		searchFilter.Order = &order
+	}
+	if filter.Ascending {
+		ascending := true
+		searchFilter.Ascending = &ascending
+	}
+	if len(filter.TagGUIDs) > 0 {
+		searchFilter.TagGuids = filter.TagGUIDs
+	}
+	if filter.Inactive {
+		inactive := true
+		searchFilter.Inactive = &inactive
+	}
+	if filter.TimeZone != "" {
+		tz := filter.TimeZone
+		searchFilter.TimeZone = &tz
 	}
 	return searchFilter
 }
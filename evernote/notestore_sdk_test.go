@@ -1,10 +1,16 @@
 package evernote
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
+	"io"
+	"net"
+	"net/url"
 	"testing"
 
 	"github.com/TcM1911/clinote"
+	edam "github.com/TcM1911/evernote-sdk-golang/errors"
 	"github.com/TcM1911/evernote-sdk-golang/notestore"
 	"github.com/TcM1911/evernote-sdk-golang/types"
 	"github.com/stretchr/testify/assert"
@@ -38,46 +44,139 @@ func TestUpdateNotebookSDK(t *testing.T) {
 	assert := assert.New(t)
 	token := "token"
 	guid := "guid"
-	t.Run("Return ErrNoNotebookCached", func(t *testing.T) {
+	// serverNotebook returns the notebook as stored on the server.
+	serverNotebook := func() *types.Notebook {
+		savedGUID := types.GUID(guid)
+		title, stack, uri := "Old title", "Stack", "old-uri"
+		published := true
+		order := types.NoteSortOrder_TITLE
+		return &types.Notebook{
+			GUID:       &savedGUID,
+			Name:       &title,
+			Stack:      &stack,
+			Published:  &published,
+			Publishing: &types.Publishing{URI: &uri, Order: &order},
+		}
+	}
+	newAPI := func(saved **types.Notebook) *mockAPI {
+		return &mockAPI{
+			getNotebook: func(k string, g types.GUID) (*types.Notebook, error) {
+				if g != types.GUID(guid) {
+					return nil, errExpected
+				}
+				return serverNotebook(), nil
+			},
+			updateNotebook: func(k string, nb *types.Notebook) (int32, error) { *saved = nb; return int32(0), nil },
+		}
+	}
+	t.Run("Return ErrNoGUIDSet", func(t *testing.T) {
 		ns := &Notestore{apiToken: token, evernoteNS: nil}
 		err := ns.UpdateNotebook(&clinote.Notebook{})
-		assert.Equal(clinote.ErrNoNotebookCached, err, "No cached notebooks")
+		assert.Equal(ErrNoGUIDSet, err, "Wrong error returned")
 	})
-	t.Run("Return ErrNoNotebookFound", func(t *testing.T) {
-		cachedGUID := types.GUID("another guid")
-		cachedNB := &types.Notebook{GUID: &cachedGUID}
-		notCached := "not cached"
-		cacheNotebook(cachedNB)
-		ns := &Notestore{apiToken: token, evernoteNS: nil}
-		err := ns.UpdateNotebook(&clinote.Notebook{GUID: notCached})
-		assert.Equal(clinote.ErrNoNotebookFound, err, "Notebook not cached")
+	t.Run("Return error when fetching notebook", func(t *testing.T) {
+		var saved *types.Notebook
+		ns := &Notestore{apiToken: token, evernoteNS: newAPI(&saved)}
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: "unknown"})
+		assert.Equal(errExpected, err, "Wrong error returned")
+		assert.Nil(saved, "Notebook should not be updated")
 	})
 	t.Run("Return error from api", func(t *testing.T) {
-		oldTitle := "Old title"
-		newTitle := "New title"
-		savedGUID := types.GUID(guid)
-		cachedNotebook := &types.Notebook{GUID: &savedGUID, Name: &oldTitle}
-		cacheNotebook(cachedNotebook)
-		api := &mockAPI{updateNotebook: func(k string, nb *types.Notebook) (int32, error) { return int32(0), errExpected }}
+		api := &mockAPI{
+			getNotebook:    func(k string, g types.GUID) (*types.Notebook, error) { return serverNotebook(), nil },
+			updateNotebook: func(k string, nb *types.Notebook) (int32, error) { return int32(0), errExpected },
+		}
 		ns := &Notestore{apiToken: token, evernoteNS: api}
-		book := &clinote.Notebook{GUID: guid, Name: newTitle}
-		err := ns.UpdateNotebook(book)
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: guid, Name: "New title"})
 		assert.Error(err, "Should return error from api call")
 	})
-	t.Run("Update notebook", func(t *testing.T) {
-		oldTitle := "Old title"
-		newTitle := "New title"
-		savedGUID := types.GUID(guid)
-		cachedNotebook := &types.Notebook{GUID: &savedGUID, Name: &oldTitle}
-		cacheNotebook(cachedNotebook)
+	t.Run("Update notebook without a cache", func(t *testing.T) {
+		var saved *types.Notebook
+		ns := &Notestore{apiToken: token, evernoteNS: newAPI(&saved)}
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: guid, Name: "New title", Stack: "Stack", Published: true})
+		assert.NoError(err, "Should update without error")
+		assert.Equal("New title", saved.GetName(), "Should update notebook name")
+		assert.Equal("Stack", saved.GetStack(), "Should keep the stack")
+		assert.Equal("old-uri", saved.GetPublishing().GetURI(), "Should keep the public URI")
+		assert.Equal(types.NoteSortOrder_TITLE, saved.GetPublishing().GetOrder(), "Should keep the publishing order")
+		assert.False(saved.IsSetDefaultNotebook(), "Default notebook should not be set")
+	})
+	t.Run("Update fetched notebook", func(t *testing.T) {
 		var saved *types.Notebook
-		api := &mockAPI{updateNotebook: func(k string, nb *types.Notebook) (int32, error) { saved = nb; return int32(0), nil }}
+		api := newAPI(&saved)
+		fetched := 0
+		getNotebook := api.getNotebook
+		api.getNotebook = func(k string, g types.GUID) (*types.Notebook, error) {
+			fetched++
+			return getNotebook(k, g)
+		}
 		ns := &Notestore{apiToken: token, evernoteNS: api}
-		book := &clinote.Notebook{GUID: guid, Name: newTitle}
-		err := ns.UpdateNotebook(book)
+		b, err := ns.GetNotebook(guid)
+		assert.NoError(err, "Should get the notebook without error")
+		b.Name = "New title"
+		err = ns.UpdateNotebook(b)
+		assert.NoError(err, "Should update without error")
+		assert.Equal(1, fetched, "Notebook should only be fetched once")
+		assert.Equal("New title", saved.GetName(), "Should update notebook name")
+	})
+	t.Run("Remove notebook from stack", func(t *testing.T) {
+		var saved *types.Notebook
+		ns := &Notestore{apiToken: token, evernoteNS: newAPI(&saved)}
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: guid, Name: "Old title", Published: true})
 		assert.NoError(err, "Should update without error")
-		assert.Equal(newTitle, *saved.Name, "Should update notebook name")
+		assert.False(saved.IsSetStack(), "Stack should be removed")
 	})
+	t.Run("Set default and publishing", func(t *testing.T) {
+		var saved *types.Notebook
+		ns := &Notestore{apiToken: token, evernoteNS: newAPI(&saved)}
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: guid, Name: "Old title", Default: true, Published: true, PublicURI: "new-uri"})
+		assert.NoError(err, "Should update without error")
+		assert.True(saved.GetDefaultNotebook(), "Should be set as the default notebook")
+		assert.True(saved.GetPublished(), "Should be published")
+		assert.Equal("new-uri", saved.GetPublishing().GetURI(), "Should update the public URI")
+	})
+	t.Run("Unpublish", func(t *testing.T) {
+		var saved *types.Notebook
+		ns := &Notestore{apiToken: token, evernoteNS: newAPI(&saved)}
+		err := ns.UpdateNotebook(&clinote.Notebook{GUID: guid, Name: "Old title"})
+		assert.NoError(err, "Should update without error")
+		assert.True(saved.IsSetPublished(), "Published should be set")
+		assert.False(saved.GetPublished(), "Should not be published")
+	})
+}
+
+func TestConvertNotebookSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("guid")
+	name, stack, uri := "Name", "Stack", "uri"
+	yes := true
+	nb := convertNotebook(&types.Notebook{
+		GUID:            &guid,
+		Name:            &name,
+		Stack:           &stack,
+		DefaultNotebook: &yes,
+		Published:       &yes,
+		Publishing:      &types.Publishing{URI: &uri},
+		Restrictions:    &types.NotebookRestrictions{NoExpungeNotebook: &yes},
+	})
+	assert.Equal(&clinote.Notebook{
+		GUID:         "guid",
+		Name:         "Name",
+		Stack:        "Stack",
+		Default:      true,
+		Published:    true,
+		PublicURI:    "uri",
+		Restrictions: &clinote.NotebookRestrictions{NoExpungeNotebook: true},
+	}, nb, "Wrong notebook")
+}
+
+func TestExpungeNotebookSDK(t *testing.T) {
+	assert := assert.New(t)
+	var expunged types.GUID
+	api := &mockAPI{expungeNotebook: func(k string, g types.GUID) (int32, error) { expunged = g; return int32(0), nil }}
+	ns := &Notestore{apiToken: "token", evernoteNS: api}
+	assert.NoError(ns.ExpungeNotebook("GUID"), "Should not return an error")
+	assert.Equal(types.GUID("GUID"), expunged, "Wrong notebook expunged")
 }
 
 func TestCreateNotebookSDK(t *testing.T) {
@@ -116,6 +215,40 @@ func TestCreateNoteSDK(t *testing.T) {
 	assert.Equal(notebookGUID, *saved.NotebookGuid, "Notebook GUID doesn't match")
 }
 
+func TestCreateNoteTimestampsSDK(t *testing.T) {
+	assert := assert.New(t)
+	var saved *types.Note
+	ns := &Notestore{
+		apiToken:   "token",
+		evernoteNS: &mockAPI{createNote: func(k string, n *types.Note) (*types.Note, error) { saved = n; return n, nil }},
+	}
+	t.Run("keep original timestamps", func(t *testing.T) {
+		err := ns.CreateNote(&clinote.Note{Title: "Title", Created: int64(1000), Updated: int64(2000)})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(types.Timestamp(1000), saved.GetCreated(), "Wrong created time")
+		assert.Equal(types.Timestamp(2000), saved.GetUpdated(), "Wrong updated time")
+	})
+	t.Run("set created time for new notes", func(t *testing.T) {
+		err := ns.CreateNote(&clinote.Note{Title: "Title"})
+		assert.NoError(err, "Should not return an error")
+		assert.NotEqual(types.Timestamp(0), saved.GetCreated(), "Created time should be set")
+		assert.False(saved.IsSetUpdated(), "Updated time should not be set")
+	})
+}
+
+func TestCreateNoteGUIDSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid := types.GUID("new GUID")
+	ns := &Notestore{
+		apiToken:   "token",
+		evernoteNS: &mockAPI{createNote: func(k string, n *types.Note) (*types.Note, error) { n.GUID = &guid; return n, nil }},
+	}
+	note := &clinote.Note{Title: "Title"}
+	err := ns.CreateNote(note)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("new GUID", note.GUID, "GUID assigned by the server should be set")
+}
+
 func TestDeleteNoteSDK(t *testing.T) {
 	assert := assert.New(t)
 	token := "token"
@@ -129,6 +262,24 @@ func TestDeleteNoteSDK(t *testing.T) {
 	assert.NoError(err, "Should not return an error.")
 }
 
+func TestExpungeNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	var expunged types.GUID
+	var emptied bool
+	ns := &Notestore{
+		apiToken: "token",
+		evernoteNS: &mockAPI{
+			expungeNote:     func(a string, g types.GUID) (int32, error) { expunged = g; return int32(0), nil },
+			expungeInactive: func(a string) (int32, error) { emptied = true; return int32(0), nil },
+		},
+	}
+
+	assert.NoError(ns.ExpungeNote("GUID"), "Should not return an error")
+	assert.Equal(types.GUID("GUID"), expunged, "Wrong note expunged")
+	assert.NoError(ns.ExpungeInactiveNotes(), "Should not return an error")
+	assert.True(emptied, "Trash should be emptied")
+}
+
 func TestUpdateNoteSDK(t *testing.T) {
 	assert := assert.New(t)
 	token := "token"
@@ -160,6 +311,7 @@ func TestUpdateNoteSDK(t *testing.T) {
 		assert.Equal(expectedGUID, string(expectedNote.GetGUID()), "Wrong GUID")
 		assert.Equal(expectedTitle, expectedNote.GetTitle(), "Wrong Title")
 		assert.Equal("", expectedNote.GetContent(), "Content should be empty")
+		assert.True(expectedNote.GetActive(), "Note should be active")
 	})
 
 	t.Run("Include body if set", func(t *testing.T) {
@@ -178,7 +330,61 @@ func TestUpdateNoteSDK(t *testing.T) {
 		assert.Equal(expectedGUID, string(expectedNote.GetGUID()), "Wrong GUID")
 		assert.Equal(expectedTitle, expectedNote.GetTitle(), "Wrong Title")
 		assert.Equal(expectedContent, expectedNote.GetContent(), "Content should be empty")
+		assert.Nil(expectedNote.Attributes, "Attributes should not be sent if not set")
 	})
+
+	t.Run("Keep the server's attributes", func(t *testing.T) {
+		var expectedNote *types.Note
+		author, order, done := "Author", int64(1), types.Timestamp(2)
+		ns.evernoteNS = &mockAPI{
+			getNote: func(k string, g types.GUID, content, data, recognition, alternate bool) (*types.Note, error) {
+				assert.Equal(types.GUID("Note GUID"), g, "Wrong note requested")
+				return &types.Note{GUID: &g, Attributes: &types.NoteAttributes{Author: &author, ReminderOrder: &order, ReminderDoneTime: &done}}, nil
+			},
+			updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil },
+		}
+		err := ns.UpdateNote(&clinote.Note{
+			Title:      "Title",
+			GUID:       "Note GUID",
+			Notebook:   new(clinote.Notebook),
+			Attributes: &clinote.NoteAttributes{ReminderOrder: 1, ReminderTime: 3},
+		})
+		assert.NoError(err, "No error should be returned")
+		a := expectedNote.Attributes
+		assert.Equal(author, a.GetAuthor(), "Author should be kept")
+		assert.Equal(types.Timestamp(3), a.GetReminderTime(), "Wrong reminder time")
+		assert.False(a.IsSetReminderDoneTime(), "Reminder done time should be unset")
+	})
+
+	t.Run("Ignore the attributes from the last search", func(t *testing.T) {
+		var expectedNote *types.Note
+		guid, cached, author := types.GUID("Searched GUID"), "Cached author", "Server author"
+		convertNotes([]*types.Note{&types.Note{GUID: &guid, Attributes: &types.NoteAttributes{Author: &cached}}})
+		ns.evernoteNS = &mockAPI{
+			getNote: func(k string, g types.GUID, content, data, recognition, alternate bool) (*types.Note, error) {
+				assert.Equal(guid, g, "Wrong note requested")
+				return &types.Note{GUID: &g, Attributes: &types.NoteAttributes{Author: &author}}, nil
+			},
+			updateNote: func(api string, n *types.Note) (*types.Note, error) { expectedNote = n; return nil, nil },
+		}
+		err := ns.UpdateNote(&clinote.Note{
+			Title:      "Title",
+			GUID:       string(guid),
+			Notebook:   new(clinote.Notebook),
+			Attributes: &clinote.NoteAttributes{},
+		})
+		assert.NoError(err, "No error should be returned")
+		assert.Equal(author, expectedNote.Attributes.GetAuthor(), "Attributes should be fetched by GUID")
+		assert.False(expectedNote.Attributes.IsSetReminderOrder(), "Reminder should be cleared")
+	})
+}
+
+func TestConvertNoteAttributesSDK(t *testing.T) {
+	assert := assert.New(t)
+	order, at, done := int64(1), types.Timestamp(2), types.Timestamp(3)
+	n := convert(&types.Note{Attributes: &types.NoteAttributes{ReminderOrder: &order, ReminderTime: &at, ReminderDoneTime: &done}})
+	assert.Equal(&clinote.NoteAttributes{ReminderOrder: 1, ReminderTime: 2, ReminderDoneTime: 3}, n.Attributes, "Wrong attributes")
+	assert.Nil(convert(types.NewNote()).Attributes, "Attributes should be nil if not returned")
 }
 
 func TestFindNotes(t *testing.T) {
@@ -188,7 +394,9 @@ func TestFindNotes(t *testing.T) {
 	title := "Note title"
 	expectedNote.GUID = &GUID
 	expectedNote.Title = &title
-	nl := &notestore.NoteList{Notes: []*types.Note{expectedNote}}
+	total := int32(41)
+	start := int32(40)
+	nl := &notestore.NoteList{Notes: []*types.Note{expectedNote}, StartIndex: start, TotalNotes: total}
 	token := "token"
 	ns := &Notestore{
 		apiToken:   token,
@@ -196,20 +404,62 @@ func TestFindNotes(t *testing.T) {
 	}
 	t.Run("all notebooks", func(t *testing.T) {
 		filter := &clinote.NoteFilter{Words: "search term"}
-		notes, err := ns.FindNotes(filter, 0, 20)
+		notes, err := ns.FindNotes(filter, 40, 20)
 		assert.NoError(err, "Should not return an error")
-		assert.Len(notes, 1, "Wrong number of notes returned.")
-		assert.Equal(title, notes[0].Title, "Wrong title")
-		assert.Equal(string(GUID), notes[0].GUID, "Wrong GUID")
+		assert.Equal(40, notes.StartIndex, "Wrong start index")
+		assert.Equal(41, notes.TotalNotes, "Wrong total")
+		assert.Len(notes.Notes, 1, "Wrong number of notes returned.")
+		assert.Equal(title, notes.Notes[0].Title, "Wrong title")
+		assert.Equal(string(GUID), notes.Notes[0].GUID, "Wrong GUID")
 	})
 
 	t.Run("one notebook", func(t *testing.T) {
 		filter := &clinote.NoteFilter{NotebookGUID: "Book GUID"}
 		notes, err := ns.FindNotes(filter, 0, 20)
 		assert.NoError(err, "Should not return an error")
-		assert.Len(notes, 1, "Wrong number of notes returned.")
-		assert.Equal(title, notes[0].Title, "Wrong title")
-		assert.Equal(string(GUID), notes[0].GUID, "Wrong GUID")
+		assert.Len(notes.Notes, 1, "Wrong number of notes returned.")
+		assert.Equal(title, notes.Notes[0].Title, "Wrong title")
+		assert.Equal(string(GUID), notes.Notes[0].GUID, "Wrong GUID")
+	})
+
+	t.Run("filter options", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		filter := &clinote.NoteFilter{
+			Words:        "milk",
+			Order:        clinote.NoteFilterOrderTitle,
+			Ascending:    true,
+			TagGUIDs:     []string{"Tag GUID"},
+			CreatedAfter: 1525132800000,
+			Inactive:     true,
+			TimeZone:     "Europe/Stockholm",
+		}
+		_, err := ns.FindNotes(filter, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("milk created:20180501T000000Z", actual.GetWords(), "Wrong search words")
+		assert.Equal(clinote.NoteFilterOrderTitle, actual.GetOrder(), "Wrong order")
+		assert.True(actual.GetAscending(), "Should be ascending")
+		assert.Equal([]string{"Tag GUID"}, actual.GetTagGuids(), "Wrong tags")
+		assert.True(actual.GetInactive(), "Should search the trash")
+		assert.Equal("Europe/Stockholm", actual.GetTimeZone(), "Wrong time zone")
+		assert.False(actual.IsSetNotebookGuid(), "Notebook should not be set")
+	})
+
+	t.Run("default filter options", func(t *testing.T) {
+		var actual *notestore.NoteFilter
+		ns.evernoteNS = &mockAPI{findNote: func(_ string, f *notestore.NoteFilter, _ int32, _ int32) (*notestore.NoteList, error) {
+			actual = f
+			return nl, nil
+		}}
+		_, err := ns.FindNotes(new(clinote.NoteFilter), 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.False(actual.IsSetWords(), "Words should not be set")
+		assert.False(actual.IsSetOrder(), "Order should not be set")
+		assert.False(actual.IsSetAscending(), "Ascending should not be set")
+		assert.False(actual.IsSetInactive(), "Inactive should not be set")
 	})
 
 	t.Run("return error", func(t *testing.T) {
@@ -225,6 +475,167 @@ func TestFindNotes(t *testing.T) {
 	})
 }
 
+func TestTagsSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("list tags", func(t *testing.T) {
+		name := "Tag"
+		guid := types.GUID("Tag GUID")
+		api := &mockAPI{listTags: func(string) ([]*types.Tag, error) { return []*types.Tag{&types.Tag{GUID: &guid, Name: &name}}, nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		tags, err := ns.ListTags()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*clinote.Tag{&clinote.Tag{GUID: string(guid), Name: name}}, tags, "Wrong tags returned")
+	})
+	t.Run("return error from list", func(t *testing.T) {
+		api := &mockAPI{listTags: func(string) ([]*types.Tag, error) { return nil, errExpected }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		tags, err := ns.ListTags()
+		assert.Nil(tags, "No tags should be returned")
+		assert.Equal(errExpected, err, "Wrong error returned")
+	})
+	t.Run("create tag", func(t *testing.T) {
+		var saved *types.Tag
+		api := &mockAPI{createTag: func(k string, tag *types.Tag) (*types.Tag, error) { saved = tag; return tag, nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		err := ns.CreateTag(&clinote.Tag{Name: "New", ParentGUID: "Parent"})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("New", saved.GetName(), "Wrong tag name")
+		assert.Equal(types.GUID("Parent"), saved.GetParentGuid(), "Wrong parent")
+	})
+	t.Run("update requires GUID", func(t *testing.T) {
+		ns := &Notestore{apiToken: token}
+		err := ns.UpdateTag(&clinote.Tag{Name: "Name"})
+		assert.Equal(ErrNoGUIDSet, err, "Wrong error returned")
+	})
+	t.Run("update tag", func(t *testing.T) {
+		var saved *types.Tag
+		api := &mockAPI{updateTag: func(k string, tag *types.Tag) (int32, error) { saved = tag; return int32(0), nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		err := ns.UpdateTag(&clinote.Tag{Name: "New", GUID: "GUID"})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("New", saved.GetName(), "Wrong tag name")
+		assert.Equal(types.GUID("GUID"), saved.GetGUID(), "Wrong GUID")
+	})
+	t.Run("expunge tag", func(t *testing.T) {
+		var expunged types.GUID
+		api := &mockAPI{expungeTag: func(k string, g types.GUID) (int32, error) { expunged = g; return int32(0), nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		err := ns.ExpungeTag("GUID")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(types.GUID("GUID"), expunged, "Wrong tag expunged")
+	})
+}
+
+func TestNoteTagsSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("convert tags", func(t *testing.T) {
+		note := types.NewNote()
+		guid := types.GUID("Note GUID")
+		note.GUID = &guid
+		note.TagGuids = []string{"GUID1", "GUID2"}
+		n := convert(note)
+		assert.Equal([]*clinote.Tag{&clinote.Tag{GUID: "GUID1"}, &clinote.Tag{GUID: "GUID2"}}, n.Tags, "Wrong tags")
+	})
+	t.Run("update tags", func(t *testing.T) {
+		var saved *types.Note
+		ns := &Notestore{
+			apiToken:   token,
+			evernoteNS: &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { saved = n; return nil, nil }},
+		}
+		err := ns.UpdateNote(&clinote.Note{
+			Title:    "Title",
+			GUID:     "GUID",
+			Notebook: new(clinote.Notebook),
+			Tags:     []*clinote.Tag{&clinote.Tag{GUID: "Tag GUID"}, &clinote.Tag{Name: "New tag"}},
+		})
+		assert.NoError(err, "No error should be returned")
+		assert.Equal([]string{"Tag GUID"}, saved.TagGuids, "Wrong tag GUIDs")
+		assert.Equal([]string{"New tag"}, saved.TagNames, "Wrong tag names")
+	})
+	t.Run("clear tags", func(t *testing.T) {
+		var saved *types.Note
+		ns := &Notestore{
+			apiToken:   token,
+			evernoteNS: &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { saved = n; return nil, nil }},
+		}
+		err := ns.UpdateNote(&clinote.Note{Title: "Title", GUID: "GUID", Notebook: new(clinote.Notebook), Tags: []*clinote.Tag{}})
+		assert.NoError(err, "No error should be returned")
+		assert.True(saved.IsSetTagGuids(), "Tag GUIDs should be set")
+		assert.Len(saved.TagGuids, 0, "No tags should be set")
+	})
+}
+
+func TestResourcesSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	data := []byte("resource data")
+	hash := md5.Sum(data)
+	filename := "file.txt"
+	mime := "text/plain"
+	size := int32(len(data))
+	t.Run("get resource", func(t *testing.T) {
+		guid := types.GUID("GUID")
+		r := &types.Resource{
+			GUID:       &guid,
+			Mime:       &mime,
+			Data:       &types.Data{BodyHash: hash[:], Size: &size},
+			Attributes: &types.ResourceAttributes{FileName: &filename},
+		}
+		api := &mockAPI{getResource: func(k string, g types.GUID, d, rec, attr, alt bool) (*types.Resource, error) {
+			assert.False(d, "Data should not be requested")
+			assert.True(attr, "Attributes should be requested")
+			return r, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		res, err := ns.GetResource(string(guid))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(&clinote.Resource{GUID: "GUID", Filename: filename, Mime: mime, Hash: hex.EncodeToString(hash[:]), Size: len(data)}, res, "Wrong resource")
+	})
+	t.Run("get resource data", func(t *testing.T) {
+		api := &mockAPI{getResourceData: func(string, types.GUID) ([]byte, error) { return data, nil }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		d, err := ns.GetResourceData("GUID")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(data, d, "Wrong data returned")
+	})
+	t.Run("upload new resources", func(t *testing.T) {
+		var saved *types.Note
+		ns := &Notestore{
+			apiToken:   token,
+			evernoteNS: &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { saved = n; return nil, nil }},
+		}
+		err := ns.UpdateNote(&clinote.Note{
+			Title:     "Title",
+			GUID:      "GUID",
+			Notebook:  new(clinote.Notebook),
+			Resources: []*clinote.Resource{&clinote.Resource{GUID: "Existing"}, clinote.NewResource(filename, mime, data)},
+		})
+		assert.NoError(err, "No error should be returned")
+		assert.Len(saved.Resources, 2, "Both resources should be sent")
+		assert.Equal(types.GUID("Existing"), saved.Resources[0].GetGUID(), "Existing resource should be kept")
+		assert.Equal(data, saved.Resources[1].Data.Body, "Wrong data")
+		assert.Equal(hash[:], saved.Resources[1].Data.BodyHash, "Wrong hash")
+		assert.Equal(filename, saved.Resources[1].Attributes.GetFileName(), "Wrong filename")
+	})
+	t.Run("keep existing resources untouched", func(t *testing.T) {
+		var saved *types.Note
+		ns := &Notestore{
+			apiToken:   token,
+			evernoteNS: &mockAPI{updateNote: func(api string, n *types.Note) (*types.Note, error) { saved = n; return nil, nil }},
+		}
+		err := ns.UpdateNote(&clinote.Note{
+			Title:     "Title",
+			GUID:      "GUID",
+			Notebook:  new(clinote.Notebook),
+			Resources: []*clinote.Resource{&clinote.Resource{GUID: "Existing"}},
+		})
+		assert.NoError(err, "No error should be returned")
+		assert.False(saved.IsSetResources(), "Resources should not be sent")
+	})
+}
+
 func TestGetNoteContentSDK(t *testing.T) {
 	assert := assert.New(t)
 	expectedContent := "Note content"
@@ -238,15 +649,233 @@ func TestGetNoteContentSDK(t *testing.T) {
 	assert.Equal(expectedContent, content, "Wrong content")
 }
 
+func TestGetNoteSDK(t *testing.T) {
+	assert := assert.New(t)
+	guid, title, usn := types.GUID("GUID"), "Title", int32(12)
+	ns := &Notestore{apiToken: "token", evernoteNS: &mockAPI{getNote: func(k string, g types.GUID, content, data, recognition, alternate bool) (*types.Note, error) {
+		assert.False(content, "Content should not be requested")
+		return &types.Note{GUID: &g, Title: &title, UpdateSequenceNum: &usn}, nil
+	}}}
+	n, err := ns.GetNote(string(guid))
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("Title", n.Title, "Wrong title")
+	assert.Equal(usn, n.USN, "Wrong USN")
+
+	t.Run("not found", func(t *testing.T) {
+		ns.evernoteNS = &mockAPI{getNote: func(k string, g types.GUID, content, data, recognition, alternate bool) (*types.Note, error) {
+			return nil, edam.NewEDAMNotFoundException()
+		}}
+		_, err := ns.GetNote(string(guid))
+		assert.Equal(clinote.ErrNoNoteFound, err, "Wrong error returned")
+	})
+}
+
+func TestConnectionError(t *testing.T) {
+	assert := assert.New(t)
+	dialErr := &url.Error{Op: "Post", URL: "https://www.evernote.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	t.Run("update note", func(t *testing.T) {
+		ns := &Notestore{apiToken: "token", evernoteNS: &mockAPI{updateNote: func(string, *types.Note) (*types.Note, error) {
+			return nil, &mockTransportError{err: dialErr}
+		}}}
+		err := ns.UpdateNote(&clinote.Note{GUID: "GUID", Title: "Title", Notebook: new(clinote.Notebook)})
+		assert.Equal(clinote.ErrOffline, err, "Transport errors should be returned as offline")
+	})
+	t.Run("find notes", func(t *testing.T) {
+		ns := &Notestore{apiToken: "token", evernoteNS: &mockAPI{findNote: func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error) {
+			return nil, &mockTransportError{typeID: transportTimedOut}
+		}}}
+		_, err := ns.FindNotes(new(clinote.NoteFilter), 0, 20)
+		assert.Equal(clinote.ErrOffline, err, "Timeouts should be returned as offline")
+	})
+	tests := []struct {
+		name    string
+		err     error
+		offline bool
+	}{
+		{"dial error", &mockTransportError{err: dialErr}, true},
+		{"timeout", &mockTransportError{typeID: transportTimedOut, err: errExpected}, true},
+		{"end of file", &mockTransportError{typeID: transportEndOfFile, err: io.EOF}, true},
+		{"http error", &mockTransportError{err: errors.New("HTTP Response code: 500")}, false},
+		{"other error", errExpected, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := test.err
+			if test.offline {
+				expected = clinote.ErrOffline
+			}
+			assert.Equal(expected, connectionError(test.err), "Wrong error returned")
+		})
+	}
+}
+
+type mockTransportError struct {
+	typeID int
+	err    error
+}
+
+func (e *mockTransportError) Error() string { return "transport error" }
+func (e *mockTransportError) TypeId() int   { return e.typeID }
+func (e *mockTransportError) Err() error    { return e.err }
+
+func TestNoteVersionsSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("list versions", func(t *testing.T) {
+		api := &mockAPI{listVersions: func(k string, guid types.GUID) ([]*notestore.NoteVersionId, error) {
+			assert.Equal(types.GUID("Note"), guid, "Wrong note GUID")
+			return []*notestore.NoteVersionId{&notestore.NoteVersionId{UpdateSequenceNum: 5, Updated: 1000, Saved: 2000, Title: "Title"}}, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		versions, err := ns.ListNoteVersions("Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*clinote.NoteVersion{&clinote.NoteVersion{USN: 5, Title: "Title", Updated: 1000, Saved: 2000}}, versions, "Wrong versions")
+	})
+	t.Run("get version", func(t *testing.T) {
+		guid, title, content := types.GUID("Note"), "Title", "<en-note>Content</en-note>"
+		var withData bool
+		var usn int32
+		api := &mockAPI{getNoteVersion: func(k string, g types.GUID, u int32, d, r, a bool) (*types.Note, error) {
+			usn, withData = u, d
+			return &types.Note{GUID: &guid, Title: &title, Content: &content}, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		n, err := ns.GetNoteVersion("Note", 5)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(int32(5), usn, "Wrong version requested")
+		assert.True(withData, "Resource data should be requested")
+		assert.Equal(title, n.Title, "Wrong title")
+		assert.Equal(content, n.Body, "Wrong content")
+	})
+	t.Run("return error", func(t *testing.T) {
+		expectedErr := errors.New("expected")
+		api := &mockAPI{listVersions: func(string, types.GUID) ([]*notestore.NoteVersionId, error) { return nil, expectedErr }}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		_, err := ns.ListNoteVersions("Note")
+		assert.Equal(expectedErr, err, "Wrong error")
+	})
+}
+
+func TestSyncSDK(t *testing.T) {
+	assert := assert.New(t)
+	token := "token"
+	t.Run("sync state", func(t *testing.T) {
+		api := &mockAPI{getSyncState: func(string) (*notestore.SyncState, error) {
+			return &notestore.SyncState{CurrentTime: 3000, FullSyncBefore: 1000, UpdateCount: 42}, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		state, err := ns.GetSyncState()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(&clinote.SyncState{CurrentTime: 3000, FullSyncBefore: 1000, UpdateCount: 42}, state, "Wrong state")
+	})
+	t.Run("sync chunk", func(t *testing.T) {
+		noteGUID, notebookGUID, title := types.GUID("Note"), "Notebook", "Title"
+		usn, high, active := int32(5), int32(7), false
+		var filter *notestore.SyncChunkFilter
+		var after, max int32
+		api := &mockAPI{getSyncChunk: func(k string, a, m int32, f *notestore.SyncChunkFilter) (*notestore.SyncChunk, error) {
+			after, max, filter = a, m, f
+			return &notestore.SyncChunk{
+				ChunkHighUSN:  &high,
+				UpdateCount:   10,
+				Notes:         []*types.Note{&types.Note{GUID: &noteGUID, Title: &title, NotebookGuid: &notebookGUID, UpdateSequenceNum: &usn, Active: &active}},
+				ExpungedNotes: []string{"Expunged"},
+			}, nil
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		chunk, err := ns.GetSyncChunk(2, 100)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(int32(2), after, "Wrong USN requested")
+		assert.Equal(int32(100), max, "Wrong max entries")
+		assert.True(filter.GetIncludeNotes() && filter.GetIncludeNotebooks() && filter.GetIncludeTags() && filter.GetIncludeExpunged(), "Filter should include notes, notebooks, tags and expunged")
+		assert.Equal(int32(7), chunk.ChunkHighUSN, "Wrong chunk USN")
+		assert.Equal(int32(10), chunk.UpdateCount, "Wrong update count")
+		assert.Equal([]string{"Expunged"}, chunk.ExpungedNotes, "Wrong expunged notes")
+		assert.Len(chunk.Notes, 1, "Wrong number of notes")
+		assert.Equal("Title", chunk.Notes[0].Title, "Wrong title")
+		assert.Equal(int32(5), chunk.Notes[0].USN, "Wrong note USN")
+		assert.True(chunk.Notes[0].Deleted, "Inactive note should be marked as deleted")
+		assert.Equal(notebookGUID, chunk.Notes[0].Notebook.GUID, "Wrong notebook")
+	})
+	t.Run("return error", func(t *testing.T) {
+		api := &mockAPI{getSyncChunk: func(string, int32, int32, *notestore.SyncChunkFilter) (*notestore.SyncChunk, error) {
+			return nil, errExpected
+		}}
+		ns := &Notestore{apiToken: token, evernoteNS: api}
+		chunk, err := ns.GetSyncChunk(0, 100)
+		assert.Nil(chunk, "No chunk should be returned")
+		assert.Equal(errExpected, err, "Wrong error returned")
+	})
+}
+
 type mockAPI struct {
-	listNotebooks  func(string) ([]*types.Notebook, error)
-	updateNotebook func(string, *types.Notebook) (int32, error)
-	createNotebook func(string, *types.Notebook) (*types.Notebook, error)
-	createNote     func(string, *types.Note) (*types.Note, error)
-	deleteNote     func(string, types.GUID) (int32, error)
-	updateNote     func(string, *types.Note) (*types.Note, error)
-	findNote       func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error)
-	getNoteContent func(string, types.GUID) (string, error)
+	listNotebooks   func(string) ([]*types.Notebook, error)
+	updateNotebook  func(string, *types.Notebook) (int32, error)
+	createNotebook  func(string, *types.Notebook) (*types.Notebook, error)
+	expungeNotebook func(string, types.GUID) (int32, error)
+	getNotebook     func(string, types.GUID) (*types.Notebook, error)
+	createNote      func(string, *types.Note) (*types.Note, error)
+	deleteNote      func(string, types.GUID) (int32, error)
+	expungeNote     func(string, types.GUID) (int32, error)
+	expungeInactive func(string) (int32, error)
+	updateNote      func(string, *types.Note) (*types.Note, error)
+	findNote        func(string, *notestore.NoteFilter, int32, int32) (*notestore.NoteList, error)
+	getNoteContent  func(string, types.GUID) (string, error)
+	listTags        func(string) ([]*types.Tag, error)
+	createTag       func(string, *types.Tag) (*types.Tag, error)
+	updateTag       func(string, *types.Tag) (int32, error)
+	expungeTag      func(string, types.GUID) (int32, error)
+	getResource     func(string, types.GUID, bool, bool, bool, bool) (*types.Resource, error)
+	getResourceData func(string, types.GUID) ([]byte, error)
+	getSyncState    func(string) (*notestore.SyncState, error)
+	getNote         func(string, types.GUID, bool, bool, bool, bool) (*types.Note, error)
+	getSyncChunk    func(string, int32, int32, *notestore.SyncChunkFilter) (*notestore.SyncChunk, error)
+	listVersions    func(string, types.GUID) ([]*notestore.NoteVersionId, error)
+	getNoteVersion  func(string, types.GUID, int32, bool, bool, bool) (*types.Note, error)
+}
+
+func (a *mockAPI) ListNoteVersions(apiKey string, guid types.GUID) (r []*notestore.NoteVersionId, err error) {
+	return a.listVersions(apiKey, guid)
+}
+
+func (a *mockAPI) GetNoteVersion(apiKey string, guid types.GUID, usn int32, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error) {
+	return a.getNoteVersion(apiKey, guid, usn, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+}
+
+func (a *mockAPI) GetNote(apiKey string, guid types.GUID, withContent bool, withResourcesData bool, withResourcesRecognition bool, withResourcesAlternateData bool) (r *types.Note, err error) {
+	return a.getNote(apiKey, guid, withContent, withResourcesData, withResourcesRecognition, withResourcesAlternateData)
+}
+
+func (a *mockAPI) GetSyncState(apiKey string) (r *notestore.SyncState, err error) {
+	return a.getSyncState(apiKey)
+}
+
+func (a *mockAPI) GetFilteredSyncChunk(apiKey string, afterUSN int32, maxEntries int32, filter *notestore.SyncChunkFilter) (r *notestore.SyncChunk, err error) {
+	return a.getSyncChunk(apiKey, afterUSN, maxEntries, filter)
+}
+
+func (a *mockAPI) GetResource(apiKey string, guid types.GUID, withData bool, withRecognition bool, withAttributes bool, withAlternateData bool) (r *types.Resource, err error) {
+	return a.getResource(apiKey, guid, withData, withRecognition, withAttributes, withAlternateData)
+}
+
+func (a *mockAPI) GetResourceData(apiKey string, guid types.GUID) (r []byte, err error) {
+	return a.getResourceData(apiKey, guid)
+}
+
+func (a *mockAPI) ListTags(apiKey string) (r []*types.Tag, err error) {
+	return a.listTags(apiKey)
+}
+
+func (a *mockAPI) CreateTag(apiKey string, tag *types.Tag) (r *types.Tag, err error) {
+	return a.createTag(apiKey, tag)
+}
+
+func (a *mockAPI) UpdateTag(apiKey string, tag *types.Tag) (r int32, err error) {
+	return a.updateTag(apiKey, tag)
+}
+
+func (a *mockAPI) ExpungeTag(apiKey string, guid types.GUID) (r int32, err error) {
+	return a.expungeTag(apiKey, guid)
 }
 
 func (a *mockAPI) ListNotebooks(apiKey string) (r []*types.Notebook, err error) {
@@ -261,6 +890,10 @@ func (a *mockAPI) UpdateNotebook(apiKey string, notebook *types.Notebook) (r int
 	return a.updateNotebook(apiKey, notebook)
 }
 
+func (a *mockAPI) ExpungeNotebook(apiKey string, guid types.GUID) (r int32, err error) {
+	return a.expungeNotebook(apiKey, guid)
+}
+
 func (a *mockAPI) CreateNote(apiKey string, note *types.Note) (r *types.Note, err error) {
 	return a.createNote(apiKey, note)
 }
@@ -273,6 +906,14 @@ func (a *mockAPI) DeleteNote(apiKey string, guid types.GUID) (int32, error) {
 	return a.deleteNote(apiKey, guid)
 }
 
+func (a *mockAPI) ExpungeNote(apiKey string, guid types.GUID) (int32, error) {
+	return a.expungeNote(apiKey, guid)
+}
+
+func (a *mockAPI) ExpungeInactiveNotes(apiKey string) (int32, error) {
+	return a.expungeInactive(apiKey)
+}
+
 func (a *mockAPI) UpdateNote(authenticationToken string, note *types.Note) (r *types.Note, err error) {
 	return a.updateNote(authenticationToken, note)
 }
@@ -282,5 +923,5 @@ func (a *mockAPI) GetNoteContent(authenticationToken string, guid types.GUID) (r
 }
 
 func (a *mockAPI) GetNotebook(authenticationToken string, guid types.GUID) (r *types.Notebook, err error) {
-	panic("not implemented")
+	return a.getNotebook(authenticationToken, guid)
 }
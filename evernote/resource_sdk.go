@@ -0,0 +1,102 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"encoding/hex"
+
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+)
+
+func convertResource(r *types.Resource) *clinote.Resource {
+	res := &clinote.Resource{
+		GUID:     string(r.GetGUID()),
+		NoteGUID: string(r.GetNoteGuid()),
+		Mime:     r.GetMime(),
+	}
+	if r.Data != nil {
+		res.Hash = hex.EncodeToString(r.Data.BodyHash)
+		res.Size = int(r.Data.GetSize())
+		res.Data = r.Data.Body
+	}
+	if r.Attributes != nil {
+		res.Filename = r.Attributes.GetFileName()
+	}
+	return res
+}
+
+func convertResources(rs []*types.Resource) []*clinote.Resource {
+	if len(rs) == 0 {
+		return nil
+	}
+	a := make([]*clinote.Resource, len(rs))
+	for i, r := range rs {
+		a[i] = convertResource(r)
+	}
+	return a
+}
+
+// transferNoteResources sets the note's resources. The server replaces all
+// the note's resources with the list, so the list is only sent when new
+// resources have been added. Otherwise the existing resources are left untouched.
+func transferNoteResources(src *clinote.Note, dst *types.Note) {
+	newResources := false
+	for _, r := range src.Resources {
+		if r.GUID == "" {
+			newResources = true
+			break
+		}
+	}
+	if !newResources {
+		return
+	}
+	dst.Resources = make([]*types.Resource, len(src.Resources))
+	for i, r := range src.Resources {
+		dst.Resources[i] = transferResourceData(r)
+	}
+}
+
+func transferResourceData(src *clinote.Resource) *types.Resource {
+	dst := types.NewResource()
+	if src.GUID != "" {
+		guid := types.GUID(src.GUID)
+		dst.GUID = &guid
+	}
+	if src.Mime != "" {
+		mime := src.Mime
+		dst.Mime = &mime
+	}
+	data := types.NewData()
+	hash, err := hex.DecodeString(src.Hash)
+	if err == nil {
+		data.BodyHash = hash
+	}
+	size := int32(src.Size)
+	data.Size = &size
+	if src.Data != nil {
+		data.Body = src.Data
+	}
+	dst.Data = data
+	if src.Filename != "" {
+		filename := src.Filename
+		attachment := true
+		dst.Attributes = &types.ResourceAttributes{FileName: &filename, Attachment: &attachment}
+	}
+	return dst
+}
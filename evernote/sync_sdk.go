@@ -0,0 +1,62 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/evernote-sdk-golang/notestore"
+)
+
+// newSyncChunkFilter returns a filter for the notes, notebooks and tags
+// including the expunged entries.
+func newSyncChunkFilter() *notestore.SyncChunkFilter {
+	include := true
+	return &notestore.SyncChunkFilter{
+		IncludeNotes:          &include,
+		IncludeNoteResources:  &include,
+		IncludeNoteAttributes: &include,
+		IncludeNotebooks:      &include,
+		IncludeTags:           &include,
+		IncludeExpunged:       &include,
+	}
+}
+
+func convertSyncState(state *notestore.SyncState) *clinote.SyncState {
+	return &clinote.SyncState{
+		CurrentTime:    int64(state.GetCurrentTime()),
+		FullSyncBefore: int64(state.GetFullSyncBefore()),
+		UpdateCount:    state.GetUpdateCount(),
+	}
+}
+
+func convertSyncChunk(chunk *notestore.SyncChunk) *clinote.SyncChunk {
+	c := &clinote.SyncChunk{
+		ChunkHighUSN:      chunk.GetChunkHighUSN(),
+		UpdateCount:       chunk.GetUpdateCount(),
+		Notes:             make([]*clinote.Note, len(chunk.Notes)),
+		Notebooks:         convertNotebooks(chunk.Notebooks),
+		Tags:              convertTags(chunk.Tags),
+		ExpungedNotes:     chunk.ExpungedNotes,
+		ExpungedNotebooks: chunk.ExpungedNotebooks,
+		ExpungedTags:      chunk.ExpungedTags,
+	}
+	for i, n := range chunk.Notes {
+		c.Notes[i] = convert(n)
+	}
+	return c
+}
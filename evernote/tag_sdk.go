@@ -0,0 +1,78 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package evernote
+
+import (
+	"github.com/TcM1911/clinote"
+	"github.com/TcM1911/evernote-sdk-golang/types"
+)
+
+func convertTags(ts []*types.Tag) []*clinote.Tag {
+	a := make([]*clinote.Tag, len(ts), len(ts))
+	for i, t := range ts {
+		a[i] = &clinote.Tag{GUID: string(t.GetGUID()), Name: t.GetName(), ParentGUID: string(t.GetParentGuid())}
+	}
+	return a
+}
+
+func transferTagData(src *clinote.Tag, dst *types.Tag) {
+	dst.Name = &(src.Name)
+	if src.GUID != "" {
+		guid := types.GUID(src.GUID)
+		dst.GUID = &guid
+	}
+	if src.ParentGUID != "" {
+		parent := types.GUID(src.ParentGUID)
+		dst.ParentGuid = &parent
+	}
+}
+
+// noteTags returns the note's tags. The names are only set
+// if the server included them in the response.
+func noteTags(note *types.Note) []*clinote.Tag {
+	if len(note.TagGuids) == 0 {
+		return nil
+	}
+	tags := make([]*clinote.Tag, len(note.TagGuids))
+	for i, guid := range note.TagGuids {
+		tags[i] = &clinote.Tag{GUID: guid}
+		if len(note.TagNames) == len(note.TagGuids) {
+			tags[i].Name = note.TagNames[i]
+		}
+	}
+	return tags
+}
+
+// transferNoteTags sets the note's tags. Tags with a GUID are
+// referenced by the GUID and the other tags are referenced by name.
+// The server creates any tag in the name list that doesn't exist.
+// If the tags are nil, the note's tags are not changed. An empty list
+// removes all the tags from the note.
+func transferNoteTags(src *clinote.Note, dst *types.Note) {
+	if src.Tags == nil {
+		return
+	}
+	dst.TagGuids = make([]string, 0, len(src.Tags))
+	for _, t := range src.Tags {
+		if t.GUID != "" {
+			dst.TagGuids = append(dst.TagGuids, t.GUID)
+			continue
+		}
+		dst.TagNames = append(dst.TagNames, t.Name)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// DefaultJournalTitle is the template for the journal note titles if none is
+// set in the settings.
+const DefaultJournalTitle = "Journal {{.Date}}"
+
+// journalEntryFormat is the time format of the journal entries.
+const journalEntryFormat = "15:04"
+
+const (
+	// journalQuery is the search grammar used to only search the note titles.
+	journalQuery    = `intitle:"`
+	journalPageSize = 50
+)
+
+const (
+	listStart = "<ul>"
+	listEnd   = "</ul>"
+)
+
+// JournalTitle returns the title of the journal note for the date. The title
+// is the pattern executed as a Go template with the TemplateData for the date.
+func JournalTitle(pattern string, date time.Time) (string, error) {
+	if pattern == "" {
+		pattern = DefaultJournalTitle
+	}
+	tmpl, err := template.New("journal").Parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, NewTemplateData(date, nil)); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// EditJournal opens the journal note for the date in the editor. If the note
+// doesn't exist, it's created once the editor has been closed. The title and
+// notebook of the journal notes are set in the settings. If the server can't
+// be reached, the change is queued and ErrChangeQueued is returned.
+func EditJournal(client *Client, date time.Time) error {
+	note, found, err := findJournal(client, date)
+	if err != nil {
+		return err
+	}
+	if found {
+		if err = loadNoteContent(client.NoteStore, note); err != nil {
+			return err
+		}
+		return editExistingNote(client, note, nil, DefaultNoteOption)
+	}
+	if err = CreateAndEditNewNote(client, note, DefaultNoteOption); err != nil {
+		return err
+	}
+	return saveCreatedJournal(client, note)
+}
+
+// AppendJournal adds the text as a bullet with the time of now to the end of
+// the journal note for the date. The bullet is added to the list at the end of
+// the note if there is one. If the note doesn't exist, it's created.
+// If the server can't be reached, the change is queued and ErrChangeQueued
+// is returned.
+func AppendJournal(client *Client, date time.Time, text string, now time.Time) error {
+	if strings.TrimSpace(text) == "" {
+		return ErrNoContent
+	}
+	entry := "- " + now.Format(journalEntryFormat) + " " + text
+	note, found, err := findJournal(client, date)
+	if err != nil {
+		return err
+	}
+	if !found {
+		note.MD = entry
+		if err = CreateNote(client, note, DefaultNoteOption); err != nil {
+			return err
+		}
+		return saveCreatedJournal(client, note)
+	}
+	item, err := noteFragment(&Note{MD: entry}, false)
+	if err != nil {
+		return err
+	}
+	return updateNoteContent(client.Store, client.NoteStore, note, nil, func(body string) string {
+		return appendListItem(body, item)
+	})
+}
+
+// appendListItem adds the list to the end of the body. If the body ends with
+// a list, the items are added to it instead so the entries stay in one list.
+func appendListItem(body, list string) string {
+	list = strings.TrimSpace(list)
+	trimmed := strings.TrimRightFunc(body, unicode.IsSpace)
+	if !strings.HasSuffix(trimmed, listEnd) || !strings.HasPrefix(list, listStart) || strings.Count(list, listStart) != 1 {
+		return body + list
+	}
+	items := strings.TrimRightFunc(strings.TrimSuffix(trimmed, listEnd), unicode.IsSpace)
+	return items + strings.TrimPrefix(list, listStart)
+}
+
+// findJournal returns the journal note for the date. If the note doesn't exist,
+// a new note with the title and notebook of the journal note is returned and
+// found is false.
+func findJournal(client *Client, date time.Time) (note *Note, found bool, err error) {
+	settings, err := client.Store.GetSettings()
+	if err != nil {
+		return nil, false, err
+	}
+	title, err := JournalTitle(settings.JournalTitle, date)
+	if err != nil {
+		return nil, false, err
+	}
+	var nb *Notebook
+	if settings.JournalNotebook != "" {
+		if nb, err = FindNotebook(client.Store, client.NoteStore, settings.JournalNotebook); err != nil {
+			return nil, false, err
+		}
+	}
+	if note, err = searchJournal(client.NoteStore, title, nb); err != nil {
+		return nil, false, err
+	}
+	// A note created just before may not be searchable yet.
+	if note == nil {
+		if note, err = createdJournal(client, title, nb); err != nil {
+			return nil, false, err
+		}
+	}
+	if note != nil {
+		return note, true, nil
+	}
+	return &Note{Title: title, Notebook: nb}, false, nil
+}
+
+// searchJournal searches for the note with the title in the notebook. Only the
+// note titles are searched so notes mentioning the title aren't matched. Nil is
+// returned if no note was found.
+func searchJournal(ns NotestoreClient, title string, nb *Notebook) (*Note, error) {
+	filter := &NoteFilter{Words: journalQuery + strings.Replace(title, `"`, "", -1) + `"`}
+	if nb != nil {
+		filter.NotebookGUID = nb.GUID
+	}
+	var note *Note
+	err := FindAllNotes(ns, filter, 0, journalPageSize, func(list *NoteList) error {
+		for _, n := range list.Notes {
+			if note == nil && n.Title == title {
+				note = n
+			}
+		}
+		return nil
+	})
+	return note, err
+}
+
+// createdJournal returns the journal note with the title from the local note
+// store if it still is in the notebook on the server. The journal notes are
+// saved to the local note store when they are created since the server's
+// search index isn't updated right away. Nil is returned if no note was found.
+func createdJournal(client *Client, title string, nb *Notebook) (*Note, error) {
+	notes, err := client.Store.GetLocalNotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		if n.Title != title || n.Deleted || (nb != nil && (n.Notebook == nil || n.Notebook.GUID != nb.GUID)) {
+			continue
+		}
+		note, err := client.NoteStore.GetNote(n.GUID)
+		if err == ErrNoNoteFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if note.Title == title && !note.Deleted && (nb == nil || note.Notebook.GUID == nb.GUID) {
+			return note, nil
+		}
+	}
+	return nil, nil
+}
+
+// saveCreatedJournal saves the created journal note to the local note store
+// so it's found before the server's search index has been updated.
+func saveCreatedJournal(client *Client, note *Note) error {
+	if note.GUID == "" {
+		return nil
+	}
+	return client.Store.SaveLocalNote(note)
+}
@@ -0,0 +1,168 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalTitle(t *testing.T) {
+	assert := assert.New(t)
+	date := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{"default", "", "Journal 2026-10-18"},
+		{"week", "Week {{.Week}}, {{.Year}}", "Week 42, 2026"},
+		{"custom layout", "{{.Now.Format \"Mon 2 Jan\"}}", "Sun 18 Oct"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			title, err := JournalTitle(test.pattern, date)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.expected, title, "Wrong title")
+		})
+	}
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := JournalTitle("{{.Date", date)
+		assert.Error(err, "Should return an error")
+	})
+}
+
+func TestAppendJournal(t *testing.T) {
+	assert := assert.New(t)
+	date := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	var snapshots []*NoteSnapshot
+	var local []*Note
+	store := &mockStore{
+		getLocalNotes: func() ([]*Note, error) { return local, nil },
+		saveLocalNote: func(n *Note) error { local = append(local, n); return nil },
+		getSearch:     func() ([]*Note, error) { return nil, nil },
+		getSettings: func() (*Settings, error) {
+			return &Settings{JournalTitle: "Log {{.Date}}", JournalNotebook: "Journal"}, nil
+		},
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Timestamp: time.Now(), Notebooks: []*Notebook{&Notebook{GUID: "NB1", Name: "Journal"}}, Limit: time.Hour}, nil
+		},
+		getNoteSnapshots: func(guid string) ([]*NoteSnapshot, error) { return snapshots, nil },
+		saveNoteSnapshot: func(s *NoteSnapshot) error { snapshots = append(snapshots, s); return nil },
+	}
+
+	t.Run("append to existing note", func(t *testing.T) {
+		var filter *NoteFilter
+		var updated *Note
+		ns := new(mockNS)
+		ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) {
+			if filter == nil {
+				filter = f
+			}
+			return &NoteList{Notes: []*Note{&Note{Title: "Log 2026-10-18", GUID: "GUID", Notebook: &Notebook{GUID: "NB1"}}}}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) { return XMLHeader + "<en-note><div>Morning</div></en-note>", nil }
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Journal"}, nil }
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		err := AppendJournal(&Client{Store: store, NoteStore: ns}, date, "Coffee", now)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("NB1", filter.NotebookGUID, "Should search in the journal notebook")
+		assert.Equal(`intitle:"Log 2026-10-18"`, filter.Words, "Should only search the titles")
+		assert.Contains(updated.Body, "<div>Morning</div><ul>\n<li>09:30 Coffee</li>", "Entry should be added to the end")
+	})
+	t.Run("add to trailing list", func(t *testing.T) {
+		var updated *Note
+		ns := new(mockNS)
+		ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) {
+			return &NoteList{Notes: []*Note{&Note{Title: "Log 2026-10-18", GUID: "GUID", Notebook: &Notebook{GUID: "NB1"}}}}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) {
+			return XMLHeader + "<en-note><ul>\n<li>08:00 Tea</li>\n</ul>\n</en-note>", nil
+		}
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Journal"}, nil }
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		err := AppendJournal(&Client{Store: store, NoteStore: ns}, date, "Coffee", now)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(updated.Body, "<ul>\n<li>08:00 Tea</li>\n<li>09:30 Coffee</li>\n</ul></en-note>", "Entry should be added to the list")
+		assert.Equal(1, strings.Count(updated.Body, "<ul>"), "Only one list should be in the note")
+	})
+	t.Run("title is a number", func(t *testing.T) {
+		var updated *Note
+		numbered := *store
+		numbered.getSearch = func() ([]*Note, error) {
+			return []*Note{&Note{Title: "Saved", GUID: "Saved GUID", Notebook: &Notebook{GUID: "NB2"}}}, nil
+		}
+		numbered.getSettings = func() (*Settings, error) {
+			return &Settings{JournalTitle: "{{.Year}}", JournalNotebook: "Journal"}, nil
+		}
+		ns := new(mockNS)
+		ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) {
+			return &NoteList{Notes: []*Note{&Note{Title: "2026", GUID: "GUID", Notebook: &Notebook{GUID: "NB1"}}}}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) { return XMLHeader + "<en-note></en-note>", nil }
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Journal"}, nil }
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		err := AppendJournal(&Client{Store: &numbered, NoteStore: ns}, date, "Coffee", now)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("GUID", updated.GUID, "The journal note should be updated")
+	})
+	t.Run("create note", func(t *testing.T) {
+		var created *Note
+		ns := new(mockNS)
+		ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) { return &NoteList{}, nil }
+		ns.createNote = func(n *Note) error { n.GUID = "New GUID"; created = n; return nil }
+		err := AppendJournal(&Client{Store: store, NoteStore: ns}, date, "Coffee", now)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Log 2026-10-18", created.Title, "Wrong title")
+		assert.Equal("NB1", created.Notebook.GUID, "Note should be created in the journal notebook")
+		assert.Equal("- 09:30 Coffee", created.MD, "Wrong entry")
+		assert.Equal([]*Note{created}, local, "Note should be saved to the local note store")
+	})
+	t.Run("append to note created just before", func(t *testing.T) {
+		var updated *Note
+		ns := new(mockNS)
+		// The new note isn't in the search index yet.
+		ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) { return &NoteList{}, nil }
+		ns.createNote = func(n *Note) error { return errors.New("should not be called") }
+		ns.getNote = func(guid string) (*Note, error) {
+			return &Note{Title: "Log 2026-10-18", GUID: guid, Notebook: &Notebook{GUID: "NB1"}}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) { return enmlDocument("<ul>\n<li>09:30 Coffee</li>\n</ul>"), nil }
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Journal"}, nil }
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		err := AppendJournal(&Client{Store: store, NoteStore: ns}, date, "Tea", now.Add(time.Minute))
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(updated, "The created note should be updated") {
+			assert.Equal("New GUID", updated.GUID, "Wrong note updated")
+			assert.Contains(updated.Body, "<li>09:30 Coffee</li>\n<li>09:31 Tea</li>", "Entry should be added")
+		}
+	})
+	t.Run("no text", func(t *testing.T) {
+		err := AppendJournal(&Client{Store: store, NoteStore: new(mockNS)}, date, " ", now)
+		assert.Equal(ErrNoContent, err, "Wrong error returned")
+	})
+}
@@ -0,0 +1,306 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrOffline is returned if the operation requires a connection to the server.
+var ErrOffline = errors.New("not connected to the server")
+
+// LocalNotestore is a notestore backed by the local copy of the user's notes.
+// The copy is kept up to date with Sync. Changes to notes are saved to the local
+// copy and queued until they can be pushed to the server with ReplayChanges.
+type LocalNotestore struct {
+	db Storager
+}
+
+// NewLocalNotestore creates a notestore that reads from the local note store.
+func NewLocalNotestore(db Storager) *LocalNotestore {
+	return &LocalNotestore{db: db}
+}
+
+// HasLocalCopy returns true if the local note store has been synced.
+func HasLocalCopy(db Storager) bool {
+	state, err := db.GetLocalSyncState()
+	return err == nil && state.USN > 0
+}
+
+// GetAllNotebooks returns all the of users notebooks.
+func (s *LocalNotestore) GetAllNotebooks() ([]*Notebook, error) {
+	return s.db.GetLocalNotebooks()
+}
+
+// GetNotebook returns the notebook with the specific GUID.
+func (s *LocalNotestore) GetNotebook(guid string) (*Notebook, error) {
+	bs, err := s.db.GetLocalNotebooks()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bs {
+		if b.GUID == guid {
+			return b, nil
+		}
+	}
+	return nil, ErrNoNotebookFound
+}
+
+// FindNotes searches the local notes. All the words in the filter have to be
+// in either the title or the content of the note. Notes in the trash are excluded.
+func (s *LocalNotestore) FindNotes(filter *NoteFilter, offset, count int) (*NoteList, error) {
+	all, err := s.db.GetLocalNotes()
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(strings.ToLower(filter.Words))
+	notes := make([]*Note, 0)
+	for _, n := range all {
+		if n.Deleted != filter.Inactive || (filter.NotebookGUID != "" && (n.Notebook == nil || n.Notebook.GUID != filter.NotebookGUID)) {
+			continue
+		}
+		if !matchWords(n, words) || !matchFilter(n, filter) {
+			continue
+		}
+		// Search results don't include the content.
+		note := *n
+		note.Body = ""
+		notes = append(notes, &note)
+	}
+	sortNotes(notes, filter.Order, filter.Ascending)
+	list := &NoteList{Notes: []*Note{}, StartIndex: offset, TotalNotes: len(notes)}
+	if offset >= len(notes) {
+		return list, nil
+	}
+	notes = notes[offset:]
+	if count < len(notes) {
+		notes = notes[:count]
+	}
+	list.Notes = notes
+	return list, nil
+}
+
+// GetNote returns the local note's metadata.
+func (s *LocalNotestore) GetNote(guid string) (*Note, error) {
+	n, err := s.db.GetLocalNote(guid)
+	if err != nil {
+		return nil, err
+	}
+	n.Body = ""
+	return n, nil
+}
+
+// GetNoteContent returns the content of the local note.
+func (s *LocalNotestore) GetNoteContent(guid string) (string, error) {
+	n, err := s.db.GetLocalNote(guid)
+	if err != nil {
+		return "", err
+	}
+	return n.Body, nil
+}
+
+// ListTags returns all the user's tags.
+func (s *LocalNotestore) ListTags() ([]*Tag, error) {
+	return s.db.GetLocalTags()
+}
+
+// GetResource returns the resource's metadata from the local notes.
+func (s *LocalNotestore) GetResource(guid string) (*Resource, error) {
+	notes, err := s.db.GetLocalNotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		for _, r := range n.Resources {
+			if r.GUID == guid {
+				return r, nil
+			}
+		}
+	}
+	return nil, ErrNoResourceFound
+}
+
+// GetResourceData is not available offline since the local copy doesn't include the data.
+func (s *LocalNotestore) GetResourceData(guid string) ([]byte, error) {
+	return nil, ErrOffline
+}
+
+// GetSyncState is not available offline.
+func (s *LocalNotestore) GetSyncState() (*SyncState, error) {
+	return nil, ErrOffline
+}
+
+// GetSyncChunk is not available offline.
+func (s *LocalNotestore) GetSyncChunk(afterUSN int32, maxEntries int) (*SyncChunk, error) {
+	return nil, ErrOffline
+}
+
+// UpdateNote queues the change and saves it to the local copy. If only the
+// metadata has changed and the note is in another notebook, it's queued as a move.
+func (s *LocalNotestore) UpdateNote(note *Note) error {
+	local, err := s.db.GetLocalNote(note.GUID)
+	if err != nil {
+		return err
+	}
+	t := ChangeUpdate
+	if note.Body == "" && note.Notebook != nil && local.Notebook != nil && note.Notebook.GUID != local.Notebook.GUID {
+		t = ChangeMove
+	}
+	if err = queueChange(s.db, t, note); err != nil {
+		return err
+	}
+	updated := *note
+	if updated.Body == "" {
+		updated.Body = local.Body
+	}
+	return s.db.SaveLocalNote(&updated)
+}
+
+// DeleteNote queues the note to be moved to the trash and marks the local copy as deleted.
+func (s *LocalNotestore) DeleteNote(guid string) error {
+	local, err := s.db.GetLocalNote(guid)
+	if err != nil {
+		return err
+	}
+	note := *local
+	note.Body = ""
+	if err = queueChange(s.db, ChangeDelete, &note); err != nil {
+		return err
+	}
+	local.Deleted = true
+	return s.db.SaveLocalNote(local)
+}
+
+// CreateNote queues the new note. The note is added to the local copy
+// by the sync after it has been pushed to the server.
+func (s *LocalNotestore) CreateNote(note *Note) error {
+	return queueChange(s.db, ChangeCreate, note)
+}
+
+// ExpungeNote is not available offline.
+func (s *LocalNotestore) ExpungeNote(guid string) error {
+	return ErrOffline
+}
+
+// ExpungeInactiveNotes is not available offline.
+func (s *LocalNotestore) ExpungeInactiveNotes() error {
+	return ErrOffline
+}
+
+// CreateNotebook is not available offline.
+func (s *LocalNotestore) CreateNotebook(book *Notebook, defaultNotebook bool) error {
+	return ErrOffline
+}
+
+// ExpungeNotebook is not available offline.
+func (s *LocalNotestore) ExpungeNotebook(guid string) error {
+	return ErrOffline
+}
+
+// UpdateNotebook is not available offline.
+func (s *LocalNotestore) UpdateNotebook(book *Notebook) error {
+	return ErrOffline
+}
+
+// CreateTag is not available offline.
+func (s *LocalNotestore) CreateTag(tag *Tag) error {
+	return ErrOffline
+}
+
+// UpdateTag is not available offline.
+func (s *LocalNotestore) UpdateTag(tag *Tag) error {
+	return ErrOffline
+}
+
+// ExpungeTag is not available offline.
+func (s *LocalNotestore) ExpungeTag(guid string) error {
+	return ErrOffline
+}
+
+// matchWords returns true if all the words are in the title or content.
+func matchWords(n *Note, words []string) bool {
+	title, body := strings.ToLower(n.Title), strings.ToLower(n.Body)
+	for _, w := range words {
+		if !strings.Contains(title, w) && !strings.Contains(body, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchFilter returns true if the note has all the filter's tags, is within the time
+// ranges and has an open reminder and checkboxes if the filter requires it.
+func matchFilter(n *Note, filter *NoteFilter) bool {
+	if (filter.Reminders && !hasOpenReminder(n)) || (filter.Todos && !hasTodos(n)) {
+		return false
+	}
+	for _, guid := range filter.TagGUIDs {
+		found := false
+		for _, t := range n.Tags {
+			if t.GUID == guid {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return inRange(n.Created, filter.CreatedAfter, filter.CreatedBefore) &&
+		inRange(n.Updated, filter.UpdatedAfter, filter.UpdatedBefore)
+}
+
+// inRange returns true if the timestamp is in the range. A zero limit is ignored.
+func inRange(ts, after, before int64) bool {
+	return (after == 0 || ts >= after) && (before == 0 || ts < before)
+}
+
+// sortNotes sorts the notes in the order used by the server. Notes are sorted
+// in descending order unless ascending is true. Relevance is not known for the
+// local notes so they are sorted by the update time instead.
+func sortNotes(notes []*Note, order int32, ascending bool) {
+	var less func(a, b *Note) bool
+	switch order {
+	case NoteFilterOrderTitle:
+		less = func(a, b *Note) bool { return a.Title < b.Title }
+	case NoteFilterOrderCreated:
+		less = func(a, b *Note) bool { return a.Created < b.Created }
+	case NoteFilterOrderSequenceNumber:
+		less = func(a, b *Note) bool { return a.USN < b.USN }
+	default:
+		less = func(a, b *Note) bool { return a.Updated < b.Updated }
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		if ascending {
+			return less(notes[i], notes[j])
+		}
+		return less(notes[j], notes[i])
+	})
+}
+
+// ListNoteVersions is not available offline.
+func (s *LocalNotestore) ListNoteVersions(guid string) ([]*NoteVersion, error) {
+	return nil, ErrOffline
+}
+
+// GetNoteVersion is not available offline.
+func (s *LocalNotestore) GetNoteVersion(guid string, usn int32) (*Note, error) {
+	return nil, ErrOffline
+}
@@ -0,0 +1,142 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalNotestore(t *testing.T) {
+	assert := assert.New(t)
+	notebook := &Notebook{GUID: "Notebook GUID", Name: "Notebook"}
+	notes := []*Note{
+		&Note{GUID: "1", Title: "Shopping list", Body: "<en-note>Milk</en-note>", Updated: 1, Created: 3, Notebook: notebook, Tags: []*Tag{&Tag{GUID: "Tag1"}, &Tag{GUID: "Tag2"}}},
+		&Note{GUID: "2", Title: "Meeting notes", Body: "<en-note>Agenda</en-note>", Updated: 3, Created: 2, Notebook: &Notebook{GUID: "Other"}, Attributes: &NoteAttributes{ReminderOrder: 1, ReminderDoneTime: 2}},
+		&Note{GUID: "3", Title: "Another list", Body: "<en-note><en-todo/>Books</en-note>", Updated: 2, Created: 1, Notebook: notebook, Tags: []*Tag{&Tag{GUID: "Tag1"}}, Attributes: &NoteAttributes{ReminderOrder: 1}},
+		&Note{GUID: "4", Title: "Deleted list", Updated: 4, Deleted: true, Notebook: notebook},
+	}
+	store := &mockStore{
+		getLocalNotes:     func() ([]*Note, error) { return notes, nil },
+		getLocalNote:      func(guid string) (*Note, error) { return notes[0], nil },
+		getLocalNotebooks: func() ([]*Notebook, error) { return []*Notebook{notebook}, nil },
+	}
+	ns := NewLocalNotestore(store)
+	titles := func(list *NoteList) []string {
+		a := make([]string, len(list.Notes))
+		for i, n := range list.Notes {
+			a[i] = n.Title
+		}
+		return a
+	}
+
+	t.Run("find all", func(t *testing.T) {
+		found, err := ns.FindNotes(new(NoteFilter), 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"Meeting notes", "Another list", "Shopping list"}, titles(found), "Wrong notes returned")
+		assert.Equal("", found.Notes[0].Body, "Content should not be included")
+		assert.NotEqual("", notes[1].Body, "Stored note should not be changed")
+	})
+	t.Run("find words", func(t *testing.T) {
+		found, err := ns.FindNotes(&NoteFilter{Words: "LIST milk"}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"Shopping list"}, titles(found), "Wrong notes returned")
+	})
+	t.Run("find in notebook", func(t *testing.T) {
+		found, err := ns.FindNotes(&NoteFilter{NotebookGUID: "Notebook GUID", Order: NoteFilterOrderTitle, Ascending: true}, 0, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"Another list", "Shopping list"}, titles(found), "Wrong notes returned")
+	})
+	t.Run("find with filter", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			filter   *NoteFilter
+			expected []string
+		}{
+			{"title descending", &NoteFilter{Order: NoteFilterOrderTitle}, []string{"Shopping list", "Meeting notes", "Another list"}},
+			{"created ascending", &NoteFilter{Order: NoteFilterOrderCreated, Ascending: true}, []string{"Another list", "Meeting notes", "Shopping list"}},
+			{"tags", &NoteFilter{TagGUIDs: []string{"Tag1", "Tag2"}}, []string{"Shopping list"}},
+			{"created after", &NoteFilter{CreatedAfter: 2}, []string{"Meeting notes", "Shopping list"}},
+			{"created before", &NoteFilter{CreatedBefore: 2}, []string{"Another list"}},
+			{"updated range", &NoteFilter{UpdatedAfter: 2, UpdatedBefore: 3}, []string{"Another list"}},
+			{"trash", &NoteFilter{Inactive: true}, []string{"Deleted list"}},
+			{"open reminders", &NoteFilter{Reminders: true}, []string{"Another list"}},
+			{"checkboxes", &NoteFilter{Todos: true}, []string{"Another list"}},
+		}
+		for _, test := range tests {
+			found, err := ns.FindNotes(test.filter, 0, 20)
+			assert.NoError(err, "Should not return an error")
+			assert.Equal(test.expected, titles(found), "Wrong notes returned for %s", test.name)
+		}
+	})
+	t.Run("paging", func(t *testing.T) {
+		found, err := ns.FindNotes(&NoteFilter{Order: NoteFilterOrderCreated}, 1, 1)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"Meeting notes"}, titles(found), "Wrong notes returned")
+		assert.Equal(1, found.StartIndex, "Wrong start index")
+		assert.Equal(3, found.TotalNotes, "Wrong total")
+		found, err = ns.FindNotes(new(NoteFilter), 5, 1)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(found.Notes, 0, "No notes should be returned")
+	})
+	t.Run("get content", func(t *testing.T) {
+		content, err := ns.GetNoteContent("1")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(notes[0].Body, content, "Wrong content")
+	})
+	t.Run("get notebook", func(t *testing.T) {
+		b, err := ns.GetNotebook("Notebook GUID")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(notebook, b, "Wrong notebook")
+		_, err = ns.GetNotebook("Missing")
+		assert.Equal(ErrNoNotebookFound, err, "Wrong error")
+	})
+	t.Run("queue changes", func(t *testing.T) {
+		queued := make([]*Change, 0)
+		saved := make([]*Note, 0)
+		store.queueChange = func(c *Change) error { queued = append(queued, c); return nil }
+		store.saveLocalNote = func(n *Note) error { saved = append(saved, n); return nil }
+		store.getLocalNote = func(guid string) (*Note, error) { n := *notes[0]; return &n, nil }
+
+		updated := &Note{GUID: "1", Title: "Shopping list", Body: "<en-note>Eggs</en-note>", USN: 5, Notebook: notebook}
+		assert.NoError(ns.UpdateNote(updated), "Should not return an error")
+		moved := &Note{GUID: "1", Title: "Shopping list", USN: 5, Notebook: &Notebook{GUID: "Other"}}
+		assert.NoError(ns.UpdateNote(moved), "Should not return an error")
+		assert.NoError(ns.DeleteNote("1"), "Should not return an error")
+		assert.NoError(ns.CreateNote(&Note{Title: "New"}), "Should not return an error")
+
+		assert.Len(queued, 4, "All changes should be queued")
+		assert.Equal(ChangeUpdate, queued[0].Type, "Wrong change type")
+		assert.Equal(updated, queued[0].Note, "Wrong note queued")
+		assert.Equal(ChangeMove, queued[1].Type, "Wrong change type")
+		assert.Equal(ChangeDelete, queued[2].Type, "Wrong change type")
+		assert.Equal(ChangeCreate, queued[3].Type, "Wrong change type")
+
+		assert.Len(saved, 3, "Local copy should be updated")
+		assert.Equal("<en-note>Eggs</en-note>", saved[0].Body, "Content should be updated")
+		assert.Equal(notes[0].Body, saved[1].Body, "Content should be kept when moved")
+		assert.True(saved[2].Deleted, "Note should be marked as deleted")
+	})
+	t.Run("get note with content", func(t *testing.T) {
+		store.getLocalTags = func() ([]*Tag, error) { return nil, nil }
+		n, err := GetNoteWithContent(store, ns, "Shopping list")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Milk", n.MD, "Wrong content")
+	})
+}
@@ -0,0 +1,645 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	lineBreak  = "  \n"
+	listIndent = "    "
+	// listSeparator separates two lists so they are not parsed as one list.
+	listSeparator = "<!-- -->"
+	// spacer is the empty line Evernote adds between the lines of a note.
+	spacer = "<div><br/></div>"
+)
+
+// blockElements are the elements rendered as markdown blocks.
+var blockElements = map[string]bool{
+	"address": true, "blockquote": true, "center": true, "dl": true, "div": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "ol": true, "p": true, "pre": true, "table": true, "ul": true, "xmp": true,
+}
+
+// emptyElements are the elements that can't have any content.
+var emptyElements = map[string]bool{
+	"area": true, "br": true, "col": true, "hr": true, "img": true,
+	enMediaElement: true, enTodoElement: true,
+}
+
+var (
+	// escaper escapes the characters with a special meaning in markdown text.
+	escaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, "~", `\~`)
+	// lineStartPattern matches text at the start of a line that would be parsed as a block.
+	lineStartPattern = regexp.MustCompile(`^(#|>|[-+] |[-=]+$|\d+\. )`)
+	whitespace       = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+// node is an element or a text node in the ENML document.
+type node struct {
+	// name is the element name. It's empty for text nodes.
+	name     string
+	attr     []xml.Attr
+	text     string
+	children []*node
+}
+
+// block is a markdown block.
+type block struct {
+	text string
+	// todo is set for a paragraph starting with an en-todo element.
+	todo bool
+	// list is set for lists.
+	list bool
+}
+
+// FromENML converts the ENML content to markdown. The content can either be a
+// full ENML document or just the content of the en-note element. en-todo
+// elements are converted to task list items, en-media elements to image or link
+// references using the en-media scheme and tables to GFM tables. Content that
+// can't be expressed in markdown is kept as HTML.
+func FromENML(content string) (string, error) {
+	root, err := parseENML(content)
+	if err != nil {
+		return "", err
+	}
+	return joinBlocks(renderNote(root.children), false), nil
+}
+
+// renderNote renders the nodes in the en-note element. Spacers are kept as
+// HTML so they are kept when the note is converted back to ENML.
+func renderNote(nodes []*node) []block {
+	var out []block
+	start := 0
+	for i, n := range nodes {
+		if n.isSpacer() {
+			out = append(out, renderBlocks(nodes[start:i])...)
+			out = append(out, block{text: spacer})
+			start = i + 1
+		}
+	}
+	return append(out, renderBlocks(nodes[start:])...)
+}
+
+// parseENML parses the ENML content into a tree of nodes.
+func parseENML(content string) (*node, error) {
+	d := xml.NewDecoder(strings.NewReader(content))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.AutoClose = xml.HTMLAutoClose
+	root := &node{name: enNoteElement}
+	stack := []*node{root}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == enNoteElement {
+				continue
+			}
+			n := &node{name: name, attr: t.Attr}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if strings.ToLower(t.Name.Local) != enNoteElement && len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &node{text: string(t)})
+		}
+	}
+}
+
+func (n *node) isBlock() bool {
+	return blockElements[n.name]
+}
+
+func (n *node) attribute(name string) string {
+	for _, a := range n.attr {
+		if strings.ToLower(a.Name.Local) == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// isSpacer returns true if the node is a div with only a line break.
+func (n *node) isSpacer() bool {
+	if n.name != "div" {
+		return false
+	}
+	breaks := 0
+	for _, c := range n.children {
+		switch {
+		case c.name == "br":
+			breaks++
+		case !c.isSpace():
+			return false
+		}
+	}
+	return breaks == 1
+}
+
+// isSpace returns true if the node is a text node with only whitespace.
+func (n *node) isSpace() bool {
+	return n.name == "" && strings.Trim(n.text, " \t\r\n") == ""
+}
+
+// textContent returns the text of the node and its children.
+func (n *node) textContent() string {
+	if n.name == "" {
+		return n.text
+	}
+	if n.name == "br" {
+		return "\n"
+	}
+	s := ""
+	for _, c := range n.children {
+		s += c.textContent()
+	}
+	return s
+}
+
+// joinBlocks joins the blocks. Consecutive task items are joined as a list. If
+// item is true, the blocks are the content of a list item and nested lists are
+// joined without a blank line.
+func joinBlocks(blocks []block, item bool) string {
+	buf := new(bytes.Buffer)
+	for i, b := range blocks {
+		if i > 0 {
+			if (b.todo && blocks[i-1].todo) || (item && b.list && !blocks[i-1].list) {
+				buf.WriteString("\n")
+			} else if b.list && (blocks[i-1].list || blocks[i-1].todo) || b.todo && blocks[i-1].list {
+				buf.WriteString("\n\n" + listSeparator + "\n\n")
+			} else {
+				buf.WriteString("\n\n")
+			}
+		}
+		if b.todo && !item {
+			buf.WriteString("- ")
+		}
+		buf.WriteString(b.text)
+	}
+	return buf.String()
+}
+
+// renderBlocks renders the nodes as blocks. Inline nodes between the block
+// elements are rendered as paragraphs.
+func renderBlocks(nodes []*node) []block {
+	var inline []*node
+	var out []block
+	flush := func() {
+		if b, ok := renderParagraph(inline); ok {
+			out = append(out, b)
+		}
+		inline = nil
+	}
+	for _, n := range nodes {
+		if !n.isBlock() {
+			inline = append(inline, n)
+			continue
+		}
+		flush()
+		out = append(out, renderBlock(n)...)
+	}
+	flush()
+	return out
+}
+
+// renderBlock renders the block element.
+func renderBlock(n *node) []block {
+	switch n.name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		b, ok := renderParagraph(n.children)
+		if !ok {
+			return nil
+		}
+		text := strings.Replace(b.text, lineBreak, " ", -1)
+		return []block{{text: strings.Repeat("#", int(n.name[1]-'0')) + " " + text}}
+	case "ul", "ol":
+		return renderList(n)
+	case "blockquote":
+		text := joinBlocks(renderBlocks(n.children), false)
+		if text == "" {
+			return nil
+		}
+		lines := strings.Split(text, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimRight("> "+l, " ")
+		}
+		return []block{{text: strings.Join(lines, "\n")}}
+	case "pre", "xmp":
+		code := strings.TrimSuffix(n.textContent(), "\n")
+		fence := "```"
+		if strings.Contains(code, fence) {
+			fence = "~~~"
+		}
+		return []block{{text: fence + "\n" + code + "\n" + fence}}
+	case "hr":
+		return []block{{text: "---"}}
+	case "table":
+		return renderTable(n)
+	case "dl":
+		return []block{{text: renderHTML(n)}}
+	}
+	return renderBlocks(n.children)
+}
+
+// renderParagraph renders the inline nodes as a paragraph. A paragraph starting with
+// an en-todo element is rendered as a task item. False is returned if the paragraph
+// is empty.
+func renderParagraph(nodes []*node) (block, bool) {
+	for len(nodes) > 0 && nodes[0].isSpace() {
+		nodes = nodes[1:]
+	}
+	prefix := ""
+	todo := len(nodes) > 0 && nodes[0].name == enTodoElement
+	if todo {
+		prefix = todoUnchecked
+		if nodes[0].attribute("checked") == "true" {
+			prefix = todoChecked
+		}
+		nodes = nodes[1:]
+	}
+	text := strings.Trim(renderInline(nodes), " \n")
+	if text == "" && !todo {
+		return block{}, false
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if i > 0 {
+			l = strings.TrimLeft(l, " ")
+		}
+		if i > 0 || !todo {
+			l = escapeLineStart(l)
+		}
+		lines[i] = l
+	}
+	return block{text: prefix + strings.Join(lines, "\n"), todo: todo}, true
+}
+
+// escapeLineStart escapes text at the start of the line that would be parsed as a block.
+func escapeLineStart(line string) string {
+	if lineStartPattern.MatchString(line) {
+		if i := strings.Index(line, ". "); i > 0 && line[0] >= '0' && line[0] <= '9' {
+			return line[:i] + `\` + line[i:]
+		}
+		return `\` + line
+	}
+	return line
+}
+
+// renderList renders the list. Lists nested directly in the list are added to the previous item.
+func renderList(n *node) []block {
+	var items []string
+	loose := false
+	index := 0
+	for _, c := range n.children {
+		if c.name == "ul" || c.name == "ol" {
+			nested := joinBlocks(renderList(c), true)
+			if len(items) == 0 {
+				items = append(items, "- "+indent(nested))
+				continue
+			}
+			items[len(items)-1] += "\n" + listIndent + indent(nested)
+			continue
+		}
+		if c.name != "li" {
+			continue
+		}
+		index++
+		marker := "- "
+		if n.name == "ol" {
+			marker = fmt.Sprintf("%d. ", index)
+		}
+		blocks := renderBlocks(c.children)
+		paragraphs := 0
+		for _, b := range blocks {
+			if !b.list {
+				paragraphs++
+			}
+		}
+		if paragraphs > 1 {
+			loose = true
+		}
+		items = append(items, marker+indent(joinBlocks(blocks, true)))
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	sep := "\n"
+	if loose {
+		sep = "\n\n"
+	}
+	return []block{{text: strings.Join(items, sep), list: true}}
+}
+
+// indent indents all but the first line so the lines belong to the list item.
+func indent(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = listIndent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTable renders the table as a GFM table. If the first row doesn't have
+// header cells, the GFM table gets an empty header row so no row is turned into
+// a header when the table is converted back to ENML. Tables with merged cells
+// or block content in the cells are kept as HTML.
+func renderTable(n *node) []block {
+	var rows []*node
+	for _, c := range n.children {
+		switch c.name {
+		case "tr":
+			rows = append(rows, c)
+		case "thead", "tbody", "tfoot":
+			for _, r := range c.children {
+				if r.name == "tr" {
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	var grid [][]string
+	var align []string
+	columns := 0
+	header := false
+	for i, r := range rows {
+		var cells []string
+		for _, c := range r.children {
+			if c.name != "td" && c.name != "th" {
+				continue
+			}
+			if i == 0 && c.name == "th" {
+				header = true
+			}
+			text, ok := renderCell(c)
+			if !ok {
+				return []block{{text: renderHTML(n)}}
+			}
+			cells = append(cells, text)
+			if i == 0 {
+				align = append(align, c.attribute("align"))
+			}
+		}
+		if len(cells) > columns {
+			columns = len(cells)
+		}
+		grid = append(grid, cells)
+	}
+	if !header {
+		grid = append([][]string{nil}, grid...)
+	}
+	buf := new(bytes.Buffer)
+	for i, cells := range grid {
+		buf.WriteString("|")
+		for j := 0; j < columns; j++ {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+		if i == 0 {
+			buf.WriteString("\n|")
+			for j := 0; j < columns; j++ {
+				a := ""
+				if j < len(align) {
+					a = strings.ToLower(align[j])
+				}
+				buf.WriteString(" " + alignmentRow(a) + " |")
+			}
+		}
+		if i < len(grid)-1 {
+			buf.WriteString("\n")
+		}
+	}
+	return []block{{text: buf.String()}}
+}
+
+func alignmentRow(align string) string {
+	switch align {
+	case "left":
+		return ":---"
+	case "right":
+		return "---:"
+	case "center":
+		return ":---:"
+	}
+	return "---"
+}
+
+// renderCell renders the table cell's content on a single line. False is
+// returned if the cell can't be expressed in a GFM table.
+func renderCell(c *node) (string, bool) {
+	if c.attribute("colspan") != "" || c.attribute("rowspan") != "" {
+		return "", false
+	}
+	var lines []string
+	var inline []*node
+	flush := func() {
+		if b, ok := renderParagraph(inline); ok {
+			lines = append(lines, b.text)
+		}
+		inline = nil
+	}
+	for _, n := range c.children {
+		if !n.isBlock() {
+			inline = append(inline, n)
+			continue
+		}
+		if n.name != "p" && n.name != "div" {
+			return "", false
+		}
+		for _, cn := range n.children {
+			if cn.isBlock() {
+				return "", false
+			}
+		}
+		flush()
+		inline = n.children
+		flush()
+	}
+	flush()
+	text := strings.Join(lines, "<br/>")
+	text = strings.Replace(text, lineBreak, "<br/>", -1)
+	return strings.Replace(text, "|", `\|`, -1), true
+}
+
+// renderInline renders the inline nodes.
+func renderInline(nodes []*node) string {
+	buf := new(bytes.Buffer)
+	for _, n := range nodes {
+		writeInline(buf, n)
+	}
+	return buf.String()
+}
+
+func writeInline(buf *bytes.Buffer, n *node) {
+	switch n.name {
+	case "":
+		buf.WriteString(escaper.Replace(whitespace.ReplaceAllString(n.text, " ")))
+	case "br":
+		buf.WriteString(lineBreak)
+	case "b", "strong":
+		writeEmphasis(buf, "**", n.children)
+	case "i", "em":
+		writeEmphasis(buf, "*", n.children)
+	case "s", "strike", "del":
+		writeEmphasis(buf, "~~", n.children)
+	case "code", "tt":
+		writeCode(buf, n.textContent())
+	case "a":
+		href := n.attribute("href")
+		if href == "" {
+			buf.WriteString(renderInline(n.children))
+			return
+		}
+		fmt.Fprintf(buf, "[%s](%s%s)", strings.TrimSpace(renderInline(n.children)), linkDestination(href), linkTitle(n.attribute("title")))
+	case "img":
+		fmt.Fprintf(buf, "![%s](%s%s)", escaper.Replace(n.attribute("alt")), linkDestination(n.attribute("src")), linkTitle(n.attribute("title")))
+	case enMediaElement:
+		writeMediaReference(buf, n)
+	case enTodoElement, enCryptElement:
+		buf.WriteString(renderHTML(n))
+	default:
+		if n.isBlock() {
+			// Block elements nested in inline elements are rendered as text.
+			buf.WriteString(" " + renderInline(n.children) + " ")
+			return
+		}
+		if emptyElements[n.name] {
+			buf.WriteString(renderHTML(n))
+			return
+		}
+		// Elements that can't be expressed in markdown are kept as HTML.
+		buf.WriteString(startTag(n) + renderInline(n.children) + "</" + n.name + ">")
+	}
+}
+
+// writeEmphasis writes the content surrounded by the marker. Leading and
+// trailing whitespace is moved outside the marker.
+func writeEmphasis(buf *bytes.Buffer, marker string, nodes []*node) {
+	text := renderInline(nodes)
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		buf.WriteString(text)
+		return
+	}
+	if strings.HasPrefix(text, " ") {
+		buf.WriteString(" ")
+	}
+	buf.WriteString(marker + trimmed + marker)
+	if strings.HasSuffix(text, " ") {
+		buf.WriteString(" ")
+	}
+}
+
+func writeCode(buf *bytes.Buffer, code string) {
+	code = whitespace.ReplaceAllString(code, " ")
+	if strings.Contains(code, "`") {
+		buf.WriteString("`` " + code + " ``")
+		return
+	}
+	buf.WriteString("`" + code + "`")
+}
+
+// writeMediaReference writes the en-media element as an image or link reference. Media
+// with attributes that can't be expressed in markdown is kept as HTML.
+func writeMediaReference(buf *bytes.Buffer, n *node) {
+	for _, a := range n.attr {
+		switch strings.ToLower(a.Name.Local) {
+		case "type", "hash", "alt":
+		default:
+			buf.WriteString(renderHTML(n))
+			return
+		}
+	}
+	mediaType, hash, alt := n.attribute("type"), n.attribute("hash"), n.attribute("alt")
+	link := MediaScheme + hash + linkTitle(mediaType)
+	if strings.HasPrefix(mediaType, "image/") {
+		fmt.Fprintf(buf, "![%s](%s)", escaper.Replace(alt), link)
+		return
+	}
+	if alt == "" {
+		alt = hash
+	}
+	fmt.Fprintf(buf, "[%s](%s)", escaper.Replace(alt), link)
+}
+
+func linkDestination(s string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(s)
+}
+
+func linkTitle(title string) string {
+	if title == "" {
+		return ""
+	}
+	return ` "` + strings.Replace(title, `"`, `\"`, -1) + `"`
+}
+
+// renderHTML returns the node as HTML.
+func renderHTML(n *node) string {
+	if n.name == "" {
+		buf := new(bytes.Buffer)
+		xml.EscapeText(buf, []byte(n.text))
+		return buf.String()
+	}
+	if emptyElements[n.name] && len(n.children) == 0 {
+		return strings.TrimSuffix(startTag(n), ">") + "/>"
+	}
+	s := startTag(n)
+	for _, c := range n.children {
+		s += renderHTML(c)
+	}
+	return s + "</" + n.name + ">"
+}
+
+func startTag(n *node) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("<" + n.name)
+	for _, a := range n.attr {
+		if a.Name.Space != "" {
+			continue
+		}
+		buf.WriteString(" " + a.Name.Local + `="`)
+		xml.EscapeText(buf, []byte(a.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
@@ -0,0 +1,116 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromENML(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		enml     string
+		expected string
+	}{
+		{"paragraphs", "<div>First</div><div>Second</div>", "First\n\nSecond"},
+		{"spacer", "<div>First</div><div><br/></div><div>Second</div>", "First\n\n<div><br/></div>\n\nSecond"},
+		{"full document", testXMLHeader + "<en-note><p>Text</p></en-note>", "Text"},
+		{"emphasis", "<p><b>bold </b><i>italic</i> <s>gone</s></p>", "**bold** *italic* ~~gone~~"},
+		{"escape text", "<p>snake_case *not em* [x] &lt;tag&gt;</p>", "snake\\_case \\*not em\\* \\[x\\] \\<tag>"},
+		{"escape line start", "<div># one</div><div>2. two</div><div>- three</div>", "\\# one\n\n2\\. two\n\n\\- three"},
+		{"line break", "<p>one<br/>two</p>", "one  \ntwo"},
+		{"header", "<h2>Title</h2>", "## Title"},
+		{"todo", `<div><en-todo checked="true"/>Done</div><div><en-todo/>Open</div>`, "- [x] Done\n- [ ] Open"},
+		{"todo in list", `<ul><li><en-todo checked="false"/>Item</li></ul>`, "- [ ] Item"},
+		{"todo in text", `<p>A <en-todo checked="true"/> B</p>`, `A <en-todo checked="true"/> B`},
+		{"nested list", "<ul><li>a<ul><li>b</li></ul></li><li>c</li></ul>", "- a\n    - b\n- c"},
+		{"ordered list", "<ol><li>a</li><li>b</li></ol>", "1. a\n2. b"},
+		{"adjacent lists", "<ul><li>a</li></ul><ol><li>b</li></ol>", "- a\n\n<!-- -->\n\n1. b"},
+		{"image media", `<en-media type="image/png" hash="abc"/>`, `![](en-media:abc "image/png")`},
+		{"file media", `<en-media type="application/pdf" hash="abc" alt="doc.pdf"/>`, `[doc.pdf](en-media:abc "application/pdf")`},
+		{"media with style", `<en-media type="image/png" hash="abc" width="10"/>`, `<en-media type="image/png" hash="abc" width="10"/>`},
+		{"link", `<a href="https://example.com" title="Example">site</a>`, `[site](https://example.com "Example")`},
+		{"code", "<p><code>x := 1</code></p><pre>line 1\nline 2</pre>", "`x := 1`\n\n```\nline 1\nline 2\n```"},
+		{"blockquote", "<blockquote><p>a</p><p>b</p></blockquote>", "> a\n>\n> b"},
+		{"table", `<table><tr><td>a|b</td><td align="right">c</td></tr><tr><td><div>d</div><div>e</div></td><td>f</td></tr></table>`,
+			"|  |  |\n| --- | ---: |\n| a\\|b | c |\n| d<br/>e | f |"},
+		{"table with header", `<table><tr><th>a</th></tr><tr><td>b</td></tr></table>`, "| a |\n| --- |\n| b |"},
+		{"table with merged cells", `<table><tr><td colspan="2">a</td></tr></table>`, `<table><tr><td colspan="2">a</td></tr></table>`},
+		{"inline html", `<p><span style="color:red">red</span> <u>under</u></p>`, `<span style="color:red">red</span> <u>under</u>`},
+		{"encrypted", `<en-crypt hint="pw">AbC=</en-crypt>`, `<en-crypt hint="pw">AbC=</en-crypt>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := FromENML(test.enml)
+			assert.NoError(err, "Should convert without an error")
+			assert.Equal(test.expected, actual, "Wrong markdown")
+		})
+	}
+}
+
+func TestENMLRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	notes := []string{
+		`<div><en-todo checked="true"/>Buy milk</div><div><en-todo checked="false"/>Eggs</div><div>Some <b>bold</b> text_with *stars*</div>`,
+		`<ul><li>a</li><li>b<ul><li>c</li></ul></li></ul><ol><li><p>x</p><p>y</p></li><li>z</li></ol>`,
+		`<table><tr><th>A</th><th align="right">B</th></tr><tr><td>c</td><td>d</td></tr></table>`,
+		`<p><en-media type="image/png" hash="0123456789abcdef0123456789abcdef"/> <en-media type="application/pdf" hash="aa"/></p>`,
+		`<h1>Title</h1><pre>a &lt; b</pre><blockquote><p>quote</p></blockquote><p><a href="http://example.com/a b">link</a> <en-crypt hint="h">abc=</en-crypt></p><hr/>`,
+	}
+	for _, note := range notes {
+		md, err := FromENML(note)
+		assert.NoError(err, "Should convert to markdown")
+		enml, err := ToENML(md)
+		assert.NoError(err, "Should convert to ENML")
+		actual, err := FromENML(enml)
+		assert.NoError(err, "Should convert back to markdown")
+		assert.Equal(md, actual, "Markdown should not change after a save cycle")
+	}
+}
+
+func TestENMLRoundTripStructure(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		enml     string
+		expected string
+	}{
+		{"todo", `<div><en-todo checked="true"/>Buy milk</div><div><en-todo checked="false"/>Eggs</div>`,
+			"<div><en-todo checked=\"true\"/>Buy milk</div>\n<div><en-todo checked=\"false\"/>Eggs</div>\n"},
+		{"spacer", `<div>First</div><div><br/></div><div><en-todo checked="false"/>Open</div>`,
+			"<p>First</p>\n\n<div><br/></div>\n\n<div><en-todo checked=\"false\"/>Open</div>\n"},
+		{"table without header", `<table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td>d</td></tr></table>`,
+			"<table>\n<tr>\n<td>a</td>\n<td>b</td>\n</tr>\n\n<tr>\n<td>c</td>\n<td>d</td>\n</tr>\n</table>\n"},
+		{"table with header", `<table><tr><th>A</th></tr><tr><td>c</td></tr></table>`,
+			"<table>\n<tr>\n<th>A</th>\n</tr>\n<tr>\n<td>c</td>\n</tr>\n</table>\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			md, err := FromENML(test.enml)
+			assert.NoError(err, "Should convert to markdown")
+			actual, err := ToENML(md)
+			assert.NoError(err, "Should convert to ENML")
+			assert.Equal(test.expected, actual, "Structure should be kept after a save cycle")
+		})
+	}
+}
+
+const testXMLHeader = `<?xml version="1.0" encoding="UTF-8"?><!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">`
@@ -0,0 +1,212 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/russross/blackfriday"
+)
+
+const (
+	// MediaScheme is the link scheme used to reference the note's resources.
+	// The link is followed by the resource hash and the MIME type is used as the title.
+	MediaScheme = "en-media:"
+	// defaultMediaType is used if the media reference doesn't include the MIME type.
+	defaultMediaType = "application/octet-stream"
+	todoUnchecked    = "[ ] "
+	todoChecked      = "[x] "
+)
+
+// tagPattern matches HTML tags.
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// enmlExtensions are the markdown extensions used. Header IDs and footnotes
+// are not enabled since they produce attributes not allowed in ENML.
+const enmlExtensions = blackfriday.EXTENSION_NO_INTRA_EMPHASIS |
+	blackfriday.EXTENSION_TABLES |
+	blackfriday.EXTENSION_FENCED_CODE |
+	blackfriday.EXTENSION_AUTOLINK |
+	blackfriday.EXTENSION_STRIKETHROUGH |
+	blackfriday.EXTENSION_SPACE_HEADERS |
+	blackfriday.EXTENSION_BACKSLASH_LINE_BREAK |
+	blackfriday.EXTENSION_DEFINITION_LISTS
+
+// ToENML converts the markdown body to the content of the en-note element.
+// Task list items are converted to en-todo elements and links using the
+// en-media scheme to en-media elements. The result is validated against
+// the ENML rules.
+func ToENML(mdBody string) (string, error) {
+	r := &enmlRenderer{Renderer: blackfriday.HtmlRenderer(blackfriday.HTML_USE_XHTML, "", "")}
+	content := string(blackfriday.Markdown([]byte(mdBody), r, enmlExtensions))
+	content = strings.Replace(content, "\n"+listSeparator+"\n", "", -1)
+	if err := Validate(content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// enmlRenderer renders the markdown as ENML. It's based on the HTML renderer
+// but doesn't produce attributes that are not allowed in ENML.
+type enmlRenderer struct {
+	blackfriday.Renderer
+	// depth is the nesting level of the list being rendered.
+	depth int
+}
+
+// Header renders the header without an id attribute.
+func (r *enmlRenderer) Header(out *bytes.Buffer, text func() bool, level int, id string) {
+	marker := out.Len()
+	if marker > 0 {
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(out, "<h%d>", level)
+	if !text() {
+		out.Truncate(marker)
+		return
+	}
+	fmt.Fprintf(out, "</h%d>\n", level)
+}
+
+// BlockCode renders the code block without the language class.
+func (r *enmlRenderer) BlockCode(out *bytes.Buffer, text []byte, lang string) {
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+	out.WriteString("<pre><code>")
+	out.WriteString(html.EscapeString(string(text)))
+	out.WriteString("</code></pre>\n")
+}
+
+// List renders top level lists with only task items as a div for each item,
+// which is how Evernote saves checkboxes.
+func (r *enmlRenderer) List(out *bytes.Buffer, text func() bool, flags int) {
+	marker := out.Len()
+	r.depth++
+	r.Renderer.List(out, text, flags)
+	r.depth--
+	if r.depth > 0 || flags&blackfriday.LIST_TYPE_ORDERED != 0 {
+		return
+	}
+	if lines, ok := todoLines(out.String()[marker:]); ok {
+		out.Truncate(marker)
+		if marker > 0 {
+			out.WriteByte('\n')
+		}
+		out.WriteString(lines)
+	}
+}
+
+// Table renders the table without the thead and tbody elements. An empty header
+// row is left out so tables without a header are kept that way.
+func (r *enmlRenderer) Table(out *bytes.Buffer, header []byte, body []byte, columnData []int) {
+	if out.Len() > 0 {
+		out.WriteByte('\n')
+	}
+	out.WriteString("<table>\n")
+	if strings.TrimSpace(tagPattern.ReplaceAllString(string(header), "")) != "" {
+		out.Write(header)
+	}
+	out.Write(body)
+	out.WriteString("</table>\n")
+}
+
+// ListItem renders task list items with an en-todo element.
+func (r *enmlRenderer) ListItem(out *bytes.Buffer, text []byte, flags int) {
+	r.Renderer.ListItem(out, todoItem(text), flags)
+}
+
+// Image renders images using the en-media scheme as en-media elements.
+func (r *enmlRenderer) Image(out *bytes.Buffer, link []byte, title []byte, alt []byte) {
+	if !bytes.HasPrefix(link, []byte(MediaScheme)) {
+		r.Renderer.Image(out, link, title, alt)
+		return
+	}
+	writeMedia(out, link, title, alt)
+}
+
+// Link renders links using the en-media scheme as en-media elements.
+func (r *enmlRenderer) Link(out *bytes.Buffer, link []byte, title []byte, content []byte) {
+	if !bytes.HasPrefix(link, []byte(MediaScheme)) {
+		r.Renderer.Link(out, link, title, content)
+		return
+	}
+	// The content has already been rendered, so only plain text is kept. Media
+	// without an alt text uses the hash as the link text.
+	if bytes.ContainsRune(content, '<') || bytes.Equal(link[len(MediaScheme):], content) {
+		content = nil
+	}
+	writeMedia(out, link, title, content)
+}
+
+// todoLines returns the rendered list as en-todo lines. False is returned if
+// the list has items that aren't tasks or have more than a single paragraph.
+func todoLines(list string) (string, bool) {
+	list = strings.TrimPrefix(list, "\n")
+	if !strings.HasPrefix(list, "<ul>\n") || !strings.HasSuffix(list, "</ul>\n") || strings.Count(list, "<ul>") != 1 {
+		return "", false
+	}
+	items := strings.Split(strings.TrimSuffix(strings.TrimPrefix(list, "<ul>\n"), "</ul>\n"), "</li>\n")
+	buf := new(bytes.Buffer)
+	for _, item := range items[:len(items)-1] {
+		if !strings.HasPrefix(item, "<li><"+enTodoElement) || strings.Contains(item, "<p>") || strings.Contains(item, "<ol>") {
+			return "", false
+		}
+		buf.WriteString("<div>" + strings.TrimPrefix(item, "<li>") + "</div>\n")
+	}
+	return buf.String(), items[len(items)-1] == ""
+}
+
+// todoItem replaces the task marker at the start of the list item with an en-todo element.
+func todoItem(text []byte) []byte {
+	prefix := ""
+	s := string(text)
+	// Items in loose lists are wrapped in a paragraph.
+	if strings.HasPrefix(s, "<p>") {
+		prefix, s = "<p>", s[3:]
+	}
+	checked := ""
+	switch {
+	case strings.HasPrefix(s, todoUnchecked):
+		checked = "false"
+	case strings.HasPrefix(s, todoChecked), strings.HasPrefix(s, "[X] "):
+		checked = "true"
+	default:
+		return text
+	}
+	return []byte(prefix + `<` + enTodoElement + ` checked="` + checked + `"/>` + s[len(todoChecked):])
+}
+
+// writeMedia writes the en-media element for the media link. The alt text is expected
+// to already be escaped.
+func writeMedia(out *bytes.Buffer, link, title, alt []byte) {
+	mediaType := string(title)
+	if mediaType == "" {
+		mediaType = defaultMediaType
+	}
+	hash := strings.TrimPrefix(string(link), MediaScheme)
+	fmt.Fprintf(out, `<%s type="%s" hash="%s"`, enMediaElement, html.EscapeString(mediaType), html.EscapeString(hash))
+	if len(alt) > 0 {
+		fmt.Fprintf(out, ` alt="%s"`, alt)
+	}
+	out.WriteString("/>")
+}
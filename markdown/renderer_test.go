@@ -0,0 +1,63 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToENML(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{"header without id", "# Title", "<h1>Title</h1>\n"},
+		{"todo", "- [ ] Open\n- [x] Done", "<div><en-todo checked=\"false\"/>Open</div>\n<div><en-todo checked=\"true\"/>Done</div>\n"},
+		{"todo in list", "- [ ] Open\n- Item", "<ul>\n<li><en-todo checked=\"false\"/>Open</li>\n<li>Item</li>\n</ul>\n"},
+		{"nested todo", "- a\n    - [ ] b", "<ul>\n<li>a\n\n<ul>\n<li><en-todo checked=\"false\"/>b</li>\n</ul></li>\n</ul>\n"},
+		{"image media", `![photo](en-media:abc "image/png")`, "<p><en-media type=\"image/png\" hash=\"abc\" alt=\"photo\"/></p>\n"},
+		{"file media", `[abc](en-media:abc "application/pdf")`, "<p><en-media type=\"application/pdf\" hash=\"abc\"/></p>\n"},
+		{"media without type", `[file](en-media:abc)`, "<p><en-media type=\"application/octet-stream\" hash=\"abc\" alt=\"file\"/></p>\n"},
+		{"code without class", "```go\nx < y\n```", "<pre><code>x &lt; y\n</code></pre>\n"},
+		{"table", "| a | b |\n| --- | ---: |\n| c | d |", "<table>\n<tr>\n<th>a</th>\n<th align=\"right\">b</th>\n</tr>\n<tr>\n<td>c</td>\n<td align=\"right\">d</td>\n</tr>\n</table>\n"},
+		{"table without header", "|  |\n| --- |\n| c |", "<table>\n<tr>\n<td>c</td>\n</tr>\n</table>\n"},
+		{"list separator", "- a\n\n<!-- -->\n\n1. b", "<ul>\n<li>a</li>\n</ul>\n\n<ol>\n<li>b</li>\n</ol>\n"},
+		{"no smart quotes", `"quoted"`, "<p>&quot;quoted&quot;</p>\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ToENML(test.md)
+			assert.NoError(err, "Should convert without an error")
+			assert.Equal(test.expected, actual, "Wrong ENML")
+		})
+	}
+
+	t.Run("invalid html", func(t *testing.T) {
+		_, err := ToENML("Text\n\n<p>\n<script>alert(1)</script>\n</p>")
+		if assert.Error(err, "Should fail validation") {
+			verr, ok := err.(*ValidationError)
+			if assert.True(ok, "Should be a validation error") {
+				assert.Equal(4, verr.Line, "Wrong line")
+			}
+		}
+	})
+}
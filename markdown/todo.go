@@ -0,0 +1,134 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNoTodoFound is returned if the content doesn't have a checkbox with the index.
+var ErrNoTodoFound = errors.New("no checkbox found")
+
+// todoEndElements end the text of a checkbox in addition to the block elements.
+var todoEndElements = map[string]bool{
+	"br": true, "li": true, "td": true, "th": true, "tr": true, enTodoElement: true,
+}
+
+// Todo is an en-todo element in the ENML content.
+type Todo struct {
+	// Text is the text after the checkbox up to the end of the line.
+	Text string
+	// Checked is true if the checkbox is checked.
+	Checked bool
+	// Offset is the byte offset of the element in the content.
+	Offset int
+	// Length is the length of the element in bytes.
+	Length int
+}
+
+// Todos returns the en-todo elements in the ENML content in the order they
+// appear. The content can either be a full ENML document or just the content
+// of the en-note element.
+func Todos(content string) ([]*Todo, error) {
+	d := xml.NewDecoder(strings.NewReader(content))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.AutoClose = xml.HTMLAutoClose
+	var todos []*Todo
+	// current is the checkbox the text is added to.
+	var current *Todo
+	text := new(strings.Builder)
+	// opened is set if the previous token was the start of a checkbox.
+	opened := false
+	done := func() {
+		if current != nil {
+			current.Text = strings.Join(strings.Fields(text.String()), " ")
+		}
+		current = nil
+		text.Reset()
+	}
+	for {
+		offset := int(d.InputOffset())
+		tok, err := d.Token()
+		if err == io.EOF {
+			done()
+			return todos, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		afterTodo := opened
+		opened = false
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if blockElements[name] || todoEndElements[name] {
+				done()
+			}
+			if name != enTodoElement {
+				continue
+			}
+			current = &Todo{Offset: offset, Length: int(d.InputOffset()) - offset}
+			for _, a := range t.Attr {
+				if strings.ToLower(a.Name.Local) == "checked" {
+					current.Checked = strings.ToLower(a.Value) == "true"
+				}
+			}
+			todos = append(todos, current)
+			opened = true
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if name == enTodoElement {
+				// Include the end tag if the element isn't self-closing.
+				if afterTodo {
+					current.Length = int(d.InputOffset()) - current.Offset
+				}
+				continue
+			}
+			if blockElements[name] || todoEndElements[name] || name == enNoteElement {
+				done()
+			}
+		case xml.CharData:
+			if current != nil {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+// SetTodo checks or unchecks the checkbox with the index in the ENML content.
+// The index starts at 0. Only the en-todo element is replaced, the rest of the
+// content is kept as is.
+func SetTodo(content string, index int, checked bool) (string, error) {
+	todos, err := Todos(content)
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || index >= len(todos) {
+		return "", ErrNoTodoFound
+	}
+	t := todos[index]
+	value := "false"
+	if checked {
+		value = "true"
+	}
+	return content[:t.Offset] + "<" + enTodoElement + " checked=\"" + value + "\"/>" + content[t.Offset+t.Length:], nil
+}
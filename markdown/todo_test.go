@@ -0,0 +1,65 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodos(t *testing.T) {
+	assert := assert.New(t)
+	content := testXMLHeader + "<en-note><div><en-todo checked=\"true\"/>Milk &amp; <b>eggs</b></div>" +
+		"<div><en-todo/>Bread<br/>Not part of the item</div>" +
+		"<ul><li><en-todo checked=\"false\"></en-todo> Butter </li><li>No checkbox</li></ul>" +
+		"<p><en-todo/>First<en-todo/>Second</p></en-note>"
+
+	t.Run("list", func(t *testing.T) {
+		todos, err := Todos(content)
+		assert.NoError(err, "Should not return an error")
+		texts := make([]string, len(todos))
+		checked := make([]bool, len(todos))
+		for i, todo := range todos {
+			texts[i] = todo.Text
+			checked[i] = todo.Checked
+		}
+		assert.Equal([]string{"Milk & eggs", "Bread", "Butter", "First", "Second"}, texts, "Wrong texts")
+		assert.Equal([]bool{true, false, false, false, false}, checked, "Wrong states")
+		assert.Equal("<en-todo checked=\"false\"></en-todo>", content[todos[2].Offset:todos[2].Offset+todos[2].Length], "Wrong element bounds")
+	})
+	t.Run("check", func(t *testing.T) {
+		out, err := SetTodo(content, 2, true)
+		assert.NoError(err, "Should not return an error")
+		expected := strings.Replace(content, "<en-todo checked=\"false\"></en-todo>", "<en-todo checked=\"true\"/>", 1)
+		assert.Equal(expected, out, "Only the checkbox should be changed")
+	})
+	t.Run("uncheck", func(t *testing.T) {
+		out, err := SetTodo(content, 0, false)
+		assert.NoError(err, "Should not return an error")
+		expected := strings.Replace(content, "<en-todo checked=\"true\"/>", "<en-todo checked=\"false\"/>", 1)
+		assert.Equal(expected, out, "Only the checkbox should be changed")
+	})
+	t.Run("no checkbox", func(t *testing.T) {
+		_, err := SetTodo(content, 5, true)
+		assert.Equal(ErrNoTodoFound, err, "Wrong error returned")
+		_, err = SetTodo("<p>Text</p>", 0, true)
+		assert.Equal(ErrNoTodoFound, err, "Wrong error returned")
+	})
+}
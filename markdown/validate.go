@@ -0,0 +1,212 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	enNoteElement  = "en-note"
+	enMediaElement = "en-media"
+	enTodoElement  = "en-todo"
+	enCryptElement = "en-crypt"
+)
+
+// allowedElements are the elements permitted by the ENML DTD.
+var allowedElements = map[string]bool{
+	"a": true, "abbr": true, "acronym": true, "address": true, "area": true, "b": true,
+	"bdo": true, "big": true, "blockquote": true, "br": true, "caption": true, "center": true,
+	"cite": true, "code": true, "col": true, "colgroup": true, "dd": true, "del": true,
+	"dfn": true, "div": true, "dl": true, "dt": true, "em": true, "font": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "i": true, "img": true, "ins": true, "kbd": true, "li": true,
+	"map": true, "ol": true, "p": true, "pre": true, "q": true, "s": true,
+	"samp": true, "small": true, "span": true, "strike": true, "strong": true, "sub": true,
+	"sup": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "title": true, "tr": true, "tt": true, "u": true, "ul": true,
+	"var": true, "xmp": true,
+	enNoteElement: true, enMediaElement: true, enTodoElement: true, enCryptElement: true,
+}
+
+// prohibitedAttributes are the attributes not permitted by the ENML DTD.
+// In addition, all attributes starting with "on" are prohibited.
+var prohibitedAttributes = map[string]bool{
+	"id": true, "class": true, "accesskey": true, "data": true, "dynsrc": true, "tabindex": true,
+}
+
+// ValidationError is returned if the content breaks the ENML rules.
+type ValidationError struct {
+	// Line is the line in the content where the error was found, starting at 1.
+	Line int
+	// Column is the column in the line where the error was found, starting at 1.
+	Column int
+	// Reason describes what is wrong.
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Reason)
+}
+
+// IsAllowedElement returns true if the element is permitted in ENML.
+func IsAllowedElement(name string) bool {
+	return allowedElements[strings.ToLower(name)]
+}
+
+// IsAllowedAttribute returns true if the attribute is permitted in ENML.
+func IsAllowedAttribute(name string) bool {
+	name = strings.ToLower(name)
+	return !prohibitedAttributes[name] && !strings.HasPrefix(name, "on")
+}
+
+// Validate checks that the content is well-formed and only uses the elements and
+// attributes permitted by the ENML DTD. The content can either be a full ENML document
+// or just the content of the en-note element. If the content is invalid, a
+// *ValidationError is returned.
+func Validate(content string) error {
+	fragment := !isENMLDocument(content)
+	r := io.Reader(strings.NewReader(content))
+	if fragment {
+		// The fragment is wrapped so it has a single root element.
+		r = io.MultiReader(strings.NewReader("<"+enNoteElement+">"), r, strings.NewReader("</"+enNoteElement+">"))
+	}
+	v := &validator{content: content, d: xml.NewDecoder(r)}
+	if fragment {
+		v.offset = len(enNoteElement) + 2
+	}
+	v.d.Strict = true
+	v.d.Entity = xml.HTMLEntity
+	return v.validate()
+}
+
+// isENMLDocument returns true if the content has an XML prolog or an en-note root element.
+func isENMLDocument(content string) bool {
+	s := strings.TrimSpace(content)
+	return strings.HasPrefix(s, "<?xml") || strings.HasPrefix(s, "<!DOCTYPE") || strings.HasPrefix(s, "<"+enNoteElement)
+}
+
+type validator struct {
+	content string
+	d       *xml.Decoder
+	// offset is the number of bytes added in front of the content.
+	offset int
+	// start is the input offset of the current token.
+	start int64
+	// space is the namespace of the en-note element.
+	space string
+}
+
+func (v *validator) validate() error {
+	depth := 0
+	root := false
+	for {
+		v.start = v.d.InputOffset()
+		tok, err := v.d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			reason := err.Error()
+			if serr, ok := err.(*xml.SyntaxError); ok {
+				reason = serr.Msg
+			}
+			return v.errorAt(v.d.InputOffset(), reason)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if t.Name.Local != enNoteElement {
+					return v.error("the root element has to be " + enNoteElement)
+				}
+				if root {
+					return v.error("only one " + enNoteElement + " element is allowed")
+				}
+				root = true
+				v.space = t.Name.Space
+			} else if err := v.checkElement(t); err != nil {
+				return err
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && strings.TrimSpace(string(t)) != "" {
+				return v.error("text outside of the " + enNoteElement + " element")
+			}
+		}
+	}
+	if !root {
+		return v.errorAt(v.d.InputOffset(), "missing "+enNoteElement+" element")
+	}
+	return nil
+}
+
+// checkElement checks the element and its attributes against the ENML rules.
+func (v *validator) checkElement(t xml.StartElement) error {
+	name := t.Name.Local
+	if t.Name.Space != v.space || !allowedElements[name] {
+		return v.error("element " + name + " is not allowed")
+	}
+	if name == enNoteElement {
+		return v.error("only one " + enNoteElement + " element is allowed")
+	}
+	attrs := make(map[string]string, len(t.Attr))
+	for _, a := range t.Attr {
+		if a.Name.Space != "" || !IsAllowedAttribute(a.Name.Local) {
+			return v.error("attribute " + a.Name.Local + " is not allowed on " + name)
+		}
+		attrs[a.Name.Local] = a.Value
+	}
+	switch name {
+	case enMediaElement:
+		if attrs["type"] == "" || attrs["hash"] == "" {
+			return v.error(enMediaElement + " requires the type and hash attributes")
+		}
+	case enTodoElement:
+		if c, ok := attrs["checked"]; ok && c != "true" && c != "false" {
+			return v.error("the checked attribute of " + enTodoElement + " has to be true or false")
+		}
+	case "a":
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(attrs["href"])), "javascript:") {
+			return v.error("javascript links are not allowed")
+		}
+	}
+	return nil
+}
+
+func (v *validator) error(reason string) error {
+	return v.errorAt(v.start, reason)
+}
+
+// errorAt returns a validation error for the input offset.
+func (v *validator) errorAt(offset int64, reason string) error {
+	pos := int(offset) - v.offset
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(v.content) {
+		pos = len(v.content)
+	}
+	line := strings.Count(v.content[:pos], "\n") + 1
+	column := pos - strings.LastIndex(v.content[:pos], "\n")
+	return &ValidationError{Line: line, Column: column, Reason: reason}
+}
@@ -0,0 +1,69 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("valid", func(t *testing.T) {
+		for _, content := range []string{
+			"<p>Text &nbsp; &amp; more</p>",
+			testXMLHeader + "<en-note><div><en-todo checked=\"true\"/>Done</div></en-note>",
+			"<en-note xmlns=\"http://xml.evernote.com/pub/enml2.dtd\"><p>Text</p></en-note>",
+			"<p><en-media type=\"image/png\" hash=\"abc\" style=\"width:10px\"/></p>",
+		} {
+			assert.NoError(Validate(content), "Should be valid: %s", content)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+	}{
+		{"prohibited element", "<p>Text</p>\n<p><script>x</script></p>", 2, 4},
+		{"prohibited attribute", "<p>\n  <span id=\"a\">x</span></p>", 2, 3},
+		{"event attribute", "<p onclick=\"x\">Text</p>", 1, 1},
+		{"media without hash", "<en-media type=\"image/png\"/>", 1, 1},
+		{"invalid todo", "<en-todo checked=\"yes\"/>", 1, 1},
+		{"javascript link", "<a href=\"javascript:alert(1)\">x</a>", 1, 1},
+		{"not well-formed", "<p>Text\n<b>bold</p>", 2, 12},
+		{"wrong root", testXMLHeader + "<html><p>Text</p></html>", 1, 104},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(test.content)
+			if !assert.Error(err, "Should return an error") {
+				return
+			}
+			verr, ok := err.(*ValidationError)
+			if assert.True(ok, "Should be a validation error") {
+				assert.Equal(test.line, verr.Line, "Wrong line")
+				assert.Equal(test.column, verr.Column, "Wrong column")
+				assert.NotEmpty(verr.Reason, "Should have a reason")
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import "strings"
+
+const (
+	conflictStartMarker = "<<<<<<< local"
+	conflictSepMarker   = "======="
+	conflictEndMarker   = ">>>>>>> server"
+)
+
+// mergeText does a line based three-way merge of the local and server versions
+// of the text. Base is the version both were changed from. Lines changed in only
+// one of the versions are merged. If both versions changed the same lines, the
+// lines are wrapped in conflict markers and conflict is set to true.
+func mergeText(base, local, server string) (merged string, conflict bool) {
+	b, l, s := splitLines(base), splitLines(local), splitLines(server)
+	ml, ms := matchLines(b, l), matchLines(b, s)
+	out := make([]string, 0, len(l)+len(s))
+	i, j, k := 0, 0, 0
+	for {
+		// Find the next base line that is unchanged in both versions.
+		next := i
+		for next < len(b) && (ml[next] == -1 || ms[next] == -1) {
+			next++
+		}
+		if next == len(b) {
+			lines, c := mergeChunk(b[i:], l[j:], s[k:])
+			out = append(out, lines...)
+			conflict = conflict || c
+			break
+		}
+		lines, c := mergeChunk(b[i:next], l[j:ml[next]], s[k:ms[next]])
+		out = append(out, lines...)
+		conflict = conflict || c
+		out = append(out, b[next])
+		i, j, k = next+1, ml[next]+1, ms[next]+1
+	}
+	return strings.Join(out, "\n"), conflict
+}
+
+// hasConflictMarkers returns true if the text has the conflict markers
+// added by mergeText.
+func hasConflictMarkers(text string) bool {
+	for _, l := range splitLines(text) {
+		l = strings.TrimRight(l, "\r")
+		if l == conflictStartMarker || l == conflictEndMarker {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeChunk merges a chunk of lines that differs between the versions.
+func mergeChunk(base, local, server []string) ([]string, bool) {
+	switch {
+	case equalLines(local, server), equalLines(base, server):
+		return local, false
+	case equalLines(base, local):
+		return server, false
+	}
+	lines := make([]string, 0, len(local)+len(server)+3)
+	lines = append(lines, conflictStartMarker)
+	lines = append(lines, local...)
+	lines = append(lines, conflictSepMarker)
+	lines = append(lines, server...)
+	return append(lines, conflictEndMarker), true
+}
+
+// matchLines returns for each line in a, the index of the matching line in b
+// based on the longest common subsequence. Lines without a match are set to -1.
+func matchLines(a, b []string) []int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for i < len(a) {
+		switch {
+		case j < len(b) && a[i] == b[j]:
+			match[i] = j
+			i++
+			j++
+		case j < len(b) && lcs[i][j+1] > lcs[i+1][j]:
+			j++
+		default:
+			match[i] = -1
+			i++
+		}
+	}
+	return match
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeText(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		name     string
+		base     string
+		local    string
+		server   string
+		expected string
+		conflict bool
+	}{
+		{"no changes", "a\nb", "a\nb", "a\nb", "a\nb", false},
+		{"local change", "a\nb\nc", "a\nB\nc", "a\nb\nc", "a\nB\nc", false},
+		{"server change", "a\nb\nc", "a\nb\nc", "a\nb\nC", "a\nb\nC", false},
+		{"changes to different lines", "a\nb\nc", "A\nb\nc", "a\nb\nC", "A\nb\nC", false},
+		{"added lines", "a\nb", "a\nlocal\nb", "a\nb\nserver", "a\nlocal\nb\nserver", false},
+		{"removed line", "a\nb\nc", "a\nc", "a\nb\nc\nd", "a\nc\nd", false},
+		{"same change", "a\nb", "a\nB", "a\nB", "a\nB", false},
+		{"empty base", "", "local", "", "local", false},
+		{"conflict", "a\nb\nc", "a\nlocal\nc", "a\nserver\nc",
+			"a\n" + conflictStartMarker + "\nlocal\n" + conflictSepMarker + "\nserver\n" + conflictEndMarker + "\nc", true},
+		{"conflict at end", "a", "a\nlocal", "a\nserver",
+			"a\n" + conflictStartMarker + "\nlocal\n" + conflictSepMarker + "\nserver\n" + conflictEndMarker, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflict := mergeText(test.base, test.local, test.server)
+			assert.Equal(test.expected, merged, "Wrong merge result")
+			assert.Equal(test.conflict, conflict, "Wrong conflict result")
+		})
+	}
+}
@@ -0,0 +1,543 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+const (
+	// mirrorStateFile is the file in the mirror folder that keeps track of
+	// the mirrored notes.
+	mirrorStateFile = ".clinote-mirror.json"
+	// mirrorExt is the file extension of the mirrored notes.
+	mirrorExt = ".md"
+	// mirrorConflictExt is added to the file name of a note that was changed
+	// both locally and on the server. The file has the server's version.
+	mirrorConflictExt = ".conflict"
+	mirrorFileMode    = 0600
+	mirrorPageSize    = 100
+	// mirrorTimeFormat is the time format of the updated field.
+	mirrorTimeFormat = "2006-01-02 15:04:05"
+	headGUIDField    = "guid:"
+	headUpdatedField = "updated:"
+	untitledNote     = "Untitled note"
+)
+
+var (
+	// ErrMirrorNotebook is returned if the folder already mirrors another notebook.
+	ErrMirrorNotebook = errors.New("the folder mirrors another notebook")
+)
+
+// mirrorFileReplacer replaces the characters that can't be used in file names.
+var mirrorFileReplacer = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "-", "<", "-", ">", "-", "|", "-")
+
+// MirrorAction is the change made to a note or its file when a notebook is mirrored.
+type MirrorAction int
+
+const (
+	// MirrorPulled means the file was written with the note from the server.
+	MirrorPulled MirrorAction = iota
+	// MirrorPushed means the note was updated with the changes in the file.
+	MirrorPushed
+	// MirrorCreated means a new note was created from the file.
+	MirrorCreated
+	// MirrorRemoved means the file was removed since the note was removed
+	// from the notebook on the server.
+	MirrorRemoved
+	// MirrorDeleted means the note was moved to the trash since the file
+	// was removed.
+	MirrorDeleted
+	// MirrorConflict means the note was changed both locally and on the
+	// server. The server's version is saved next to the file.
+	MirrorConflict
+	// MirrorKept means the file was changed but its note was moved to
+	// another notebook or to the trash. The file is kept and the note is
+	// not changed.
+	MirrorKept
+)
+
+var mirrorActionNames = []string{"Pulled", "Pushed", "Created", "Removed", "Deleted", "Conflict", "Kept"}
+
+func (a MirrorAction) String() string {
+	return mirrorActionNames[a]
+}
+
+// MirrorChange is a change made when the notebook is mirrored.
+type MirrorChange struct {
+	// Action is the change made.
+	Action MirrorAction
+	// File is the name of the note's file in the folder.
+	File string
+	// Title is the note's title.
+	Title string
+}
+
+// mirrorState is the state of the mirror folder after the last mirror.
+type mirrorState struct {
+	// NotebookGUID is the GUID of the mirrored notebook.
+	NotebookGUID string `json:"notebook"`
+	// Notes are the mirrored notes by their GUID.
+	Notes map[string]*mirrorEntry `json:"notes"`
+}
+
+// mirrorEntry is the state of a mirrored note. The hash is the note's hash
+// when the file and the note last were the same and the USN is the note's
+// update sequence number at the time.
+type mirrorEntry struct {
+	File     string `json:"file"`
+	Hash     string `json:"hash"`
+	USN      int32  `json:"usn"`
+	Conflict bool   `json:"conflict,omitempty"`
+}
+
+// mirrorFile is a note file in the mirror folder.
+type mirrorFile struct {
+	name string
+	note *Note
+	hash string
+}
+
+// mirror holds the state while a notebook is mirrored.
+type mirror struct {
+	db       Storager
+	ns       NotestoreClient
+	notebook *Notebook
+	dir      string
+	state    *mirrorState
+	tags     map[string]string
+	files    map[string]bool
+	changes  []*MirrorChange
+}
+
+// MirrorNotebook mirrors the notebook to Markdown files in the folder. Each
+// note is saved as a file with a header that has the note's title, GUID,
+// notebook, tags and update time. Files changed since the last mirror are
+// pushed to the server, new files are created as notes and removed files
+// are moved to the trash. Notes changed on the server are written to the
+// files and notes removed from the notebook have their files removed.
+//
+// If a note was changed both locally and on the server, the file is kept
+// and the server's version is written next to it with the ".conflict"
+// extension. The local version is pushed once the conflict file has been
+// removed.
+func MirrorNotebook(db Storager, ns NotestoreClient, notebook, dir string) ([]*MirrorChange, error) {
+	nb, err := findNotebook(db, ns, notebook)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, os.ModeDir|0700); err != nil {
+		return nil, err
+	}
+	state, err := loadMirrorState(dir)
+	if err != nil {
+		return nil, err
+	}
+	if state.NotebookGUID != "" && state.NotebookGUID != nb.GUID {
+		return nil, ErrMirrorNotebook
+	}
+	state.NotebookGUID = nb.GUID
+	tags, err := ns.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	m := &mirror{
+		db:       db,
+		ns:       ns,
+		notebook: nb,
+		dir:      dir,
+		state:    state,
+		tags:     make(map[string]string, len(tags)),
+		files:    make(map[string]bool),
+	}
+	for _, t := range tags {
+		m.tags[t.GUID] = t.Name
+	}
+	remote := make(map[string]*Note)
+	err = FindAllNotes(ns, &NoteFilter{NotebookGUID: nb.GUID}, 0, mirrorPageSize, func(list *NoteList) error {
+		for _, n := range list.Notes {
+			remote[n.GUID] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	local, added, err := m.readFiles()
+	if err != nil {
+		return nil, err
+	}
+	guids := make([]string, 0, len(state.Notes)+len(remote))
+	for guid := range state.Notes {
+		guids = append(guids, guid)
+	}
+	for guid := range remote {
+		if _, ok := state.Notes[guid]; !ok {
+			guids = append(guids, guid)
+		}
+	}
+	for guid := range local {
+		if _, ok := state.Notes[guid]; !ok && remote[guid] == nil {
+			guids = append(guids, guid)
+		}
+	}
+	sort.Strings(guids)
+	for _, guid := range guids {
+		if err = m.syncNote(guid, remote[guid], local[guid]); err != nil {
+			break
+		}
+	}
+	for i := 0; err == nil && i < len(added); i++ {
+		err = m.create(added[i])
+	}
+	// Save the state of the notes that were mirrored, even if one failed.
+	if saveErr := saveMirrorState(dir, state); err == nil {
+		err = saveErr
+	}
+	return m.changes, err
+}
+
+// syncNote mirrors the note with the GUID. The remote note or the local file
+// is nil if the note doesn't exist on that side.
+func (m *mirror) syncNote(guid string, remote *Note, local *mirrorFile) error {
+	entry := m.state.Notes[guid]
+	switch {
+	case remote == nil && local == nil:
+		delete(m.state.Notes, guid)
+		return nil
+	case remote == nil:
+		delete(m.state.Notes, guid)
+		if entry != nil && local.hash == entry.Hash {
+			if err := os.Remove(filepath.Join(m.dir, local.name)); err != nil {
+				return err
+			}
+			m.add(MirrorRemoved, local.name, local.note.Title)
+			return nil
+		}
+		// Only keep the local changes in a new note if the note has been
+		// expunged, so a moved or trashed note isn't duplicated.
+		if _, err := m.ns.GetNote(guid); err == nil {
+			m.add(MirrorKept, local.name, local.note.Title)
+			return nil
+		} else if err != ErrNoNoteFound {
+			return err
+		}
+		local.note.GUID = ""
+		return m.create(local)
+	case local == nil:
+		if entry != nil && entry.USN == remote.USN {
+			if err := m.ns.DeleteNote(guid); err != nil {
+				return err
+			}
+			delete(m.state.Notes, guid)
+			m.add(MirrorDeleted, entry.File, remote.Title)
+			return nil
+		}
+		return m.pull(remote, "")
+	}
+	localChanged := entry == nil || local.hash != entry.Hash
+	if entry != nil && entry.USN == remote.USN {
+		if localChanged {
+			return m.push(remote, local, entry)
+		}
+		entry.File = local.name
+		return nil
+	}
+	if err := m.fetch(remote); err != nil {
+		return err
+	}
+	data, hash, err := m.render(remote)
+	if err != nil {
+		return err
+	}
+	switch {
+	case entry != nil && hash == entry.Hash:
+		// Only the note's metadata was changed on the server.
+		entry.USN = remote.USN
+		if localChanged {
+			return m.push(remote, local, entry)
+		}
+		entry.File = local.name
+		return nil
+	case !localChanged:
+		return m.write(remote, local.name, data, hash, MirrorPulled)
+	case hash == local.hash:
+		m.state.Notes[guid] = &mirrorEntry{File: local.name, Hash: hash, USN: remote.USN}
+		return nil
+	}
+	err = ioutil.WriteFile(filepath.Join(m.dir, local.name+mirrorConflictExt), data, mirrorFileMode)
+	if err != nil {
+		return err
+	}
+	base := ""
+	if entry != nil {
+		base = entry.Hash
+	}
+	m.state.Notes[guid] = &mirrorEntry{File: local.name, Hash: base, USN: remote.USN, Conflict: true}
+	m.add(MirrorConflict, local.name, local.note.Title)
+	return nil
+}
+
+// pull writes the note from the server to the file. If name is empty, a new
+// file is used.
+func (m *mirror) pull(n *Note, name string) error {
+	if err := m.fetch(n); err != nil {
+		return err
+	}
+	data, hash, err := m.render(n)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = m.fileName(n.Title)
+	}
+	return m.write(n, name, data, hash, MirrorPulled)
+}
+
+// push updates the note on the server with the content of the file. The note
+// before and after the change is saved to the note history. A note with an
+// unresolved conflict is not pushed until the conflict file is removed.
+func (m *mirror) push(n *Note, f *mirrorFile, entry *mirrorEntry) error {
+	if entry.Conflict {
+		if _, err := os.Stat(filepath.Join(m.dir, f.name+mirrorConflictExt)); err == nil {
+			m.add(MirrorConflict, f.name, f.note.Title)
+			return nil
+		}
+	}
+	if err := m.fetch(n); err != nil {
+		return err
+	}
+	// Keep the current content in the history before it's changed.
+	current, err := newNoteSnapshot(n, enmlDocument(n.Body))
+	if err != nil {
+		return err
+	}
+	n.Title, n.Tags, n.MD = f.note.Title, f.note.Tags, f.note.MD
+	if n.Tags == nil {
+		// The file doesn't have any tags so they are removed from the note.
+		n.Tags = make([]*Tag, 0)
+	}
+	if err = saveChanges(m.ns, n, true, false); err != nil {
+		return err
+	}
+	if err = saveEditSnapshots(m.db, current, n); err != nil {
+		return err
+	}
+	return m.update(n, f.name, MirrorPushed)
+}
+
+// create creates a new note from the file in the mirrored notebook.
+func (m *mirror) create(f *mirrorFile) error {
+	n := &Note{Title: f.note.Title, Tags: f.note.Tags, MD: f.note.MD, Notebook: m.notebook}
+	if err := SaveNewNote(m.ns, n, false); err != nil {
+		return err
+	}
+	if err := saveEditSnapshots(m.db, nil, n); err != nil {
+		return err
+	}
+	return m.update(n, f.name, MirrorCreated)
+}
+
+// update writes the file again after the note was saved to the server, so the
+// GUID and the update time in the header matches the note on the server.
+func (m *mirror) update(n *Note, name string, action MirrorAction) error {
+	saved, err := m.ns.GetNote(n.GUID)
+	if err != nil {
+		return err
+	}
+	n.Updated, n.USN = saved.Updated, saved.USN
+	data, hash, err := m.render(n)
+	if err != nil {
+		return err
+	}
+	return m.write(n, name, data, hash, action)
+}
+
+// write saves the data to the file and the note's state.
+func (m *mirror) write(n *Note, name string, data []byte, hash string, action MirrorAction) error {
+	if err := ioutil.WriteFile(filepath.Join(m.dir, name), data, mirrorFileMode); err != nil {
+		return err
+	}
+	m.state.Notes[n.GUID] = &mirrorEntry{File: name, Hash: hash, USN: n.USN}
+	m.add(action, name, n.Title)
+	return nil
+}
+
+// fetch downloads the note's content if it hasn't been downloaded already.
+func (m *mirror) fetch(n *Note) error {
+	if n.Body != "" {
+		return nil
+	}
+	content, err := m.ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return err
+	}
+	if err = decodeXML(content, n); err != nil {
+		return err
+	}
+	if n.MD, err = markdown.FromENML(n.Body); err != nil {
+		return err
+	}
+	for _, t := range n.Tags {
+		if t.Name == "" {
+			t.Name = m.tags[t.GUID]
+		}
+	}
+	n.Notebook = m.notebook
+	return nil
+}
+
+// render returns the file content for the note and its hash. The hash is
+// calculated from the note as it's read back from the file.
+func (m *mirror) render(n *Note) ([]byte, string, error) {
+	buf := new(bytes.Buffer)
+	a := []string{
+		headSep,
+		headTitleField + headSpace + n.Title,
+		headGUIDField + headSpace + n.GUID,
+		headNotebookNameField + headSpace + m.notebook.Name,
+	}
+	if len(n.Tags) > 0 {
+		a = append(a, headTagsField+headSpace+strings.Join(tagNames(n.Tags), tagSep+headSpace))
+	}
+	if n.Updated != 0 {
+		updated := time.Unix(0, n.Updated*int64(time.Millisecond))
+		a = append(a, headUpdatedField+headSpace+updated.Format(mirrorTimeFormat))
+	}
+	a = append(a, headSep, n.MD)
+	buf.WriteString(strings.Join(a, "\n") + "\n")
+	f, err := parseMirrorFile("", buf.Bytes())
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), f.hash, nil
+}
+
+// fileName returns a file name for the note that isn't used in the folder.
+func (m *mirror) fileName(title string) string {
+	base := strings.Trim(mirrorFileReplacer.Replace(title), " .")
+	if base == "" {
+		base = untitledNote
+	}
+	name := base + mirrorExt
+	for i := 2; m.files[strings.ToLower(name)]; i++ {
+		name = base + " (" + strconv.Itoa(i) + ")" + mirrorExt
+	}
+	m.files[strings.ToLower(name)] = true
+	return name
+}
+
+func (m *mirror) add(action MirrorAction, name, title string) {
+	m.changes = append(m.changes, &MirrorChange{Action: action, File: name, Title: title})
+}
+
+// readFiles reads the note files in the folder. The files are returned by the
+// note's GUID. Files without a GUID, or with the same GUID as an earlier
+// file, are returned as added files.
+func (m *mirror) readFiles() (map[string]*mirrorFile, []*mirrorFile, error) {
+	infos, err := ioutil.ReadDir(m.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make(map[string]*mirrorFile)
+	var added []*mirrorFile
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != mirrorExt {
+			continue
+		}
+		m.files[strings.ToLower(info.Name())] = true
+		data, err := ioutil.ReadFile(filepath.Join(m.dir, info.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+		f, err := parseMirrorFile(info.Name(), data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if f.note.GUID == "" || files[f.note.GUID] != nil {
+			f.note.GUID = ""
+			added = append(added, f)
+			continue
+		}
+		files[f.note.GUID] = f
+	}
+	return files, added, nil
+}
+
+// parseMirrorFile parses the note file. The header is optional. If the file
+// doesn't have a title, the file name is used as the title.
+func parseMirrorFile(name string, data []byte) (*mirrorFile, error) {
+	n := new(Note)
+	if err := parseInput(bytes.NewReader(data), n, DefaultNoteOption); err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if scanner.Scan() && scanner.Text() == headSep {
+		for scanner.Scan() && scanner.Text() != headSep {
+			if strings.Index(scanner.Text(), headGUIDField) == 0 {
+				n.GUID = strings.TrimSpace(scanner.Text()[len(headGUIDField):])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if n.Title == "" {
+		n.Title = strings.TrimSuffix(name, mirrorExt)
+	}
+	return &mirrorFile{name: name, note: n, hash: hex.EncodeToString(n.Hash(false))}, nil
+}
+
+func loadMirrorState(dir string) (*mirrorState, error) {
+	state := &mirrorState{Notes: make(map[string]*mirrorEntry)}
+	data, err := ioutil.ReadFile(filepath.Join(dir, mirrorStateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Notes == nil {
+		state.Notes = make(map[string]*mirrorEntry)
+	}
+	return state, nil
+}
+
+func saveMirrorState(dir string, state *mirrorState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, mirrorStateFile), data, mirrorFileMode)
+}
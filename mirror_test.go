@@ -0,0 +1,229 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorNotebook(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The server's notes by GUID, with the content in the body.
+	server := map[string]*Note{
+		"GUID1": &Note{GUID: "GUID1", Title: "Groceries", Body: "<div>Milk</div>", USN: 1, Tags: []*Tag{&Tag{GUID: "Tag1"}}},
+	}
+	usn := int32(1)
+	var deleted []string
+	ns := new(mockNS)
+	ns.listTags = func() ([]*Tag, error) { return []*Tag{&Tag{GUID: "Tag1", Name: "home"}}, nil }
+	ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) {
+		list := &NoteList{}
+		for _, n := range server {
+			if n.Deleted {
+				continue
+			}
+			list.Notes = append(list.Notes, &Note{GUID: n.GUID, Title: n.Title, USN: n.USN, Tags: n.Tags, Notebook: &Notebook{GUID: "NB1"}})
+		}
+		list.TotalNotes = len(list.Notes)
+		return list, nil
+	}
+	ns.getNoteContent = func(guid string) (string, error) {
+		return enmlDocument(server[guid].Body), nil
+	}
+	ns.getNote = func(guid string) (*Note, error) {
+		n := server[guid]
+		if n == nil {
+			return nil, ErrNoNoteFound
+		}
+		return &Note{GUID: n.GUID, USN: n.USN, Updated: 1539856800000, Deleted: n.Deleted}, nil
+	}
+	ns.updateNote = func(n *Note) error {
+		usn++
+		tmp := new(Note)
+		if err := decodeXML(n.Body, tmp); err != nil {
+			return err
+		}
+		server[n.GUID] = &Note{GUID: n.GUID, Title: n.Title, Body: tmp.Body, USN: usn, Tags: n.Tags}
+		return nil
+	}
+	ns.createNote = func(n *Note) error {
+		usn++
+		n.GUID = "GUID" + strconv.Itoa(int(usn))
+		return ns.updateNote(n)
+	}
+	ns.deleteNote = func(guid string) error {
+		deleted = append(deleted, guid)
+		delete(server, guid)
+		return nil
+	}
+	var snapshots []*NoteSnapshot
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Timestamp: time.Now(), Notebooks: []*Notebook{&Notebook{GUID: "NB1", Name: "Home"}}, Limit: time.Hour}, nil
+		},
+		getNoteSnapshots: func(guid string) ([]*NoteSnapshot, error) { return nil, nil },
+		saveNoteSnapshot: func(s *NoteSnapshot) error { snapshots = append(snapshots, s); return nil },
+	}
+	readFile := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.NoError(err, "Should read the file")
+		return string(data)
+	}
+	writeFile := func(name, content string) {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		assert.NoError(err, "Should write the file")
+	}
+
+	t.Run("pull new note", func(t *testing.T) {
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*MirrorChange{&MirrorChange{Action: MirrorPulled, File: "Groceries.md", Title: "Groceries"}}, changes, "Wrong changes")
+		assert.Equal("---\ntitle: Groceries\nguid: GUID1\nnotebook: Home\ntags: home\n---\nMilk\n", readFile("Groceries.md"), "Wrong file content")
+	})
+	t.Run("no changes", func(t *testing.T) {
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Empty(changes, "Nothing should be changed")
+	})
+	t.Run("push local change", func(t *testing.T) {
+		writeFile("Groceries.md", "---\ntitle: Groceries\nguid: GUID1\nnotebook: Home\ntags: home\n---\nMilk\n\nBread\n")
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorPushed, changes[0].Action, "Note should be pushed")
+		assert.Contains(server["GUID1"].Body, "Bread", "Note should be updated on the server")
+		assert.Len(snapshots, 2, "The note before and after the change should be saved to the history")
+		assert.Contains(readFile("Groceries.md"), "\nupdated: ", "Update time should be written to the file")
+	})
+	t.Run("pull server change", func(t *testing.T) {
+		usn++
+		server["GUID1"].Body = "<div>Eggs</div>"
+		server["GUID1"].USN = usn
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorPulled, changes[0].Action, "Note should be pulled")
+		assert.True(strings.HasSuffix(readFile("Groceries.md"), "---\nEggs\n"), "File should have the server's content")
+	})
+	t.Run("conflict", func(t *testing.T) {
+		usn++
+		server["GUID1"].Body = "<div>Server</div>"
+		server["GUID1"].USN = usn
+		writeFile("Groceries.md", "---\ntitle: Groceries\nguid: GUID1\n---\nLocal\n")
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorConflict, changes[0].Action, "Should be a conflict")
+		assert.Contains(readFile("Groceries.md"), "Local", "Local file should be kept")
+		assert.Contains(readFile("Groceries.md"+mirrorConflictExt), "Server", "Server's version should be saved")
+
+		changes, err = MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorConflict, changes[0].Action, "Should be a conflict until the conflict file is removed")
+
+		assert.NoError(os.Remove(filepath.Join(dir, "Groceries.md"+mirrorConflictExt)))
+		changes, err = MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorPushed, changes[0].Action, "Local version should be pushed")
+		assert.Contains(server["GUID1"].Body, "Local", "Note should be updated on the server")
+	})
+	t.Run("create note from new file", func(t *testing.T) {
+		writeFile("Todo.md", "Call mom\n")
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorCreated, changes[0].Action, "Note should be created")
+		guid := ""
+		for g, n := range server {
+			if n.Title == "Todo" {
+				guid = g
+			}
+		}
+		assert.NotEmpty(guid, "Note should be created with the file name as the title")
+		assert.Contains(readFile("Todo.md"), "guid: "+guid+"\n", "GUID should be written to the file")
+	})
+	t.Run("delete note of removed file", func(t *testing.T) {
+		assert.NoError(os.Remove(filepath.Join(dir, "Todo.md")))
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorDeleted, changes[0].Action, "Note should be deleted")
+		assert.Len(deleted, 1, "Note should be moved to the trash")
+	})
+	t.Run("remove file of removed note", func(t *testing.T) {
+		delete(server, "GUID1")
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorRemoved, changes[0].Action, "File should be removed")
+		_, err = os.Stat(filepath.Join(dir, "Groceries.md"))
+		assert.True(os.IsNotExist(err), "File should be removed")
+	})
+	t.Run("remove tags", func(t *testing.T) {
+		server["GUIDB"] = &Note{GUID: "GUIDB", Title: "Books", Body: "<div>Dune</div>", USN: usn}
+		_, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		server["GUIDB"].Tags = []*Tag{&Tag{GUID: "Tag1"}}
+		writeFile("Books.md", "---\ntitle: Books\nguid: GUIDB\n---\nDune\n\nEmma\n")
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorPushed, changes[0].Action, "Note should be pushed")
+		assert.NotNil(server["GUIDB"].Tags, "An empty tag list should be sent")
+		assert.Empty(server["GUIDB"].Tags, "Tags should be removed")
+	})
+	t.Run("keep file of trashed note", func(t *testing.T) {
+		server["GUIDB"].Deleted = true
+		writeFile("Books.md", "---\ntitle: Books\nguid: GUIDB\n---\nDune\n\nEmma\n\nUlysses\n")
+		count := len(server)
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*MirrorChange{&MirrorChange{Action: MirrorKept, File: "Books.md", Title: "Books"}}, changes, "Wrong changes")
+		assert.Len(server, count, "Note should not be duplicated")
+		assert.Contains(readFile("Books.md"), "Ulysses", "File should be kept")
+	})
+	t.Run("create note of expunged note", func(t *testing.T) {
+		delete(server, "GUIDB")
+		changes, err := MirrorNotebook(store, ns, "Home", dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(MirrorCreated, changes[0].Action, "Note should be created")
+		assert.NotContains(readFile("Books.md"), "guid: GUIDB\n", "File should have the new note's GUID")
+	})
+	t.Run("other notebook", func(t *testing.T) {
+		store.getNotebookCache = func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Timestamp: time.Now(), Notebooks: []*Notebook{&Notebook{GUID: "NB2", Name: "Work"}}, Limit: time.Hour}, nil
+		}
+		_, err := MirrorNotebook(store, ns, "Work", dir)
+		assert.Equal(ErrMirrorNotebook, err, "Wrong error returned")
+	})
+}
+
+func TestMirrorFileName(t *testing.T) {
+	assert := assert.New(t)
+	m := &mirror{files: map[string]bool{"notes.md": true}}
+	assert.Equal("a-b.md", m.fileName("a/b"), "Invalid characters should be replaced")
+	assert.Equal("Notes (2).md", m.fileName("Notes"), "Used names should not be reused")
+	assert.Equal(untitledNote+mirrorExt, m.fileName(" . "), "Empty names should be replaced")
+}
@@ -25,8 +25,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/TcM1911/clinote/markdown"
 	uuid "github.com/satori/go.uuid"
@@ -40,9 +42,25 @@ const (
 	headSep               = "---"
 	headTitleField        = "title:"
 	headNotebookNameField = "notebook:"
+	headTagsField         = "tags:"
 	newNotePrependString  = "new_note_"
+	// searchTimeFormat is the time format used in Evernote's search grammar.
+	searchTimeFormat  = "20060102T150405Z"
+	searchCreatedTerm = "created:"
+	searchUpdatedTerm = "updated:"
+	// searchReminderTerms limits the search to notes with an open reminder.
+	searchReminderTerms = "reminderOrder:* -reminderDoneTime:*"
+	// searchTodoTerm limits the search to notes with checkboxes.
+	searchTodoTerm = "todo:*"
 )
 
+// editErrorLines are written above the note header when the note is reopened
+// because the content is invalid.
+var editErrorLines = []string{
+	"Error: %s",
+	"Fix the note and save it to try again. Close the editor without any changes to cancel.",
+}
+
 var (
 	// NoteFilterOrderCreated sorts the notes by create time.
 	NoteFilterOrderCreated = int32(1)
@@ -56,9 +74,25 @@ var (
 	NoteFilterOrderTitle = int32(5)
 )
 
+var noteFilterOrderNames = map[string]int32{
+	"created":   NoteFilterOrderCreated,
+	"updated":   NoteFilterOrderUpdated,
+	"relevance": NoteFilterOrderRelevance,
+	"title":     NoteFilterOrderTitle,
+}
+
 var (
 	// ErrNoNoteFound is returned if search resulted in no notes found.
 	ErrNoNoteFound = errors.New("no note found")
+	// ErrUnknownSortOrder is returned if the sort order name is not known.
+	ErrUnknownSortOrder = errors.New("unknown sort order")
+	// ErrNoTitle is returned if a note is created without a title.
+	ErrNoTitle = errors.New("the note needs a title")
+	// ErrNoContent is returned if there is no content to add to the note.
+	ErrNoContent = errors.New("no content given")
+	// ErrUnresolvedConflict is returned if the editor is closed without
+	// resolving the conflicts with the server's changes.
+	ErrUnresolvedConflict = errors.New("the conflicts with the server's changes were not resolved")
 )
 
 // NoteOption are used for options around notes.
@@ -70,7 +104,7 @@ const (
 	// RawNote option will display or edit the note in it's raw format.
 	RawNote = 1 << iota
 	// UseRecoveryPointNote should be used to signal that the user wants to
-	// reopen the note that the note store failed to save.
+	// reopen a note that the note store failed to save.
 	UseRecoveryPointNote
 )
 
@@ -92,6 +126,29 @@ type Note struct {
 	Created int64
 	// Updated
 	Updated int64
+	// Tags the note is tagged with.
+	Tags []*Tag
+	// Resources are the files attached to the note.
+	Resources []*Resource
+	// USN is the update sequence number of the last change to the note.
+	USN int32
+	// Attributes are the note's attributes. If nil, the attributes are
+	// not changed when the note is updated.
+	Attributes *NoteAttributes
+	// contentOffset is the number of blank lines that were trimmed from
+	// the start of the content when the note was parsed.
+	contentOffset int
+}
+
+// NoteAttributes are the note's attributes. All the times are in milliseconds.
+type NoteAttributes struct {
+	// ReminderOrder is set if the note has a reminder. Notes with reminders
+	// are sorted by the value.
+	ReminderOrder int64
+	// ReminderTime is when the reminder is due.
+	ReminderTime int64
+	// ReminderDoneTime is when the reminder was completed.
+	ReminderDoneTime int64
 }
 
 // Hash returns the hash for the note. If raw equals true, the raw
@@ -99,6 +156,7 @@ type Note struct {
 func (n *Note) Hash(raw bool) []byte {
 	hasher := md5.New()
 	hasher.Write([]byte(n.Title))
+	hasher.Write([]byte(strings.Join(tagNames(n.Tags), tagSep)))
 	if raw {
 		hasher.Write([]byte(n.Body))
 	} else {
@@ -113,19 +171,126 @@ type NoteFilter struct {
 	NotebookGUID string
 	// Words can be a search string or note title.
 	Words string
-	// Order
+	// Order is the sort order, one of the NoteFilterOrder values.
 	Order int32
+	// Ascending sorts the notes in ascending order instead of descending.
+	Ascending bool
+	// TagGUIDs limits the search to notes with all the tags.
+	TagGUIDs []string
+	// CreatedAfter limits the search to notes created at or after the time in milliseconds.
+	CreatedAfter int64
+	// CreatedBefore limits the search to notes created before the time in milliseconds.
+	CreatedBefore int64
+	// UpdatedAfter limits the search to notes updated at or after the time in milliseconds.
+	UpdatedAfter int64
+	// UpdatedBefore limits the search to notes updated before the time in milliseconds.
+	UpdatedBefore int64
+	// Inactive searches the notes in the trash instead of the active notes.
+	Inactive bool
+	// TimeZone is the time zone, for example "Europe/Stockholm", the server uses
+	// for relative dates in the search words.
+	TimeZone string
+	// Reminders limits the search to notes with a reminder that isn't done.
+	Reminders bool
+	// Todos limits the search to notes with checkboxes.
+	Todos bool
+}
+
+// SearchQuery returns the search words with the time ranges, the reminder
+// and the checkbox terms added using Evernote's search grammar.
+func (f *NoteFilter) SearchQuery() string {
+	terms := make([]string, 0, 7)
+	if f.Words != "" {
+		terms = append(terms, f.Words)
+	}
+	if f.Reminders {
+		terms = append(terms, searchReminderTerms)
+	}
+	if f.Todos {
+		terms = append(terms, searchTodoTerm)
+	}
+	ranges := []struct {
+		term string
+		ts   int64
+	}{
+		{searchCreatedTerm, f.CreatedAfter},
+		{"-" + searchCreatedTerm, f.CreatedBefore},
+		{searchUpdatedTerm, f.UpdatedAfter},
+		{"-" + searchUpdatedTerm, f.UpdatedBefore},
+	}
+	for _, r := range ranges {
+		if r.ts != 0 {
+			terms = append(terms, r.term+time.Unix(r.ts/1000, (r.ts%1000)*int64(time.Millisecond)).UTC().Format(searchTimeFormat))
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// ParseSortOrder returns the NoteFilterOrder value for the name. The valid names
+// are title, created, updated and relevance.
+func ParseSortOrder(name string) (int32, error) {
+	order, ok := noteFilterOrderNames[strings.ToLower(name)]
+	if !ok {
+		return 0, ErrUnknownSortOrder
+	}
+	return order, nil
+}
+
+// NoteList is a page of notes returned by a search.
+type NoteList struct {
+	// Notes are the notes in the page.
+	Notes []*Note
+	// StartIndex is the index of the first note in the page within the whole search result.
+	StartIndex int
+	// TotalNotes is the number of notes matching the search.
+	TotalNotes int
 }
 
 // FindNotes searches for notes.
-func FindNotes(ns NotestoreClient, filter *NoteFilter, offset int, count int) ([]*Note, error) {
+func FindNotes(ns NotestoreClient, filter *NoteFilter, offset int, count int) (*NoteList, error) {
 	return ns.FindNotes(filter, offset, count)
 }
 
+// FindAllNotes searches for notes starting at the offset and requests the result
+// in pages of pageSize notes until all the matching notes have been returned.
+// The function fn is called for each page as soon as it has been received.
+func FindAllNotes(ns NotestoreClient, filter *NoteFilter, offset, pageSize int, fn func(*NoteList) error) error {
+	for {
+		list, err := ns.FindNotes(filter, offset, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(list.Notes) == 0 {
+			return nil
+		}
+		if err = fn(list); err != nil {
+			return err
+		}
+		offset += len(list.Notes)
+		if offset >= list.TotalNotes {
+			return nil
+		}
+	}
+}
+
+// SaveSearch stores the search result so the notes can be referenced by their
+// index in the listing. The offset is the index of the first note in the search
+// result, so the index mapping is kept when a later page is listed.
+func SaveSearch(db Storager, offset int, notes []*Note) error {
+	search := make([]*Note, offset, offset+len(notes))
+	return db.SaveSearch(append(search, notes...))
+}
+
 // GetNote gets the note metadata in the notebook from the server.
 // If the notebook is an empty string, the first matching note will
 // be returned.
 func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, error) {
+	return getNote(db, ns, title, notebook, false)
+}
+
+// getNote returns the note with the title. If inactive is true, the note is
+// searched for in the trash instead of the active notes.
+func getNote(db Storager, ns NotestoreClient, title, notebook string, inactive bool) (*Note, error) {
 	// Check if the title is a number. If it is
 	// assume that the user wants to get the note
 	// from a saved search.
@@ -136,12 +301,12 @@ func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, er
 		if err != nil {
 			return nil, err
 		}
-		if index <= len(notes) {
+		if index <= len(notes) && notes[index-1] != nil && (!inactive || notes[index-1].Deleted) {
 			return notes[index-1], nil
 		}
 	}
 
-	filter := new(NoteFilter)
+	filter := &NoteFilter{Inactive: inactive}
 	if notebook != "" {
 		nb, err := findNotebook(db, ns, notebook)
 		if err != nil {
@@ -150,12 +315,12 @@ func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, er
 		filter.NotebookGUID = nb.GUID
 	}
 	filter.Words = title
-	notes, err := ns.FindNotes(filter, 0, 20)
+	list, err := ns.FindNotes(filter, 0, 20)
 	if err != nil {
 		return nil, err
 	}
 	var note *Note
-	for _, n := range notes {
+	for _, n := range list.Notes {
 		if n.Title == title {
 			note = n
 			break
@@ -170,19 +335,30 @@ func GetNote(db Storager, ns NotestoreClient, title, notebook string) (*Note, er
 // GetNoteWithContent returns the note with content from the user's notestore.
 func GetNoteWithContent(db Storager, ns NotestoreClient, title string) (*Note, error) {
 	n, err := GetNote(db, ns, title, "")
-	content, err := ns.GetNoteContent(n.GUID)
 	if err != nil {
 		return nil, err
 	}
+	if err = loadNoteContent(ns, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// loadNoteContent gets the content of the note from the notestore.
+func loadNoteContent(ns NotestoreClient, n *Note) error {
+	content, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return err
+	}
 	err = decodeXML(content, n)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	n.MD, err = markdown.FromHTML(n.Body)
+	n.MD, err = markdown.FromENML(n.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return n, nil
+	return populateTagNames(ns, n)
 }
 
 // SaveChanges updates the changes to the note on the server.
@@ -229,9 +405,9 @@ func DeleteNote(db Storager, ns NotestoreClient, title, notebook string) error {
 
 func saveChanges(ns NotestoreClient, n *Note, updateContent, useRawContent bool) error {
 	if updateContent {
-		body := toXML(n.MD)
-		if useRawContent {
-			body = fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body)
+		body, err := encodeNoteContent(n, useRawContent)
+		if err != nil {
+			return err
 		}
 		n.Body = body
 	}
@@ -244,13 +420,12 @@ func saveChanges(ns NotestoreClient, n *Note, updateContent, useRawContent bool)
 
 // SaveNewNote pushes the new note to the server.
 func SaveNewNote(ns NotestoreClient, n *Note, raw bool) error {
-	var body string
-	if !raw && n.MD != "" {
-		body = toXML(n.MD)
-	} else if raw {
-		body = fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, n.Body)
-	} else {
-		body = XMLHeader + "<en-note></en-note>"
+	body := XMLHeader + "<en-note></en-note>"
+	if raw || n.MD != "" {
+		var err error
+		if body, err = encodeNoteContent(n, raw); err != nil {
+			return err
+		}
 	}
 	n.Body = body
 	if err := ns.CreateNote(n); err != nil {
@@ -260,15 +435,34 @@ func SaveNewNote(ns NotestoreClient, n *Note, raw bool) error {
 }
 
 // EditNote opens the editor so the user can edit the note. Once the user closes the
-// editor, the note is saved to the notestore.
+// editor, the note is saved to the notestore. If the server can't be reached, the
+// change is queued and ErrChangeQueued is returned.
+//
+// If the UseRecoveryPointNote option is set, title is the index of the recovery point
+// to edit. If title is an empty string, the latest recovery point is used. The recovery
+// point is removed once the note has been saved.
 func EditNote(client *Client, title string, opts NoteOption) error {
 	db, ns := client.Store, client.NoteStore
 	var note *Note
+	var rp *RecoveryPoint
 	var err error
 	if opts&UseRecoveryPointNote != 0 {
-		note, err = db.GetNoteRecoveryPoint()
+		rp, err = getRecoveryPointByTitle(db, title)
+		if err != nil {
+			return err
+		}
+		note = rp.Note
+		// The note is edited the same way as when the recovery point was saved.
+		opts &^= RawNote
+		if rp.Raw {
+			opts |= RawNote
+			if note.Body, err = recoveredContent(note.Body); err != nil {
+				return err
+			}
+		}
+		// A note that failed to be created doesn't have a GUID.
 		if note.GUID == "" {
-			return ErrNoNoteFound
+			return createAndEditNote(client, note, rp, opts)
 		}
 	} else {
 		note, err = GetNoteWithContent(db, ns, title)
@@ -276,6 +470,14 @@ func EditNote(client *Client, title string, opts NoteOption) error {
 	if err != nil {
 		return err
 	}
+	return editExistingNote(client, note, rp, opts)
+}
+
+// editExistingNote opens the editor with the note and saves the changes once the
+// editor has been closed. The note must have its content. If rp is set, the note is
+// the recovery point's note and the recovery point is removed once it's saved.
+func editExistingNote(client *Client, note *Note, rp *RecoveryPoint, opts NoteOption) error {
+	db, ns := client.Store, client.NoteStore
 	oldHash := note.Hash(opts&RawNote != 0)
 	nb, err := GetNotebook(client.NoteStore, note.Notebook.GUID)
 	if err != nil {
@@ -283,11 +485,33 @@ func EditNote(client *Client, title string, opts NoteOption) error {
 	}
 	note.Notebook = nb
 	initialNotebook := getNotebookName(note)
-	cacheFile, err := editNote(client, note, opts)
+	// Snapshot the note before it's edited so the first edit can be reverted.
+	var original *NoteSnapshot
+	if rp == nil {
+		if original, err = newNoteSnapshot(note, enmlDocument(note.Body)); err != nil {
+			return err
+		}
+	}
+	// Keep the version the edit is based on so changes made on the
+	// server while the note is edited can be merged.
+	base := &Note{USN: note.USN, Updated: note.Updated, Body: note.Body, MD: note.MD}
+	cacheFile, err := editNote(client, note, opts, nil)
 	if err != nil {
 		return err
 	}
-	defer cacheFile.CloseAndRemove()
+	// The edited file is only removed once the note has been saved or
+	// queued, so the edit isn't lost if that fails.
+	keepFile := true
+	defer func() {
+		if cacheFile == nil {
+			return
+		}
+		if keepFile {
+			cacheFile.Close()
+		} else {
+			cacheFile.CloseAndRemove()
+		}
+	}()
 	err = parseNote(cacheFile, note, opts)
 	if err != nil {
 		return err
@@ -296,28 +520,200 @@ func EditNote(client *Client, title string, opts NoteOption) error {
 	if err != nil {
 		return err
 	}
-	if bytes.Equal(oldHash, note.Hash(opts&RawNote != 0)) && initialNotebook == note.Notebook.Name {
+	// A recovered note is saved even if it's unchanged since it failed to save before.
+	if rp == nil && bytes.Equal(oldHash, note.Hash(opts&RawNote != 0)) && initialNotebook == note.Notebook.Name {
+		keepFile = false
 		return nil
 	}
-	err = SaveChanges(ns, note, opts)
+	for {
+		var conflict bool
+		if conflict, err = mergeServerChanges(ns, note, base, opts); err != nil {
+			// A failed merge is handled the same way as a failed save.
+			break
+		}
+		var invalid *ENMLError
+		if !conflict {
+			err = SaveChanges(ns, note, opts)
+			if invalid, _ = err.(*ENMLError); invalid == nil {
+				break
+			}
+		}
+		// Reopen the editor with the conflict markers or the validation
+		// error so the user can fix the note.
+		hash := note.Hash(opts&RawNote != 0)
+		if cacheFile, err = reopenNote(client, cacheFile, note, opts, initialNotebook, invalid); err != nil {
+			break
+		}
+		if !bytes.Equal(hash, note.Hash(opts&RawNote != 0)) {
+			continue
+		}
+		// The note wasn't changed so the user gave up on fixing it.
+		if invalid != nil {
+			err = invalid
+			break
+		}
+		text := note.MD
+		if opts&RawNote != 0 {
+			text = note.Body
+		}
+		if conflict && hasConflictMarkers(text) {
+			err = ErrUnresolvedConflict
+			break
+		}
+	}
+	if err == ErrOffline {
+		err = queueOfflineChange(db, ChangeUpdate, note)
+	} else if err != nil {
+		return saveRecoveryPoint(db, note, rp, opts, cacheFile, err)
+	}
+	if err != nil && err != ErrChangeQueued {
+		return err
+	}
+	keepFile = false
+	// The note has been saved or queued so the recovery point isn't needed anymore.
+	if rp != nil {
+		if removeErr := db.RemoveNoteRecoveryPoint(rp.ID); removeErr != nil {
+			return removeErr
+		}
+	}
+	if snapErr := saveEditSnapshots(db, original, note); snapErr != nil {
+		return snapErr
+	}
+	return err
+}
+
+// saveEditSnapshots saves the snapshots of the note before and after it was edited
+// to the note history.
+func saveEditSnapshots(db Storager, original *NoteSnapshot, note *Note) error {
+	edited, err := newNoteSnapshot(note, note.Body)
+	if err == nil {
+		err = saveNoteSnapshots(db, original, edited)
+	}
 	if err != nil {
-		saveErr := db.SaveNoteRecoveryPoint(note)
-		if saveErr != nil {
-			err = errors.New("Error when saving note: " + err.Error() + "\nFailed to create recovery point: " + saveErr.Error())
+		return errors.New("Failed to save the note history: " + err.Error())
+	}
+	return nil
+}
+
+// getRecoveryPointByTitle returns the recovery point with the index in the title.
+// If the title is empty, the latest recovery point is returned.
+func getRecoveryPointByTitle(db Storager, title string) (*RecoveryPoint, error) {
+	if title != "" {
+		index, err := strconv.Atoi(title)
+		if err != nil {
+			return nil, ErrNoRecoveryPointFound
 		}
+		return GetRecoveryPoint(db, index)
+	}
+	rps, err := db.GetNoteRecoveryPoints()
+	if err != nil {
+		return nil, err
+	}
+	if len(rps) == 0 {
+		return nil, ErrNoRecoveryPointFound
+	}
+	return rps[len(rps)-1], nil
+}
+
+// saveRecoveryPoint saves the note that failed to be saved with the error as a
+// new recovery point. The new recovery point replaces rp if set. The error is
+// returned, with the path to the edited file if the recovery point couldn't
+// be saved.
+func saveRecoveryPoint(db Storager, note *Note, rp *RecoveryPoint, opts NoteOption, cacheFile CacheFile, err error) error {
+	saveErr := db.SaveNoteRecoveryPoint(note, opts&RawNote != 0)
+	if saveErr == nil && rp != nil {
+		saveErr = db.RemoveNoteRecoveryPoint(rp.ID)
+	}
+	if saveErr == nil {
+		return err
+	}
+	err = errors.New("Error when saving note: " + err.Error() + "\nFailed to create recovery point: " + saveErr.Error())
+	if cacheFile != nil {
+		err = errors.New(err.Error() + "\nThe edited note is kept in " + cacheFile.FilePath())
 	}
 	return err
 }
 
+// mergeServerChanges checks if the note has been changed on the server after the
+// version the edit is based on. If it has, the server's changes are merged into the
+// note and the base is updated to the server's version. True is returned if the
+// changes conflict, in which case the note's content includes conflict markers.
+func mergeServerChanges(ns NotestoreClient, note, base *Note, opts NoteOption) (bool, error) {
+	server, err := ns.GetNote(note.GUID)
+	// If the server can't be reached, the change is queued when saved
+	// and the conflict is detected when the queue is replayed.
+	if err == ErrOffline {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !changedOnServer(base, server) {
+		return false, nil
+	}
+	content, err := ns.GetNoteContent(note.GUID)
+	if err != nil {
+		return false, err
+	}
+	if err = decodeXML(content, server); err != nil {
+		return false, err
+	}
+	var conflict bool
+	if opts&RawNote != 0 {
+		note.Body, conflict = mergeText(base.Body, note.Body, server.Body)
+		base.Body = server.Body
+	} else {
+		md, err := markdown.FromENML(server.Body)
+		if err != nil {
+			return false, err
+		}
+		note.MD, conflict = mergeText(base.MD, note.MD, md)
+		base.MD = md
+	}
+	base.USN, base.Updated = server.USN, server.Updated
+	note.USN, note.Updated = server.USN, server.Updated
+	return conflict, nil
+}
+
+// changedOnServer returns true if the server's version of the note is newer than
+// the base. The update sequence number is used if set, otherwise the updated time.
+func changedOnServer(base, server *Note) bool {
+	if base.USN != 0 || server.USN != 0 {
+		return base.USN != server.USN
+	}
+	return base.Updated != server.Updated
+}
+
 // CreateAndEditNewNote creates a new note and opens it in the client's editor.
-// Once the editor has been closed, the note is saved to the notestore.
+// Once the editor has been closed, the note is saved to the notestore. If the
+// server can't be reached, the note is queued and ErrChangeQueued is returned.
+// If the note can't be saved, a recovery point is saved.
 func CreateAndEditNewNote(client *Client, note *Note, opts NoteOption) error {
+	return createAndEditNote(client, note, nil, opts)
+}
+
+// createAndEditNote opens the new note in the editor and creates it once the
+// editor has been closed. If rp is set, the note is the recovery point's note
+// and the recovery point is removed once the note has been created.
+func createAndEditNote(client *Client, note *Note, rp *RecoveryPoint, opts NoteOption) error {
 	initialNotebook := getNotebookName(note)
-	cacheFile, err := editNote(client, note, opts)
+	cacheFile, err := editNote(client, note, opts, nil)
 	if err != nil {
 		return err
 	}
-	defer cacheFile.CloseAndRemove()
+	// The edited file is only removed once the note has been created or
+	// queued, so the note isn't lost if that fails.
+	keepFile := true
+	defer func() {
+		if cacheFile == nil {
+			return
+		}
+		if keepFile {
+			cacheFile.Close()
+		} else {
+			cacheFile.CloseAndRemove()
+		}
+	}()
 	err = parseNote(cacheFile, note, opts)
 	if err != nil {
 		return err
@@ -326,7 +722,84 @@ func CreateAndEditNewNote(client *Client, note *Note, opts NoteOption) error {
 	if err != nil {
 		return err
 	}
-	return SaveNewNote(client.NoteStore, note, opts&RawNote != 0)
+	for {
+		err = SaveNewNote(client.NoteStore, note, opts&RawNote != 0)
+		invalid, _ := err.(*ENMLError)
+		if invalid == nil {
+			break
+		}
+		hash := note.Hash(opts&RawNote != 0)
+		if cacheFile, err = reopenNote(client, cacheFile, note, opts, initialNotebook, invalid); err != nil {
+			break
+		}
+		if bytes.Equal(hash, note.Hash(opts&RawNote != 0)) {
+			// The note wasn't changed so the user gave up on fixing it.
+			err = invalid
+			break
+		}
+	}
+	if err == ErrOffline {
+		// A queued note doesn't have a GUID until it's been created
+		// so its history starts with the next edit.
+		err = queueOfflineChange(client.Store, ChangeCreate, note)
+	} else if err != nil {
+		return saveRecoveryPoint(client.Store, note, rp, opts, cacheFile, err)
+	}
+	if err != nil && err != ErrChangeQueued {
+		return err
+	}
+	keepFile = false
+	// The note has been created or queued so the recovery point isn't needed anymore.
+	if rp != nil {
+		if removeErr := client.Store.RemoveNoteRecoveryPoint(rp.ID); removeErr != nil {
+			return removeErr
+		}
+	}
+	if err == ErrChangeQueued {
+		return err
+	}
+	return saveEditSnapshots(client.Store, nil, note)
+}
+
+// CreateNoteFromReader creates a new note with the content read from the reader.
+// The content can start with the same header as the note in the editor. The title,
+// notebook and tags in the header replace the values set on the note. If the server
+// can't be reached, the note is queued and ErrChangeQueued is returned.
+func CreateNoteFromReader(client *Client, note *Note, r io.Reader, opts NoteOption) error {
+	initialNotebook := getNotebookName(note)
+	if err := parseInput(r, note, opts); err != nil {
+		return err
+	}
+	if note.Title == "" {
+		return ErrNoTitle
+	}
+	if err := checkForNotebookAndUpdate(client, note, initialNotebook); err != nil {
+		return err
+	}
+	return CreateNote(client, note, opts)
+}
+
+// CreateNote saves the new note to the notestore like SaveNewNote. If the server
+// can't be reached, the note is queued and ErrChangeQueued is returned. Otherwise,
+// the created note is saved to the note history.
+func CreateNote(client *Client, note *Note, opts NoteOption) error {
+	err := SaveNewNote(client.NoteStore, note, opts&RawNote != 0)
+	if err == ErrOffline {
+		return queueOfflineChange(client.Store, ChangeCreate, note)
+	}
+	if err != nil {
+		return err
+	}
+	return saveEditSnapshots(client.Store, nil, note)
+}
+
+// queueOfflineChange queues the change that failed to be pushed because the
+// server couldn't be reached. ErrChangeQueued is returned if the change was queued.
+func queueOfflineChange(db Storager, t ChangeType, note *Note) error {
+	if err := queueChange(db, t, note); err != nil {
+		return errors.New("Error when saving note: " + ErrOffline.Error() + "\nFailed to queue the change: " + err.Error())
+	}
+	return ErrChangeQueued
 }
 
 func checkForNotebookAndUpdate(client *Client, note *Note, initialNotebook string) error {
@@ -357,7 +830,41 @@ func randomFilename(prepend string) (string, error) {
 	return prepend + id.String(), nil
 }
 
-func editNote(client *Client, note *Note, opts NoteOption) (CacheFile, error) {
+// reopenNote removes the old cache file and opens the note in the editor again.
+// If invalid is not nil, the validation error is written above the note header.
+// If the edited note can't be parsed, the new cache file is returned with the
+// error so the edit can be kept.
+func reopenNote(client *Client, old CacheFile, note *Note, opts NoteOption, initialNotebook string, invalid *ENMLError) (CacheFile, error) {
+	old.CloseAndRemove()
+	cacheFile, err := editNote(client, note, opts, invalid)
+	if err != nil {
+		return nil, err
+	}
+	if err = parseNote(cacheFile, note, opts); err != nil {
+		return cacheFile, err
+	}
+	if err = checkForNotebookAndUpdate(client, note, initialNotebook); err != nil {
+		return cacheFile, err
+	}
+	return cacheFile, nil
+}
+
+// writeEditError writes the validation error above the note header. Everything
+// before the header is ignored when the note is parsed. The error's line is
+// changed to refer to the line in the file.
+func writeEditError(w io.Writer, n *Note, invalid *ENMLError) error {
+	header := new(bytes.Buffer)
+	if err := writeNoteHeader(header, n); err != nil {
+		return err
+	}
+	if invalid.Line > 0 {
+		invalid.Line += len(editErrorLines) + strings.Count(header.String(), "\n") + n.contentOffset
+	}
+	_, err := fmt.Fprintf(w, strings.Join(editErrorLines, "\n")+"\n", invalid.Error())
+	return err
+}
+
+func editNote(client *Client, note *Note, opts NoteOption, invalid *ENMLError) (CacheFile, error) {
 	filename := ""
 
 	// If the note has a GUID == "", it is a new note.
@@ -381,6 +888,11 @@ func editNote(client *Client, note *Note, opts NoteOption) (CacheFile, error) {
 	if err != nil {
 		return nil, err
 	}
+	if invalid != nil {
+		if err = writeEditError(cacheFile, note, invalid); err != nil {
+			return nil, err
+		}
+	}
 	err = WriteNote(cacheFile, note, opts)
 	if err != nil {
 		return nil, err
@@ -411,16 +923,37 @@ func parseNote(r io.Reader, n *Note, opts NoteOption) error {
 	return parseContent(scanner, n, opts)
 }
 
+// parseInput parses the note read from the reader. Unlike parseNote, the
+// header is optional. The content is only parsed as a header if the first
+// line is the header separator.
+func parseInput(r io.Reader, n *Note, opts NoteOption) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r") == headSep {
+		return parseNote(bytes.NewReader(data), n, opts)
+	}
+	return parseContent(bufio.NewScanner(bytes.NewReader(data)), n, opts)
+}
+
 func parseHeader(scanner *bufio.Scanner, n *Note) error {
 	// Find beginning of the header.
+	found := false
 	for scanner.Scan() {
 		if scanner.Text() == headSep {
+			found = true
 			break
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		return err
 	}
+	// The tags line is left out of the header if the note doesn't have
+	// any tags, so a header without the line removes the note's tags.
+	if found {
+		n.Tags = make([]*Tag, 0)
+	}
 
 	// Parse header until the end.
 	for scanner.Scan() {
@@ -440,6 +973,11 @@ func parseHeader(scanner *bufio.Scanner, n *Note) error {
 				n.Notebook = new(Notebook)
 			}
 			n.Notebook.Name = strings.TrimSpace(line[len(headNotebookNameField):])
+			continue
+		}
+
+		if strings.Index(line, headTagsField) == 0 {
+			n.Tags = parseTags(line[len(headTagsField):])
 		}
 	}
 	return scanner.Err()
@@ -453,10 +991,12 @@ func parseContent(scanner *bufio.Scanner, n *Note, opts NoteOption) error {
 	if err := scanner.Err(); err != nil {
 		return err
 	}
+	content := strings.TrimLeft(buf.String(), "\n")
+	n.contentOffset = buf.Len() - len(content)
 	if opts&RawNote != 0 {
-		n.Body = strings.Trim(buf.String(), "\n")
+		n.Body = strings.TrimRight(content, "\n")
 	} else {
-		n.MD = strings.Trim(buf.String(), "\n")
+		n.MD = strings.TrimRight(content, "\n")
 	}
 	return nil
 }
@@ -469,6 +1009,9 @@ func writeNoteHeader(w io.Writer, n *Note) error {
 	if n.Notebook != nil && n.Notebook.Name != "" {
 		a = append(a, headNotebookNameField+headSpace+n.Notebook.Name)
 	}
+	if len(n.Tags) > 0 {
+		a = append(a, headTagsField+headSpace+strings.Join(tagNames(n.Tags), tagSep+headSpace))
+	}
 	a = append(a, headSep)
 	for _, line := range a {
 		_, err := w.Write([]byte(line + "\n"))
@@ -484,7 +1027,12 @@ func WriteNote(w io.Writer, n *Note, opts NoteOption) error {
 	if err := writeNoteHeader(w, n); err != nil {
 		return err
 	}
-	var err error
+	// Keep the blank lines the content started with when it was parsed so
+	// the lines match the file the note was parsed from.
+	_, err := w.Write([]byte(strings.Repeat("\n", n.contentOffset)))
+	if err != nil {
+		return err
+	}
 	if opts&RawNote != 0 {
 		_, err = w.Write([]byte(n.Body))
 	} else {
@@ -501,14 +1049,30 @@ func WriteNote(w io.Writer, n *Note, opts NoteOption) error {
 	return err
 }
 
-func toXML(mdBody string) string {
-	b := []byte("")
-	content := bytes.NewBuffer(b)
-	content.WriteString(XMLHeader)
-	content.WriteString("<en-note>")
-	content.Write(markdown.ToXML(mdBody))
-	content.WriteString("</en-note>")
-	return content.String()
+// encodeNoteContent returns the ENML document for the note. The content is validated
+// and an *ENMLError is returned if it's not valid ENML.
+func encodeNoteContent(n *Note, raw bool) (string, error) {
+	body, err := noteFragment(n, raw)
+	if err != nil {
+		return "", err
+	}
+	return enmlDocument(body), nil
+}
+
+// noteFragment returns the note's content as the content of an en-note element.
+// An *ENMLError is returned if the content is not valid ENML.
+func noteFragment(n *Note, raw bool) (string, error) {
+	if raw {
+		if err := validateENML(n.Body); err != nil {
+			return "", err
+		}
+		return n.Body, nil
+	}
+	body, err := markdown.ToENML(n.MD)
+	if err != nil {
+		return "", toENMLError(err, false)
+	}
+	return body, nil
 }
 
 func decodeXML(content string, v interface{}) error {
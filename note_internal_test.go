@@ -47,6 +47,49 @@ func TestNoteParsing(t *testing.T) {
 	}
 }
 
+func TestNoteTagParsing(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("parse tags", func(t *testing.T) {
+		n := new(Note)
+		err := parseNote(bytes.NewReader([]byte(contentWithTags)), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Tag{&Tag{Name: "Tag1"}, &Tag{Name: "Tag 2"}}, n.Tags, "Wrong tags parsed")
+		assert.Equal(noteContent, n.MD, "Wrong content parsed")
+	})
+	t.Run("empty tag line removes tags", func(t *testing.T) {
+		n := &Note{Tags: []*Tag{&Tag{Name: "Tag1"}}}
+		err := parseNote(bytes.NewReader([]byte("---\ntitle: Title\ntags:\n---\n")), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.NotNil(n.Tags, "Tags should be set")
+		assert.Len(n.Tags, 0, "Tags should be empty")
+	})
+	t.Run("missing tag line removes tags", func(t *testing.T) {
+		n := &Note{Tags: []*Tag{&Tag{Name: "Tag1"}}}
+		err := parseNote(bytes.NewReader([]byte("---\ntitle: Title\n---\n")), n, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.NotNil(n.Tags, "Tags should be set")
+		assert.Len(n.Tags, 0, "Tags should be empty")
+	})
+	t.Run("write tags", func(t *testing.T) {
+		n := &Note{
+			Title:    noteTitle,
+			MD:       noteContent,
+			Notebook: &Notebook{Name: notebookName},
+			Tags:     []*Tag{&Tag{Name: "Tag1"}, &Tag{Name: "Tag 2"}},
+		}
+		w := new(bytes.Buffer)
+		err := WriteNote(w, n, DefaultNoteOption)
+		assert.NoError(err, "Should not fail")
+		assert.Equal(contentWithTags, w.String(), "Wrong content written")
+	})
+	t.Run("tags change the hash", func(t *testing.T) {
+		n := &Note{Title: noteTitle, MD: noteContent}
+		h := n.Hash(false)
+		n.Tags = []*Tag{&Tag{Name: "Tag1"}}
+		assert.NotEqual(h, n.Hash(false), "Hash should change")
+	})
+}
+
 func TestNoteWriting(t *testing.T) {
 	assert := assert.New(t)
 	n := &Note{
@@ -81,6 +124,17 @@ the
 note
 `
 
+const contentWithTags = `---
+title: Note title
+notebook: Notebook name
+tags: Tag1, Tag 2
+---
+Body
+of
+the
+note
+`
+
 const compactContent = `---
 title:Note title
 notebook: Notebook name
@@ -20,6 +20,8 @@ package clinote
 import (
 	"bytes"
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -50,13 +52,23 @@ func TestGetNote(t *testing.T) {
 		assert.NoError(err)
 		assert.Equal(expectedNote, note)
 	})
+	t.Run("search note not listed from saved search", func(t *testing.T) {
+		expectedNote := &Note{Title: "2"}
+		store.getSearch = func() ([]*Note, error) {
+			return []*Note{nil, nil, new(Note)}, nil
+		}
+		ns := nsWithNote(expectedNote)
+		note, err := GetNote(store, ns, "2", "")
+		assert.NoError(err)
+		assert.Equal(expectedNote, note)
+	})
 	t.Run("handle cache note index overflow", func(t *testing.T) {
 		store.getSearch = func() ([]*Note, error) {
 			return []*Note{new(Note), new(Note), new(Note)}, nil
 		}
 		notes := []*Note{new(Note), new(Note)}
 		ns := new(mockNS)
-		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+		ns.findNotes = func(filter *NoteFilter, o, max int) (*NoteList, error) { return &NoteList{Notes: notes}, nil }
 		_, err := GetNote(store, ns, "4", "")
 		assert.Error(err)
 		assert.EqualError(err, ErrNoNoteFound.Error())
@@ -64,7 +76,7 @@ func TestGetNote(t *testing.T) {
 	t.Run("return error from FindNotes", func(t *testing.T) {
 		expectedError := errors.New("Expected error")
 		ns := new(mockNS)
-		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return nil, expectedError }
+		ns.findNotes = func(filter *NoteFilter, o, max int) (*NoteList, error) { return nil, expectedError }
 		_, err := GetNote(store, ns, "title", "")
 		assert.EqualError(err, expectedError.Error())
 	})
@@ -75,7 +87,7 @@ func TestGetNote(t *testing.T) {
 		notes := []*Note{otherNote1, otherNote2}
 
 		ns := new(mockNS)
-		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+		ns.findNotes = func(filter *NoteFilter, o, max int) (*NoteList, error) { return &NoteList{Notes: notes}, nil }
 		_, err := GetNote(store, ns, title, "")
 		assert.EqualError(err, ErrNoNoteFound.Error())
 	})
@@ -90,7 +102,7 @@ func TestGetNote(t *testing.T) {
 		books := []*Notebook{otherBook, expectedNotebook}
 
 		ns := new(mockNS)
-		ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+		ns.findNotes = func(filter *NoteFilter, o, max int) (*NoteList, error) { return &NoteList{Notes: notes}, nil }
 		ns.getAllNotebooks = func() ([]*Notebook, error) { return books, nil }
 		note, err := GetNote(store, ns, title, notebook)
 		assert.NoError(err)
@@ -183,6 +195,17 @@ func TestSaveChanges(t *testing.T) {
 		assert.NoError(err, "Should not return an error")
 		assert.Equal(expectedRawContent, note.Body, "Note content doesn't match")
 	})
+	t.Run("invalid raw content", func(t *testing.T) {
+		ns := new(mockNS)
+		invalid := "<p>" + body + "</p>\n<p><script>alert(1)</script></p>"
+		note := &Note{Body: invalid}
+		err := SaveChanges(ns, note, RawNote)
+		if assert.IsType(&ENMLError{}, err, "Should return a validation error") {
+			assert.Equal(2, err.(*ENMLError).Line, "Wrong line")
+			assert.Equal(4, err.(*ENMLError).Column, "Wrong column")
+		}
+		assert.Equal(invalid, note.Body, "Content should not be changed")
+	})
 }
 
 func TestChangeTitle(t *testing.T) {
@@ -196,7 +219,7 @@ func TestChangeTitle(t *testing.T) {
 		ns := new(mockNS)
 		note := &Note{Title: "Old"}
 		var savedNote *Note
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
 
 		err := ChangeTitle(store, ns, "Old", "New")
@@ -207,7 +230,7 @@ func TestChangeTitle(t *testing.T) {
 	t.Run("should handle error from saveChanges", func(t *testing.T) {
 		ns := new(mockNS)
 		note := &Note{Title: "Old"}
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.updateNote = func(*Note) error { return expectedError }
 
 		err := ChangeTitle(store, ns, "Old", "New")
@@ -216,7 +239,7 @@ func TestChangeTitle(t *testing.T) {
 	})
 	t.Run("should handle error from GetNote", func(t *testing.T) {
 		ns := new(mockNS)
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return nil, expectedError }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return nil, expectedError }
 
 		err := ChangeTitle(store, ns, "Old", "New")
 		assert.Error(err, "Should return an error")
@@ -243,7 +266,7 @@ func TestMoveNote(t *testing.T) {
 
 		note := &Note{Title: noteName, Notebook: &Notebook{Name: "Old", GUID: "Old GUID"}}
 		var savedNote *Note
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
 
 		err := MoveNote(store, ns, noteName, notebookName)
@@ -256,7 +279,7 @@ func TestMoveNote(t *testing.T) {
 		ns := new(mockNS)
 		notebook := &Notebook{Name: notebookName, GUID: notebookGUID}
 		note := &Note{Title: noteName, Notebook: notebook}
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.getAllNotebooks = func() ([]*Notebook, error) { return []*Notebook{notebook}, nil }
 		ns.updateNote = func(*Note) error { return expectedError }
 
@@ -266,7 +289,7 @@ func TestMoveNote(t *testing.T) {
 	})
 	t.Run("should handle error from GetNote", func(t *testing.T) {
 		ns := new(mockNS)
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return nil, expectedError }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return nil, expectedError }
 
 		err := MoveNote(store, ns, noteName, notebookName)
 		assert.Error(err, "Should return an error")
@@ -275,7 +298,7 @@ func TestMoveNote(t *testing.T) {
 	t.Run("should handle error from FindNote", func(t *testing.T) {
 		ns := new(mockNS)
 		note := &Note{Title: noteName}
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.getAllNotebooks = func() ([]*Notebook, error) { return nil, expectedError }
 		ns.updateNote = func(*Note) error { return expectedError }
 
@@ -297,7 +320,7 @@ func TestDeleteNote(t *testing.T) {
 	t.Run("should delete note", func(t *testing.T) {
 		note := &Note{Title: noteTitle, GUID: noteGUID}
 		ns := nsWithNote(note)
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.deleteNote = func(g string) error {
 			if g == noteGUID {
 				return nil
@@ -310,7 +333,7 @@ func TestDeleteNote(t *testing.T) {
 	t.Run("should return error from DeleteNote", func(t *testing.T) {
 		note := &Note{Title: noteTitle, GUID: noteGUID}
 		ns := nsWithNote(note)
-		ns.findNotes = func(*NoteFilter, int, int) ([]*Note, error) { return []*Note{note}, nil }
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return &NoteList{Notes: []*Note{note}}, nil }
 		ns.deleteNote = func(g string) error { return expectedError }
 		err := DeleteNote(store, ns, noteTitle, "")
 		assert.Error(err, "Should note return an error")
@@ -341,6 +364,18 @@ func TestSaveNewNote(t *testing.T) {
 			assert.Equal(test.N, createdNote, "Should save the correct note")
 		})
 	}
+	t.Run("invalid raw content", func(t *testing.T) {
+		ns := new(mockNS)
+		err := SaveNewNote(ns, &Note{Body: "<p>Unclosed"}, true)
+		assert.IsType(&ENMLError{}, err, "Should return a validation error")
+	})
+	t.Run("invalid html in markdown", func(t *testing.T) {
+		ns := new(mockNS)
+		err := SaveNewNote(ns, &Note{MD: "Text\n\n<form>\n</form>"}, false)
+		if assert.IsType(&ENMLError{}, err, "Should return a validation error") {
+			assert.Equal(0, err.(*ENMLError).Line, "Position should not refer to the converted content")
+		}
+	})
 	t.Run("return error from CreateNote", func(t *testing.T) {
 		ns := new(mockNS)
 		ns.createNote = func(*Note) error { return expectedError }
@@ -357,6 +392,9 @@ func TestEditNote(t *testing.T) {
 	var setupClientAndStore = func(addToNote string) (*Client, *mockNS, *[]byte, *Note, string, *mockStore) {
 		// Setup store
 		store := new(mockStore)
+		var snapshots []*NoteSnapshot
+		store.getNoteSnapshots = func(string) ([]*NoteSnapshot, error) { return snapshots, nil }
+		store.saveNoteSnapshot = func(s *NoteSnapshot) error { snapshots = append(snapshots, s); return nil }
 
 		notebookGUID := "NOTEBOOKGUID"
 		notebookName := "Name of the notebook"
@@ -583,7 +621,7 @@ func TestEditNote(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
 		ns.updateNote = func(*Note) error { return expectedError }
 		var savedNote *Note
-		store.saveNoteRecoveryPoint = func(n *Note) error {
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error {
 			savedNote = n
 			return nil
 		}
@@ -595,11 +633,265 @@ func TestEditNote(t *testing.T) {
 		assert.Equal(expectedNote, savedNote, "Note not saved")
 	})
 
+	t.Run("merge_server_changes", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("added text")
+		expectedNote.USN = 1
+		calls := 0
+		ns.getNoteContent = func(string) (string, error) {
+			calls++
+			if calls == 1 {
+				return expectedNote.Body, nil
+			}
+			return "<en-note><p>Server text</p><p>Body content</p></en-note>", nil
+		}
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, USN: 2}, nil }
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(savedNote.Body, "Server text", "Server changes should be merged")
+		assert.Contains(savedNote.Body, "added text", "Local changes should be kept")
+		assert.NotContains(savedNote.Body, conflictStartMarker, "Should not have conflicts")
+		assert.Equal(int32(2), savedNote.USN, "Note should be based on the server version")
+	})
+
+	t.Run("reopen_editor_on_conflict", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		expectedNote.USN = 1
+		calls := 0
+		ns.getNoteContent = func(string) (string, error) {
+			calls++
+			if calls == 1 {
+				return expectedNote.Body, nil
+			}
+			return "<en-note><p>Server content</p></en-note>", nil
+		}
+		serverUSN := int32(2)
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, USN: serverUSN}, nil }
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+		edits := make([]string, 0)
+		c.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				cache := file.(*mockCacheFile)
+				edits = append(edits, cache.buffer.String())
+				cache.buffer.Reset()
+				content := "Local content"
+				if len(edits) > 1 {
+					content = "Resolved content"
+				}
+				_, err := cache.buffer.WriteString("---\ntitle: " + expectedNote.Title + "\n---\n" + content + "\n")
+				return err
+			},
+		}
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(edits, 2, "Editor should be reopened") {
+			assert.Contains(edits[1], conflictStartMarker, "Conflict markers should be written to the editor")
+			assert.Contains(edits[1], "Local content", "Local changes should be in the editor")
+			assert.Contains(edits[1], "Server content", "Server changes should be in the editor")
+		}
+		assert.Contains(savedNote.Body, "Resolved content", "Resolved content should be saved")
+	})
+
+	t.Run("keep_unresolved_conflict", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("")
+		expectedNote.USN = 1
+		calls := 0
+		ns.getNoteContent = func(string) (string, error) {
+			calls++
+			if calls == 1 {
+				return expectedNote.Body, nil
+			}
+			return "<en-note><p>Server content</p></en-note>", nil
+		}
+		ns.getNote = func(guid string) (*Note, error) { return &Note{GUID: guid, USN: 2}, nil }
+		ns.updateNote = func(n *Note) error { return errors.New("should not be called") }
+		var recovered *Note
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error { recovered = n; return nil }
+		edits := 0
+		c.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				edits++
+				if edits > 1 {
+					// The editor is closed without resolving the conflict.
+					return nil
+				}
+				cache := file.(*mockCacheFile)
+				cache.buffer.Reset()
+				_, err := cache.buffer.WriteString("---\ntitle: " + expectedNote.Title + "\n---\nLocal content\n")
+				return err
+			},
+		}
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(ErrUnresolvedConflict, err, "Wrong error returned")
+		assert.Equal(2, edits, "Editor should be reopened once")
+		if assert.NotNil(recovered, "Recovery point should be saved") {
+			assert.Contains(recovered.MD, conflictStartMarker, "Conflict should be in the recovery point")
+		}
+	})
+
+	t.Run("reopen_editor_on_invalid_content", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error { savedNote = n; return nil }
+		edits := make([]string, 0)
+		c.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				cache := file.(*mockCacheFile)
+				edits = append(edits, cache.buffer.String())
+				cache.buffer.Reset()
+				content := "<p>Text</p>\n<p><b>Bold</p>"
+				if len(edits) > 1 {
+					content = "<p>Text</p>\n<p><b>Bold</b></p>"
+				}
+				_, err := cache.buffer.WriteString("---\ntitle: " + expectedNote.Title + "\n---\n" + content + "\n")
+				return err
+			},
+		}
+
+		err := EditNote(c, expectedNote.Title, RawNote)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(edits, 2, "Editor should be reopened") {
+			assert.True(strings.HasPrefix(edits[1], "Error: invalid note content at line 8,"), "Error should be written above the header: %s", edits[1])
+			assert.Contains(edits[1], "<p><b>Bold</p>", "Invalid content should be in the editor")
+		}
+		assert.Contains(savedNote.Body, "<b>Bold</b>", "Fixed content should be saved")
+	})
+
+	t.Run("invalid_content_after_blank_lines", func(t *testing.T) {
+		c, ns, _, expectedNote, _ := setupClient("")
+		ns.updateNote = func(n *Note) error { return nil }
+		edits := make([]string, 0)
+		c.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				cache := file.(*mockCacheFile)
+				edits = append(edits, cache.buffer.String())
+				if len(edits) > 1 {
+					cache.buffer.Reset()
+					_, err := cache.buffer.WriteString("---\ntitle: " + expectedNote.Title + "\n---\n<p>Text</p>\n")
+					return err
+				}
+				cache.buffer.Reset()
+				_, err := cache.buffer.WriteString("---\ntitle: " + expectedNote.Title + "\n---\n\n\n<p>Text</p>\n<p><b>Bold</p>\n")
+				return err
+			},
+		}
+
+		err := EditNote(c, expectedNote.Title, RawNote)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(edits, 2, "Editor should be reopened") {
+			lines := strings.Split(edits[1], "\n")
+			assert.True(strings.HasPrefix(lines[0], "Error: invalid note content at line 10,"), "Wrong line in the error: %s", lines[0])
+			assert.Equal("<p><b>Bold</p>", lines[9], "Line 10 should have the invalid content")
+		}
+	})
+
+	t.Run("give_up_on_invalid_content", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("")
+		ns.updateNote = func(n *Note) error { return errors.New("should not be called") }
+		var recovered *Note
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error { recovered = n; return nil }
+		edits := 0
+		c.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				edits++
+				if edits > 1 {
+					return nil
+				}
+				cache := file.(*mockCacheFile)
+				cache.buffer.Reset()
+				_, err := cache.buffer.WriteString("---\ntitle: " + expectedNote.Title + "\n---\n<p onclick=\"x\">Text</p>\n")
+				return err
+			},
+		}
+
+		err := EditNote(c, expectedNote.Title, RawNote)
+		assert.Equal(2, edits, "Editor should be reopened once")
+		if assert.IsType(&ENMLError{}, err, "Should return the validation error") {
+			assert.Equal(7, err.(*ENMLError).Line, "Line should refer to the reopened file")
+		}
+		assert.NotNil(recovered, "Recovery point should be saved")
+	})
+
+	t.Run("save_snapshots", func(t *testing.T) {
+		c, ns, _, expectedNote, originalContent, store := setupClientAndStore("added text")
+		ns.updateNote = func(*Note) error { return nil }
+		var saved []*NoteSnapshot
+		store.saveNoteSnapshot = func(s *NoteSnapshot) error { saved = append(saved, s); return nil }
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(saved, 2, "Should save the note before and after the edit") {
+			assert.Equal(expectedNote.GUID, saved[0].NoteGUID, "Wrong note GUID")
+			assert.Equal("Name of the notebook", saved[0].Notebook, "Wrong notebook")
+			assert.Equal(originalContent, saved[0].MD, "Original content should be saved first")
+			assert.Contains(saved[1].MD, "added text", "Edited content should be saved last")
+			assert.True(strings.HasPrefix(saved[1].Body, XMLHeader), "Body should be an ENML document")
+			assert.NotEqual(saved[0].Hash, saved[1].Hash, "Hashes should differ")
+		}
+	})
+
+	t.Run("queue_change_if_offline", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
+		ns.updateNote = func(*Note) error { return ErrOffline }
+		var queued *Change
+		store.queueChange = func(c *Change) error { queued = c; return nil }
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(ErrChangeQueued, err, "Wrong error returned")
+		assert.Equal(ChangeUpdate, queued.Type, "Wrong change type")
+		assert.Equal(expectedNote.GUID, queued.Note.GUID, "Wrong note queued")
+		assert.Contains(queued.Note.Body, "added text", "Change should be queued")
+	})
+
+	t.Run("keep_edit_if_merge_fails", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
+		var cache *mockCacheFile
+		c.newCacheFile = func(c *Client, filename string) (CacheFile, error) {
+			cache = &mockCacheFile{buffer: new(bytes.Buffer)}
+			return cache, nil
+		}
+		ns.getNote = func(string) (*Note, error) { return nil, expectedError }
+		ns.updateNote = func(*Note) error { return errors.New("should not be called") }
+		var recovered *Note
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error { recovered = n; return nil }
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		if assert.NotNil(recovered, "Recovery point should be saved") {
+			assert.Contains(recovered.MD, "added text", "Edit should be in the recovery point")
+		}
+		assert.False(cache.removed, "Edited file should be kept")
+	})
+
+	t.Run("queue_change_if_offline_during_merge", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
+		ns.getNote = func(string) (*Note, error) { return &Note{GUID: expectedNote.GUID, USN: 5}, nil }
+		content := ns.getNoteContent
+		ns.getNoteContent = func(guid string) (string, error) {
+			// The server goes offline after the note has been opened.
+			ns.getNoteContent = func(string) (string, error) { return "", ErrOffline }
+			return content(guid)
+		}
+		var queued *Change
+		store.queueChange = func(c *Change) error { queued = c; return nil }
+
+		err := EditNote(c, expectedNote.Title, DefaultNoteOption)
+		assert.Equal(ErrChangeQueued, err, "Wrong error returned")
+		if assert.NotNil(queued, "Change should be queued") {
+			assert.Contains(queued.Note.MD, "added text", "Edit should be queued")
+		}
+	})
+
 	t.Run("warn_if_recovery_fails", func(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
 		ns.updateNote = func(*Note) error { return expectedError }
 		expectedSaveError := errors.New("recovery error")
-		store.saveNoteRecoveryPoint = func(n *Note) error {
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error {
 			return expectedSaveError
 		}
 
@@ -611,11 +903,16 @@ func TestEditNote(t *testing.T) {
 
 	t.Run("recover_note", func(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
-		store.saveNoteRecoveryPoint = func(n *Note) error {
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error {
 			return nil
 		}
-		store.getNoteRecoveryPoint = func() (*Note, error) {
-			return expectedNote, nil
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) {
+			return []*RecoveryPoint{{ID: "old"}, {ID: "recovery", Note: expectedNote}}, nil
+		}
+		var removed string
+		store.removeNoteRecoveryPoint = func(id string) error {
+			removed = id
+			return nil
 		}
 		ns.getNoteContent = func(string) (string, error) { return "", errors.New("should not be called") }
 
@@ -626,26 +923,82 @@ func TestEditNote(t *testing.T) {
 			savedNote = n
 			return nil
 		}
-		err := EditNote(c, expectedNote.Title, DefaultNoteOption|UseRecoveryPointNote)
+		err := EditNote(c, "2", DefaultNoteOption|UseRecoveryPointNote)
 		assert.NoError(err, "Should not return an error")
 		assert.True(saveNoteCalled)
 		assert.Equal(expectedNote, savedNote, "Wrong note saved")
+		assert.Equal("recovery", removed, "Recovery point should be removed")
 	})
 
-	t.Run("error_recover_note_if_empty", func(t *testing.T) {
+	t.Run("recover_latest_note", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("")
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) {
+			return []*RecoveryPoint{{ID: "old", Note: &Note{GUID: "old"}}, {ID: "latest", Note: expectedNote}}, nil
+		}
+		store.removeNoteRecoveryPoint = func(string) error { return nil }
+		var savedNote *Note
+		ns.updateNote = func(n *Note) error {
+			savedNote = n
+			return nil
+		}
+		err := EditNote(c, "", DefaultNoteOption|UseRecoveryPointNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(expectedNote, savedNote, "Latest recovery point should be used")
+	})
+
+	t.Run("replace_recovery_point_if_save_fails", func(t *testing.T) {
 		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
-		expectedNote.GUID = ""
-		store.saveNoteRecoveryPoint = func(n *Note) error {
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) {
+			return []*RecoveryPoint{{ID: "recovery", Note: expectedNote}}, nil
+		}
+		var saved *Note
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error {
+			saved = n
 			return nil
 		}
-		store.getNoteRecoveryPoint = func() (*Note, error) {
-			return expectedNote, nil
+		var removed string
+		store.removeNoteRecoveryPoint = func(id string) error {
+			removed = id
+			return nil
+		}
+		ns.updateNote = func(*Note) error { return expectedError }
+		err := EditNote(c, "1", DefaultNoteOption|UseRecoveryPointNote)
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Equal(expectedNote, saved, "New recovery point should be saved")
+		assert.Equal("recovery", removed, "Old recovery point should be removed")
+	})
+
+	t.Run("error_recover_note_with_invalid_id", func(t *testing.T) {
+		c, _, _, _, _, store := setupClientAndStore("")
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) {
+			return []*RecoveryPoint{}, nil
+		}
+		err := EditNote(c, "1", DefaultNoteOption|UseRecoveryPointNote)
+		assert.Equal(ErrNoRecoveryPointFound, err, "Wrong error returned")
+	})
+
+	t.Run("recover_new_note", func(t *testing.T) {
+		c, ns, _, expectedNote, _, store := setupClientAndStore("added text")
+		expectedNote.GUID = ""
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) {
+			return []*RecoveryPoint{{ID: "recovery", Note: expectedNote}}, nil
+		}
+		var removed string
+		store.removeNoteRecoveryPoint = func(id string) error {
+			removed = id
+			return nil
 		}
 		ns.getNoteContent = func(string) (string, error) { return "", errors.New("should not be called") }
+		ns.updateNote = func(*Note) error { return errors.New("should not be called") }
+		var created *Note
+		ns.createNote = func(n *Note) error { created = n; return nil }
 
-		err := EditNote(c, expectedNote.Title, DefaultNoteOption|UseRecoveryPointNote)
-		assert.Error(err, "Should return an error")
-		assert.Equal(ErrNoNoteFound, err, "Wrong error returned")
+		err := EditNote(c, "", DefaultNoteOption|UseRecoveryPointNote)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(created, "Note should be created") {
+			assert.Contains(created.Body, "added text", "Edit should be saved")
+		}
+		assert.Equal("recovery", removed, "Recovery point should be removed")
 	})
 
 	t.Run("handle_error_from_GetNotebook", func(t *testing.T) {
@@ -772,11 +1125,186 @@ notebook: Name of the notebook
 		assert.NoError(err)
 		assert.Equal("Name of the notebook", savedNote.Notebook.Name)
 	})
+
+	t.Run("keep_note_if_user_gives_up_on_invalid_content", func(t *testing.T) {
+		var recovered *Note
+		var recoveredRaw bool
+		store.saveNoteRecoveryPoint = func(n *Note, raw bool) error {
+			recovered, recoveredRaw = n, raw
+			return nil
+		}
+		ns.createNote = func(n *Note) error { return errors.New("should not be called") }
+		var cache *mockCacheFile
+		client.newCacheFile = func(c *Client, filename string) (CacheFile, error) {
+			cache = &mockCacheFile{buffer: new(bytes.Buffer)}
+			return cache, nil
+		}
+		edits := 0
+		client.Editor = &mockEditor{
+			edit: func(file CacheFile) error {
+				edits++
+				if edits > 1 {
+					return nil
+				}
+				cache := file.(*mockCacheFile)
+				cache.buffer.Reset()
+				_, err := cache.buffer.WriteString("---\ntitle: New note\n---\n<p onclick=\"x\">Typed text</p>\n")
+				return err
+			},
+		}
+		err := CreateAndEditNewNote(client, &Note{Title: "New note"}, RawNote)
+		assert.IsType(&ENMLError{}, err, "Should return the validation error")
+		if assert.NotNil(recovered, "Recovery point should be saved") {
+			assert.Contains(recovered.Body, "Typed text", "Typed content should be in the recovery point")
+			assert.True(recoveredRaw, "Recovery point should be raw")
+		}
+		assert.False(cache.removed, "Edited file should be kept")
+	})
+}
+
+func TestCreateNoteFromReader(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Timestamp: time.Now(), Notebooks: []*Notebook{&Notebook{GUID: "NB1", Name: "Work"}}, Limit: time.Hour}, nil
+		},
+	}
+	ns := new(mockNS)
+	var saved *Note
+	ns.createNote = func(n *Note) error { saved = n; return nil }
+	client := &Client{Store: store, NoteStore: ns}
+
+	t.Run("content without header", func(t *testing.T) {
+		err := CreateNoteFromReader(client, &Note{Title: "Log"}, strings.NewReader("# Build\n\nPassed\n"), DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Log", saved.Title, "Title should be kept")
+		assert.Equal("# Build\n\nPassed", saved.MD, "Wrong Markdown")
+		assert.Contains(saved.Body, "<h1>Build</h1>", "Markdown should be converted")
+	})
+	t.Run("content with header", func(t *testing.T) {
+		input := "---\ntitle: From header\nnotebook: Work\ntags: log, build\n---\nPassed\n"
+		err := CreateNoteFromReader(client, &Note{Title: "Log"}, strings.NewReader(input), DefaultNoteOption)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("From header", saved.Title, "Title should be set from the header")
+		assert.Equal("NB1", saved.Notebook.GUID, "Notebook should be set from the header")
+		assert.Equal([]string{"log", "build"}, tagNames(saved.Tags), "Tags should be set from the header")
+		assert.Equal("Passed", saved.MD, "Header should not be part of the content")
+	})
+	t.Run("raw content", func(t *testing.T) {
+		err := CreateNoteFromReader(client, &Note{Title: "Log"}, strings.NewReader("<p>Passed</p>"), RawNote)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(XMLHeader+"<en-note><p>Passed</p></en-note>", saved.Body, "Wrong content")
+	})
+	t.Run("no title", func(t *testing.T) {
+		err := CreateNoteFromReader(client, new(Note), strings.NewReader("Passed"), DefaultNoteOption)
+		assert.Equal(ErrNoTitle, err, "Wrong error returned")
+	})
 }
 
 func nsWithNote(note *Note) *mockNS {
 	notes := []*Note{&Note{Title: "Other note"}, note}
 	ns := new(mockNS)
-	ns.findNotes = func(filter *NoteFilter, o, max int) ([]*Note, error) { return notes, nil }
+	ns.findNotes = func(filter *NoteFilter, o, max int) (*NoteList, error) { return &NoteList{Notes: notes}, nil }
+	ns.getNote = func(guid string) (*Note, error) {
+		return &Note{GUID: note.GUID, USN: note.USN, Updated: note.Updated}, nil
+	}
 	return ns
 }
+
+func TestNoteFilterSearchQuery(t *testing.T) {
+	assert := assert.New(t)
+	day := time.Date(2018, 5, 1, 12, 30, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	tests := []struct {
+		name     string
+		filter   *NoteFilter
+		expected string
+	}{
+		{"empty", &NoteFilter{}, ""},
+		{"only words", &NoteFilter{Words: "intitle:test"}, "intitle:test"},
+		{"created", &NoteFilter{CreatedAfter: day, CreatedBefore: day}, "created:20180501T123000Z -created:20180501T123000Z"},
+		{"updated with words", &NoteFilter{Words: "milk", UpdatedAfter: day, UpdatedBefore: day}, "milk updated:20180501T123000Z -updated:20180501T123000Z"},
+		{"reminders", &NoteFilter{Words: "milk", Reminders: true}, "milk reminderOrder:* -reminderDoneTime:*"},
+		{"checkboxes", &NoteFilter{Todos: true}, "todo:*"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(test.expected, test.filter.SearchQuery(), "Wrong search query")
+		})
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	assert := assert.New(t)
+	for name, expected := range map[string]int32{
+		"title":     NoteFilterOrderTitle,
+		"Created":   NoteFilterOrderCreated,
+		"updated":   NoteFilterOrderUpdated,
+		"relevance": NoteFilterOrderRelevance,
+	} {
+		order, err := ParseSortOrder(name)
+		assert.NoError(err, "Should not return an error for %s", name)
+		assert.Equal(expected, order, "Wrong order for %s", name)
+	}
+	_, err := ParseSortOrder("size")
+	assert.Equal(ErrUnknownSortOrder, err, "Wrong error returned")
+}
+
+func TestFindAllNotes(t *testing.T) {
+	assert := assert.New(t)
+	page := func(offset, count, total int) *NoteList {
+		list := &NoteList{Notes: []*Note{}, StartIndex: offset, TotalNotes: total}
+		for i := offset; i < offset+count && i < total; i++ {
+			list.Notes = append(list.Notes, &Note{GUID: strconv.Itoa(i)})
+		}
+		return list
+	}
+	t.Run("request all pages", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.findNotes = func(_ *NoteFilter, offset, count int) (*NoteList, error) { return page(offset, count, 7), nil }
+		var starts []int
+		var notes []*Note
+		err := FindAllNotes(ns, new(NoteFilter), 1, 3, func(list *NoteList) error {
+			starts = append(starts, list.StartIndex)
+			notes = append(notes, list.Notes...)
+			return nil
+		})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]int{1, 4}, starts, "Wrong pages returned")
+		assert.Len(notes, 6, "Wrong number of notes")
+		assert.Equal("6", notes[5].GUID, "Wrong last note")
+	})
+	t.Run("stop on empty page", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.findNotes = func(_ *NoteFilter, offset, count int) (*NoteList, error) { return page(offset, count, offset), nil }
+		called := false
+		err := FindAllNotes(ns, new(NoteFilter), 0, 3, func(*NoteList) error { called = true; return nil })
+		assert.NoError(err, "Should not return an error")
+		assert.False(called, "Should not be called for an empty page")
+	})
+	t.Run("return error from callback", func(t *testing.T) {
+		ns := new(mockNS)
+		ns.findNotes = func(_ *NoteFilter, offset, count int) (*NoteList, error) { return page(offset, count, 7), nil }
+		expectedError := errors.New("expected error")
+		calls := 0
+		err := FindAllNotes(ns, new(NoteFilter), 0, 3, func(*NoteList) error { calls++; return expectedError })
+		assert.Equal(expectedError, err, "Wrong error returned")
+		assert.Equal(1, calls, "Should stop after the error")
+	})
+	t.Run("return error from notestore", func(t *testing.T) {
+		ns := new(mockNS)
+		expectedError := errors.New("expected error")
+		ns.findNotes = func(*NoteFilter, int, int) (*NoteList, error) { return nil, expectedError }
+		err := FindAllNotes(ns, new(NoteFilter), 0, 3, func(*NoteList) error { return nil })
+		assert.Equal(expectedError, err, "Wrong error returned")
+	})
+}
+
+func TestSaveSearch(t *testing.T) {
+	assert := assert.New(t)
+	var saved []*Note
+	store := &mockStore{saveSearch: func(notes []*Note) error { saved = notes; return nil }}
+	notes := []*Note{&Note{Title: "Note1"}, &Note{Title: "Note2"}}
+	err := SaveSearch(store, 2, notes)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Note{nil, nil, notes[0], notes[1]}, saved, "Notes should be saved at their index")
+}
@@ -22,9 +22,9 @@ import "errors"
 var (
 	// ErrNoNotebookFound is returned if no matching notebook was found.
 	ErrNoNotebookFound = errors.New("no notebook found")
-	// ErrNoNotebookCached is returned when trying to update a notebook
-	// that hasn't been pulled from the server.
-	ErrNoNotebookCached = errors.New("no notebook found")
+	// ErrNotebookRestricted is returned if the change isn't allowed by the
+	// notebook's restrictions.
+	ErrNotebookRestricted = errors.New("the change is not allowed for the notebook")
 )
 
 // Notebook is a struct for the notebook.
@@ -35,21 +35,92 @@ type Notebook struct {
 	GUID string
 	// Stack is the stack that the notebook belongs too.
 	Stack string
+	// Default is true if it's the user's default notebook.
+	Default bool
+	// Published is true if the notebook is published to the public.
+	Published bool
+	// PublicURI is the part of the public URL that identifies the notebook
+	// if it's published.
+	PublicURI string
+	// Restrictions are the changes the user isn't allowed to make to the
+	// notebook. It's nil if there are no restrictions.
+	Restrictions *NotebookRestrictions
 }
 
-// UpdateNotebook updates the notebook.
-func UpdateNotebook(db Storager, ns NotestoreClient, name string, notebook *Notebook) error {
-	b, err := findNotebook(db, ns, name)
+// NotebookRestrictions are the restrictions set by the server on the changes the
+// user can make to a notebook.
+type NotebookRestrictions struct {
+	// NoUpdateNotebook is true if the notebook can't be changed.
+	NoUpdateNotebook bool
+	// NoExpungeNotebook is true if the notebook can't be removed.
+	NoExpungeNotebook bool
+	// NoSetDefaultNotebook is true if the notebook can't be set as the default notebook.
+	NoSetDefaultNotebook bool
+	// NoSetNotebookStack is true if the notebook's stack can't be changed.
+	NoSetNotebookStack bool
+	// NoPublishToPublic is true if the notebook can't be published.
+	NoPublishToPublic bool
+}
+
+// NotebookChanges are the changes made to a notebook by UpdateNotebook.
+// Only the set fields are changed.
+type NotebookChanges struct {
+	// Name is the new name of the notebook.
+	Name string
+	// Stack is the new stack of the notebook.
+	Stack string
+	// Default sets the notebook as the default notebook.
+	Default bool
+	// Publish publishes the notebook with PublicURI if true and unpublishes
+	// it if false.
+	Publish *bool
+	// PublicURI is the URI used when the notebook is published.
+	PublicURI string
+}
+
+// UpdateNotebook applies the changes to the notebook. The notebook is fetched from
+// the server before it's changed so the changes are applied to its current state.
+// ErrNotebookRestricted is returned if a change isn't allowed for the notebook.
+func UpdateNotebook(db Storager, ns NotestoreClient, name string, changes *NotebookChanges) error {
+	cached, err := findNotebook(db, ns, name)
 	if err != nil {
 		return err
 	}
-	if notebook.Name != "" {
-		b.Name = notebook.Name
+	b, err := ns.GetNotebook(cached.GUID)
+	if err != nil {
+		return err
+	}
+	r := b.Restrictions
+	if r == nil {
+		r = new(NotebookRestrictions)
+	}
+	if r.NoUpdateNotebook ||
+		(changes.Stack != "" && r.NoSetNotebookStack) ||
+		(changes.Default && r.NoSetDefaultNotebook) ||
+		(changes.Publish != nil && *changes.Publish && r.NoPublishToPublic) {
+		return ErrNotebookRestricted
+	}
+	if changes.Name != "" {
+		b.Name = changes.Name
+	}
+	if changes.Stack != "" {
+		b.Stack = changes.Stack
 	}
-	if notebook.Stack != "" {
-		b.Stack = notebook.Stack
+	if changes.Default {
+		b.Default = true
 	}
-	return ns.UpdateNotebook(b)
+	if changes.Publish != nil {
+		b.Published = *changes.Publish
+		if changes.PublicURI != "" {
+			b.PublicURI = changes.PublicURI
+		}
+	}
+	if err = ns.UpdateNotebook(b); err != nil {
+		return err
+	}
+	// Refresh the cache so it has the new name and stack.
+	_, err = GetNotebooks(db, ns, true)
+	return err
 }
 
 // FindNotebook gets the notebook matching with the name.
@@ -97,6 +168,20 @@ func GetNotebook(ns NotestoreClient, guid string) (*Notebook, error) {
 	return ns.GetNotebook(guid)
 }
 
+// DeleteNotebook permanently removes the notebook. The server moves the notes
+// in the notebook to the trash of the default notebook.
+func DeleteNotebook(db Storager, ns NotestoreClient, name string) error {
+	b, err := findNotebook(db, ns, name)
+	if err != nil {
+		return err
+	}
+	if err = ns.ExpungeNotebook(b.GUID); err != nil {
+		return err
+	}
+	_, err = GetNotebooks(db, ns, true)
+	return err
+}
+
 // CreateNotebook creates a new notebook.
 func CreateNotebook(ns NotestoreClient, notebook *Notebook, defaultNotebook bool) error {
 	return ns.CreateNotebook(notebook, defaultNotebook)
@@ -96,44 +96,72 @@ func TestGetNotebooks(t *testing.T) {
 func TestUpdateNotebook(t *testing.T) {
 	assert := assert.New(t)
 	newName, oldName, newStack, oldStack := "New Name", "Old Name", "New Stack", "Old Stack"
+	publish, unpublish := true, false
 	tests := []struct {
 		Name         string
-		Book         *Notebook
+		Changes      *NotebookChanges
 		ExpectedBook *Notebook
 	}{
-		{"Change name", &Notebook{Name: newName}, &Notebook{Name: newName, Stack: oldStack}},
-		{"Change stack", &Notebook{Stack: newStack}, &Notebook{Name: oldName, Stack: newStack}},
-		{"Change name and stack", &Notebook{Name: newName, Stack: newStack}, &Notebook{Name: newName, Stack: newStack}},
+		{"Change name", &NotebookChanges{Name: newName}, &Notebook{Name: newName, Stack: oldStack, Published: true}},
+		{"Change stack", &NotebookChanges{Stack: newStack}, &Notebook{Name: oldName, Stack: newStack, Published: true}},
+		{"Change name and stack", &NotebookChanges{Name: newName, Stack: newStack}, &Notebook{Name: newName, Stack: newStack, Published: true}},
+		{"Set default", &NotebookChanges{Default: true}, &Notebook{Name: oldName, Stack: oldStack, Default: true, Published: true}},
+		{"Publish", &NotebookChanges{Publish: &publish, PublicURI: "uri"}, &Notebook{Name: oldName, Stack: oldStack, Published: true, PublicURI: "uri"}},
+		{"Unpublish", &NotebookChanges{Publish: &unpublish}, &Notebook{Name: oldName, Stack: oldStack}},
 	}
 	store := &mockStore{
 		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
 		storeNotebookList: func(list *NotebookCacheList) error { return nil },
 	}
+	newNS := func(serverBook *Notebook) (*mockNS, **Notebook) {
+		var saved *Notebook
+		ns := &mockNS{
+			// The cached notebook is outdated, the server's version should be changed.
+			getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{&Notebook{Name: oldName, GUID: "GUID"}}, nil },
+			getNotebook: func(guid string) (*Notebook, error) {
+				b := *serverBook
+				return &b, nil
+			},
+			updateNotebook: func(book *Notebook) error { saved = book; return nil },
+		}
+		return ns, &saved
+	}
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
-			oldBook := &Notebook{Name: oldName, Stack: oldStack}
-			var saved *Notebook
-			ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{oldBook}, nil },
-				updateNotebook: func(book *Notebook) error { saved = book; return nil }}
-			err := UpdateNotebook(store, ns, oldName, test.Book)
+			ns, saved := newNS(&Notebook{Name: oldName, Stack: oldStack, Published: true})
+			err := UpdateNotebook(store, ns, oldName, test.Changes)
 			assert.NoError(err, "Should not return an error")
-			assert.Equal(test.ExpectedBook, saved, "Saved notebook doesn't match")
+			assert.Equal(test.ExpectedBook, *saved, "Saved notebook doesn't match")
 		})
 	}
+	t.Run("return error if restricted", func(t *testing.T) {
+		restrictions := []struct {
+			Restrictions *NotebookRestrictions
+			Changes      *NotebookChanges
+		}{
+			{&NotebookRestrictions{NoUpdateNotebook: true}, &NotebookChanges{Name: newName}},
+			{&NotebookRestrictions{NoSetNotebookStack: true}, &NotebookChanges{Stack: newStack}},
+			{&NotebookRestrictions{NoSetDefaultNotebook: true}, &NotebookChanges{Default: true}},
+			{&NotebookRestrictions{NoPublishToPublic: true}, &NotebookChanges{Publish: &publish}},
+		}
+		for _, r := range restrictions {
+			ns, saved := newNS(&Notebook{Name: oldName, Restrictions: r.Restrictions})
+			err := UpdateNotebook(store, ns, oldName, r.Changes)
+			assert.Equal(ErrNotebookRestricted, err, "Wrong error returned for %+v", r.Restrictions)
+			assert.Nil(*saved, "Notebook should not be updated for %+v", r.Restrictions)
+		}
+	})
 	t.Run("return error from UpdateNotebook", func(t *testing.T) {
 		expectedErr := errors.New("expected error")
-		oldBook := &Notebook{Name: oldName, Stack: oldStack}
-		ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{oldBook}, nil },
-			updateNotebook: func(book *Notebook) error { return expectedErr }}
-		err := UpdateNotebook(store, ns, oldName, &Notebook{})
+		ns, _ := newNS(&Notebook{Name: oldName, Stack: oldStack})
+		ns.updateNotebook = func(book *Notebook) error { return expectedErr }
+		err := UpdateNotebook(store, ns, oldName, &NotebookChanges{})
 		assert.Error(err, "Should return an error")
 		assert.Equal(expectedErr, err, "Wrong error returned")
 	})
 	t.Run("return error when no notebook found", func(t *testing.T) {
-		oldBook := &Notebook{Name: oldName, Stack: oldStack}
-		ns := &mockNS{getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{oldBook}, nil },
-			updateNotebook: func(book *Notebook) error { return nil }}
-		err := UpdateNotebook(store, ns, newName, &Notebook{})
+		ns, _ := newNS(&Notebook{Name: oldName, Stack: oldStack})
+		err := UpdateNotebook(store, ns, newName, &NotebookChanges{})
 		assert.Error(err, "Should return an error")
 		assert.Equal(ErrNoNotebookFound, err, "Wrong error returned")
 	})
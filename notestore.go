@@ -3,7 +3,7 @@ package clinote
 // NotestoreClient is the interface for the notestore.
 type NotestoreClient interface {
 	// FindNotes searches for the notes based on the filter.
-	FindNotes(filter *NoteFilter, offset, count int) ([]*Note, error)
+	FindNotes(filter *NoteFilter, offset, count int) (*NoteList, error)
 	// GetAllNotebooks returns all the of users notebooks.
 	GetAllNotebooks() ([]*Notebook, error)
 	// GetNotebook
@@ -12,12 +12,42 @@ type NotestoreClient interface {
 	CreateNotebook(b *Notebook, defaultNotebook bool) error
 	// GetNoteContent gets the note's content from the notestore.
 	GetNoteContent(guid string) (string, error)
+	// GetNote returns the note's metadata. If the note doesn't exist,
+	// ErrNoNoteFound is returned.
+	GetNote(guid string) (*Note, error)
 	// UpdateNote update's the note.
 	UpdateNote(note *Note) error
 	// DeleteNote removes a note from the user's notebook.
 	DeleteNote(guid string) error
+	// ExpungeNote permanently removes the note from the server.
+	ExpungeNote(guid string) error
+	// ExpungeInactiveNotes permanently removes all the notes in the trash.
+	ExpungeInactiveNotes() error
 	// CreateNote creates a new note on the server.
 	CreateNote(note *Note) error
+	// ExpungeNotebook permanently removes the notebook from the server.
+	ExpungeNotebook(guid string) error
 	// UpdateNotebook updates the notebook on the server.
 	UpdateNotebook(book *Notebook) error
+	// ListTags returns all the user's tags.
+	ListTags() ([]*Tag, error)
+	// CreateTag creates a new tag on the server.
+	CreateTag(tag *Tag) error
+	// UpdateTag updates the tag on the server.
+	UpdateTag(tag *Tag) error
+	// ExpungeTag permanently removes the tag from the server.
+	ExpungeTag(guid string) error
+	// GetResource returns the resource's metadata without the data.
+	GetResource(guid string) (*Resource, error)
+	// GetResourceData returns the resource's data.
+	GetResourceData(guid string) ([]byte, error)
+	// GetSyncState returns the sync state of the user's account.
+	GetSyncState() (*SyncState, error)
+	// GetSyncChunk returns the changes after the update sequence number.
+	GetSyncChunk(afterUSN int32, maxEntries int) (*SyncChunk, error)
+	// ListNoteVersions returns the prior versions of the note saved by the server.
+	ListNoteVersions(guid string) ([]*NoteVersion, error)
+	// GetNoteVersion returns the prior version of the note with the content
+	// and the resource data.
+	GetNoteVersion(guid string, usn int32) (*Note, error)
 }
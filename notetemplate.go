@@ -0,0 +1,168 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// templateFolder is the folder in the config folder with the note templates.
+	templateFolder = "templates"
+	// templateExtension is the file extension of the note templates.
+	templateExtension = ".md"
+)
+
+var (
+	// ErrNoTemplateFound is returned if the note template doesn't exist.
+	ErrNoTemplateFound = errors.New("no template found")
+	// ErrInvalidTemplateVar is returned if a template variable isn't given as name=value.
+	ErrInvalidTemplateVar = errors.New("template variables have to be given as name=value")
+)
+
+// TemplateData is the data the note templates are executed with.
+type TemplateData struct {
+	// Date is the current date, for example 2018-05-01.
+	Date string
+	// Time is the current time, for example 15:04.
+	Time string
+	// Week is the ISO 8601 week number.
+	Week int
+	// Year is the current year.
+	Year int
+	// Now is the current time.
+	Now  time.Time
+	vars map[string]string
+}
+
+// Var returns the value of the template variable. An error is returned if
+// the variable isn't set.
+func (d *TemplateData) Var(name string) (string, error) {
+	v, ok := d.vars[name]
+	if !ok {
+		return "", fmt.Errorf("template variable %q is not set", name)
+	}
+	return v, nil
+}
+
+// NewTemplateData returns the template data for the time with the variables.
+func NewTemplateData(now time.Time, vars map[string]string) *TemplateData {
+	_, week := now.ISOWeek()
+	return &TemplateData{
+		Date: now.Format(timeFormat),
+		Time: now.Format("15:04"),
+		Week: week,
+		Year: now.Year(),
+		Now:  now,
+		vars: vars,
+	}
+}
+
+// ParseTemplateVars parses the template variables given as name=value.
+func ParseTemplateVars(a []string) (map[string]string, error) {
+	vars := make(map[string]string, len(a))
+	for _, s := range a {
+		i := strings.Index(s, "=")
+		if i < 1 {
+			return nil, ErrInvalidTemplateVar
+		}
+		vars[s[:i]] = s[i+1:]
+	}
+	return vars, nil
+}
+
+// TemplateFolder returns the folder with the note templates.
+func TemplateFolder(cfg Configuration) string {
+	return filepath.Join(cfg.GetConfigFolder(), templateFolder)
+}
+
+// GetTemplates returns the names of the note templates, sorted by name.
+func GetTemplates(cfg Configuration) ([]string, error) {
+	files, err := ioutil.ReadDir(TemplateFolder(cfg))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == templateExtension {
+			names = append(names, strings.TrimSuffix(f.Name(), templateExtension))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// NewNoteFromTemplate returns a new note from the template with the name in the
+// template folder. The template is a Markdown note that can start with the same
+// header as the note in the editor. It's executed as a Go template with the
+// TemplateData before it's parsed, for example:
+//
+//	---
+//	title: Standup {{.Date}}
+//	notebook: Meetings
+//	tags: standup, {{.Var "project"}}
+//	---
+//	# Week {{.Week}}
+//
+// The notebook in the header is looked up in the user's notebooks.
+func NewNoteFromTemplate(client *Client, name string, data *TemplateData) (*Note, error) {
+	fp := filepath.Join(TemplateFolder(client.Config), name)
+	if filepath.Ext(fp) != templateExtension {
+		fp += templateExtension
+	}
+	text, err := ioutil.ReadFile(fp)
+	if os.IsNotExist(err) {
+		return nil, ErrNoTemplateFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	note := new(Note)
+	if err = parseInput(buf, note, DefaultNoteOption); err != nil {
+		return nil, err
+	}
+	if note.Notebook != nil && note.Notebook.Name == "" {
+		note.Notebook = nil
+	}
+	if note.Notebook != nil {
+		if note.Notebook, err = FindNotebook(client.Store, client.NoteStore, note.Notebook.Name); err != nil {
+			return nil, err
+		}
+	}
+	return note, nil
+}
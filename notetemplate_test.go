@@ -0,0 +1,102 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// folderConfig is a configuration with the config folder in the directory.
+type folderConfig struct {
+	DefaultConfig
+	dir string
+}
+
+func (c *folderConfig) GetConfigFolder() string {
+	return c.dir
+}
+
+func TestNoteTemplates(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := &folderConfig{dir: dir}
+	store := &mockStore{
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Timestamp: time.Now(), Notebooks: []*Notebook{&Notebook{GUID: "NB1", Name: "Meetings"}}, Limit: time.Hour}, nil
+		},
+	}
+	client := &Client{Config: cfg, Store: store, NoteStore: new(mockNS)}
+	data := NewTemplateData(time.Date(2018, 5, 1, 9, 30, 0, 0, time.UTC), map[string]string{"project": "clinote"})
+
+	t.Run("no templates", func(t *testing.T) {
+		names, err := GetTemplates(cfg)
+		assert.NoError(err, "Should not return an error")
+		assert.Len(names, 0, "No templates should be returned")
+		_, err = NewNoteFromTemplate(client, "standup", data)
+		assert.Equal(ErrNoTemplateFound, err, "Wrong error returned")
+	})
+	if err = os.MkdirAll(TemplateFolder(cfg), 0700); err != nil {
+		t.Fatal(err)
+	}
+	standup := "---\ntitle: Standup {{.Date}}\nnotebook: Meetings\ntags: standup, {{.Var \"project\"}}\n---\n# Week {{.Week}}, {{.Time}}\n"
+	files := map[string]string{"standup.md": standup, "missing.md": "{{.Var \"owner\"}}", "notes.txt": ""}
+	for name, text := range files {
+		assert.NoError(ioutil.WriteFile(filepath.Join(TemplateFolder(cfg), name), []byte(text), 0600), "Should write the template")
+	}
+
+	t.Run("list templates", func(t *testing.T) {
+		names, err := GetTemplates(cfg)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"missing", "standup"}, names, "Wrong templates")
+	})
+	t.Run("new note", func(t *testing.T) {
+		n, err := NewNoteFromTemplate(client, "standup", data)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Standup 2018-05-01", n.Title, "Wrong title")
+		assert.Equal("NB1", n.Notebook.GUID, "Notebook should be looked up")
+		assert.Equal([]string{"standup", "clinote"}, tagNames(n.Tags), "Wrong tags")
+		assert.Equal("# Week 18, 09:30", n.MD, "Wrong content")
+	})
+	t.Run("missing variable", func(t *testing.T) {
+		_, err := NewNoteFromTemplate(client, "missing.md", data)
+		if assert.Error(err, "Should return an error") {
+			assert.Contains(err.Error(), "\"owner\" is not set", "Wrong error returned")
+		}
+	})
+}
+
+func TestParseTemplateVars(t *testing.T) {
+	assert := assert.New(t)
+	vars, err := ParseTemplateVars([]string{"project=foo", "query=a=b", "empty="})
+	assert.NoError(err, "Should not return an error")
+	assert.Equal(map[string]string{"project": "foo", "query": "a=b", "empty": ""}, vars, "Wrong variables")
+	_, err = ParseTemplateVars([]string{"=foo"})
+	assert.Equal(ErrInvalidTemplateVar, err, "Wrong error returned")
+	_, err = ParseTemplateVars([]string{"project"})
+	assert.Equal(ErrInvalidTemplateVar, err, "Wrong error returned")
+}
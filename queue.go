@@ -0,0 +1,167 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrChangeQueued is returned if the server couldn't be reached and the change
+// has been queued until the next sync.
+var ErrChangeQueued = errors.New("the server couldn't be reached, the change will be pushed on the next sync")
+
+// ChangeType is the type of change made to a note.
+type ChangeType uint8
+
+func (c ChangeType) String() string {
+	return changeTypeStringMapper[c]
+}
+
+const (
+	// ChangeCreate is a new note.
+	ChangeCreate ChangeType = iota
+	// ChangeUpdate is a change to the note's content or metadata.
+	ChangeUpdate
+	// ChangeMove is a move of the note to another notebook.
+	ChangeMove
+	// ChangeDelete is a move of the note to the trash.
+	ChangeDelete
+)
+
+var changeTypeStringMapper = []string{"Create", "Update", "Move", "Delete"}
+
+// ConflictPolicy defines how changes that conflict with changes on the server are handled.
+type ConflictPolicy uint8
+
+const (
+	// KeepConflicts leaves the conflicting changes in the queue.
+	KeepConflicts ConflictPolicy = iota
+	// OverwriteConflicts pushes the changes and overwrites the changes on the server.
+	OverwriteConflicts
+	// DiscardConflicts removes the conflicting changes from the queue.
+	DiscardConflicts
+)
+
+// Change is a change to a note that hasn't been pushed to the server.
+type Change struct {
+	// ID is the change's position in the queue. It's set by the storage.
+	ID uint64
+	// Type is the type of change.
+	Type ChangeType
+	// Note is the changed note. The note's USN is the update sequence
+	// number the change was based on.
+	Note *Note
+	// Queued is the time in milliseconds when the change was made.
+	Queued int64
+}
+
+// ReplayResult is the result of pushing the queued changes.
+type ReplayResult struct {
+	// Pushed is the number of changes pushed to the server.
+	Pushed int
+	// Conflicts are the changes to notes that have been changed on the server
+	// after the change was made.
+	Conflicts []*Change
+}
+
+// GetQueuedChanges returns the changes that haven't been pushed to the server.
+func GetQueuedChanges(db Storager) ([]*Change, error) {
+	return db.GetQueuedChanges()
+}
+
+// ReplayChanges pushes the queued changes to the server in the order they were made.
+// A change conflicts if the note has been changed on the server since the change was
+// made. Conflicts are handled according to the policy. If pushing a change fails,
+// the change and the changes after it are kept in the queue.
+func ReplayChanges(db Storager, ns NotestoreClient, policy ConflictPolicy) (*ReplayResult, error) {
+	changes, err := db.GetQueuedChanges()
+	if err != nil {
+		return nil, err
+	}
+	result := &ReplayResult{Conflicts: make([]*Change, 0)}
+	// Notes that have been pushed are not checked again since the later
+	// changes are based on the pushed change.
+	pushed := make(map[string]bool)
+	for _, c := range changes {
+		if c.Type != ChangeCreate && !pushed[c.Note.GUID] {
+			conflict, err := isConflict(ns, c)
+			if err != nil {
+				return result, err
+			}
+			if conflict {
+				result.Conflicts = append(result.Conflicts, c)
+				if policy == KeepConflicts {
+					continue
+				}
+				if policy == DiscardConflicts {
+					if err = db.RemoveQueuedChange(c.ID); err != nil {
+						return result, err
+					}
+					continue
+				}
+			}
+		}
+		if err = pushChange(ns, c); err != nil {
+			return result, err
+		}
+		if err = db.RemoveQueuedChange(c.ID); err != nil {
+			return result, err
+		}
+		pushed[c.Note.GUID] = true
+		result.Pushed++
+	}
+	return result, nil
+}
+
+// isConflict returns true if the note on the server has been changed after
+// the change was made.
+func isConflict(ns NotestoreClient, c *Change) (bool, error) {
+	// Without an update sequence number, the change can't be checked.
+	if c.Note.USN == 0 {
+		return false, nil
+	}
+	n, err := ns.GetNote(c.Note.GUID)
+	if err != nil {
+		return false, err
+	}
+	return n.USN != c.Note.USN, nil
+}
+
+func pushChange(ns NotestoreClient, c *Change) error {
+	switch c.Type {
+	case ChangeCreate:
+		return ns.CreateNote(c.Note)
+	case ChangeDelete:
+		return ns.DeleteNote(c.Note.GUID)
+	default:
+		return ns.UpdateNote(c.Note)
+	}
+}
+
+// queueChange adds the change to the queue. A copy of the note is queued
+// so later changes to the note doesn't alter the queued change.
+func queueChange(db Storager, t ChangeType, n *Note) error {
+	note := *n
+	c := &Change{
+		Type:   t,
+		Note:   &note,
+		Queued: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	return db.QueueChange(c)
+}
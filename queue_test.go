@@ -0,0 +1,120 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayChanges(t *testing.T) {
+	assert := assert.New(t)
+	newChanges := func() []*Change {
+		return []*Change{
+			&Change{ID: 1, Type: ChangeCreate, Note: &Note{Title: "New"}},
+			&Change{ID: 2, Type: ChangeUpdate, Note: &Note{GUID: "A", USN: 5}},
+			&Change{ID: 3, Type: ChangeMove, Note: &Note{GUID: "A", USN: 5}},
+			&Change{ID: 4, Type: ChangeUpdate, Note: &Note{GUID: "B", USN: 2}},
+			&Change{ID: 5, Type: ChangeDelete, Note: &Note{GUID: "C", USN: 7}},
+		}
+	}
+	// B has been changed on the server.
+	serverUSN := map[string]int32{"A": 5, "B": 3, "C": 7}
+	setup := func() (*mockStore, *mockNS, *[]uint64, *[]string) {
+		removed := make([]uint64, 0)
+		pushed := make([]string, 0)
+		store := &mockStore{
+			getQueuedChanges:   func() ([]*Change, error) { return newChanges(), nil },
+			removeQueuedChange: func(id uint64) error { removed = append(removed, id); return nil },
+		}
+		ns := &mockNS{
+			getNote: func(guid string) (*Note, error) {
+				// Simulate that the USN changes when the note is updated.
+				usn := serverUSN[guid]
+				for _, g := range pushed {
+					if g == "update "+guid {
+						usn++
+					}
+				}
+				return &Note{GUID: guid, USN: usn}, nil
+			},
+			createNote: func(n *Note) error { pushed = append(pushed, "create "+n.Title); return nil },
+			updateNote: func(n *Note) error { pushed = append(pushed, "update "+n.GUID); return nil },
+			deleteNote: func(guid string) error { pushed = append(pushed, "delete "+guid); return nil },
+		}
+		return store, ns, &removed, &pushed
+	}
+
+	t.Run("keep conflicts", func(t *testing.T) {
+		store, ns, removed, pushed := setup()
+		result, err := ReplayChanges(store, ns, KeepConflicts)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(4, result.Pushed, "Wrong number of pushed changes")
+		assert.Equal([]string{"create New", "update A", "update A", "delete C"}, *pushed, "Wrong changes pushed")
+		assert.Equal([]uint64{1, 2, 3, 5}, *removed, "Pushed changes should be removed")
+		if assert.Len(result.Conflicts, 1, "Wrong number of conflicts") {
+			assert.Equal(uint64(4), result.Conflicts[0].ID, "Wrong conflict")
+		}
+	})
+	t.Run("overwrite conflicts", func(t *testing.T) {
+		store, ns, removed, pushed := setup()
+		result, err := ReplayChanges(store, ns, OverwriteConflicts)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(5, result.Pushed, "Wrong number of pushed changes")
+		assert.Contains(*pushed, "update B", "Conflict should be pushed")
+		assert.Equal([]uint64{1, 2, 3, 4, 5}, *removed, "All changes should be removed")
+	})
+	t.Run("discard conflicts", func(t *testing.T) {
+		store, ns, removed, pushed := setup()
+		result, err := ReplayChanges(store, ns, DiscardConflicts)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(4, result.Pushed, "Wrong number of pushed changes")
+		assert.NotContains(*pushed, "update B", "Conflict should not be pushed")
+		assert.Equal([]uint64{1, 2, 3, 4, 5}, *removed, "All changes should be removed")
+	})
+	t.Run("stop on error", func(t *testing.T) {
+		store, ns, removed, _ := setup()
+		ns.updateNote = func(n *Note) error { return ErrOffline }
+		result, err := ReplayChanges(store, ns, KeepConflicts)
+		assert.Equal(ErrOffline, err, "Wrong error returned")
+		assert.Equal(1, result.Pushed, "Only the first change should be pushed")
+		assert.Equal([]uint64{1}, *removed, "Failed changes should be kept")
+	})
+	t.Run("error from GetNote", func(t *testing.T) {
+		store, ns, _, _ := setup()
+		expectedErr := errors.New("expected")
+		ns.getNote = func(string) (*Note, error) { return nil, expectedErr }
+		_, err := ReplayChanges(store, ns, KeepConflicts)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestQueueChange(t *testing.T) {
+	assert := assert.New(t)
+	var queued *Change
+	store := &mockStore{queueChange: func(c *Change) error { queued = c; return nil }}
+	n := &Note{GUID: "GUID", Title: "Title"}
+	err := queueChange(store, ChangeUpdate, n)
+	assert.NoError(err, "Should not return an error")
+	n.Title = "Changed"
+	assert.Equal("Title", queued.Note.Title, "Queued note should be a copy")
+	assert.NotEqual(int64(0), queued.Queued, "Queue time should be set")
+	assert.Equal("Update", queued.Type.String(), "Wrong type name")
+}
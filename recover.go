@@ -0,0 +1,108 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNoRecoveryPointFound is returned if no matching recovery point was found.
+var ErrNoRecoveryPointFound = errors.New("no recovery point found")
+
+// RecoveryPoint is a saved copy of a note that failed to be saved to the notestore.
+type RecoveryPoint struct {
+	// ID identifies the recovery point. It's created from the note's GUID and the
+	// time the recovery point was saved.
+	ID string
+	// Note is the note that failed to be saved.
+	Note *Note
+	// Saved is the time in milliseconds when the recovery point was saved.
+	Saved int64
+	// Raw is true if the note's content was edited as ENML. Otherwise the
+	// Markdown content is the edited content.
+	Raw bool
+}
+
+// GetRecoveryPoints returns all the recovery points, the oldest first.
+func GetRecoveryPoints(db Storager) ([]*RecoveryPoint, error) {
+	return db.GetNoteRecoveryPoints()
+}
+
+// GetRecoveryPoint returns the recovery point with the index in the list returned
+// by GetRecoveryPoints. The index starts at 1.
+func GetRecoveryPoint(db Storager, index int) (*RecoveryPoint, error) {
+	rps, err := db.GetNoteRecoveryPoints()
+	if err != nil {
+		return nil, err
+	}
+	if index < 1 || index > len(rps) {
+		return nil, ErrNoRecoveryPointFound
+	}
+	return rps[index-1], nil
+}
+
+// ApplyRecoveryPoint saves the recovery point's note to the notestore. The content
+// is encoded from the Markdown or the ENML depending on how the note was edited.
+// A note that failed to be created is created. Once the note has been saved, the
+// recovery point is removed.
+func ApplyRecoveryPoint(db Storager, ns NotestoreClient, index int) error {
+	rp, err := GetRecoveryPoint(db, index)
+	if err != nil {
+		return err
+	}
+	n := rp.Note
+	if rp.Raw {
+		if n.Body, err = recoveredContent(n.Body); err != nil {
+			return err
+		}
+	}
+	// A note that failed to be created doesn't have a GUID.
+	if n.GUID == "" {
+		err = SaveNewNote(ns, n, rp.Raw)
+	} else {
+		err = saveChanges(ns, n, true, rp.Raw)
+	}
+	if err != nil {
+		return err
+	}
+	return db.RemoveNoteRecoveryPoint(rp.ID)
+}
+
+// recoveredContent returns the content of the en-note element if the body is
+// an ENML document, which it is if the note failed to save after the content
+// was encoded. Otherwise the body is the edited content and returned as is.
+func recoveredContent(body string) (string, error) {
+	if !strings.Contains(body, "<en-note") {
+		return body, nil
+	}
+	n := new(Note)
+	if err := decodeXML(body, n); err != nil {
+		return "", err
+	}
+	return n.Body, nil
+}
+
+// DropRecoveryPoint removes the recovery point.
+func DropRecoveryPoint(db Storager, index int) error {
+	rp, err := GetRecoveryPoint(db, index)
+	if err != nil {
+		return err
+	}
+	return db.RemoveNoteRecoveryPoint(rp.ID)
+}
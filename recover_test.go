@@ -0,0 +1,138 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryPoints(t *testing.T) {
+	assert := assert.New(t)
+	rps := []*RecoveryPoint{
+		&RecoveryPoint{ID: "GUID1_1", Note: &Note{GUID: "GUID1", Title: "Note 1"}, Saved: 1},
+		&RecoveryPoint{ID: "GUID2_2", Note: &Note{GUID: "GUID2", Title: "Note 2"}, Saved: 2},
+	}
+	setup := func() (*mockStore, *[]string) {
+		removed := make([]string, 0)
+		store := new(mockStore)
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) { return rps, nil }
+		store.removeNoteRecoveryPoint = func(id string) error {
+			removed = append(removed, id)
+			return nil
+		}
+		return store, &removed
+	}
+
+	t.Run("get", func(t *testing.T) {
+		store, _ := setup()
+		rp, err := GetRecoveryPoint(store, 2)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(rps[1], rp, "Wrong recovery point returned")
+	})
+
+	t.Run("get_out_of_range", func(t *testing.T) {
+		store, _ := setup()
+		for _, i := range []int{0, 3, -1} {
+			_, err := GetRecoveryPoint(store, i)
+			assert.Equal(ErrNoRecoveryPointFound, err, "Wrong error returned for %d", i)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		store, removed := setup()
+		ns := new(mockNS)
+		var saved *Note
+		ns.updateNote = func(n *Note) error {
+			saved = n
+			return nil
+		}
+		err := ApplyRecoveryPoint(store, ns, 1)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(rps[0].Note, saved, "Wrong note saved")
+		assert.Equal([]string{"GUID1_1"}, *removed, "Recovery point should be removed")
+	})
+
+	t.Run("apply_encodes_content", func(t *testing.T) {
+		tests := []struct {
+			name string
+			rp   *RecoveryPoint
+		}{
+			{"markdown", &RecoveryPoint{ID: "MD", Note: &Note{GUID: "GUID", Body: enmlDocument("<p>Stale</p>"), MD: "Edited"}}},
+			{"raw", &RecoveryPoint{ID: "Raw", Note: &Note{GUID: "GUID", Body: "<p>Edited</p>", MD: "Stale"}, Raw: true}},
+			{"raw document", &RecoveryPoint{ID: "Doc", Note: &Note{GUID: "GUID", Body: enmlDocument("<p>Edited</p>"), MD: "Stale"}, Raw: true}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				store, removed := setup()
+				store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) { return []*RecoveryPoint{test.rp}, nil }
+				ns := new(mockNS)
+				var saved *Note
+				ns.updateNote = func(n *Note) error {
+					saved = n
+					return nil
+				}
+				err := ApplyRecoveryPoint(store, ns, 1)
+				assert.NoError(err, "Should not return an error")
+				assert.Contains(saved.Body, "Edited", "Edited content should be saved")
+				assert.NotContains(saved.Body, "Stale", "Stale content should not be saved")
+				assert.Equal(1, strings.Count(saved.Body, "<en-note>"), "Content should be a single ENML document")
+				assert.Equal([]string{test.rp.ID}, *removed, "Recovery point should be removed")
+			})
+		}
+	})
+
+	t.Run("apply_creates_new_note", func(t *testing.T) {
+		store, removed := setup()
+		store.getNoteRecoveryPoints = func() ([]*RecoveryPoint, error) {
+			return []*RecoveryPoint{&RecoveryPoint{ID: "_1", Note: &Note{Title: "New", MD: "Typed"}}}, nil
+		}
+		ns := new(mockNS)
+		var created *Note
+		ns.createNote = func(n *Note) error {
+			created = n
+			return nil
+		}
+		err := ApplyRecoveryPoint(store, ns, 1)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(created, "Note should be created") {
+			assert.Contains(created.Body, "Typed", "Content should be saved")
+		}
+		assert.Equal([]string{"_1"}, *removed, "Recovery point should be removed")
+	})
+
+	t.Run("apply_keeps_recovery_point_on_error", func(t *testing.T) {
+		store, removed := setup()
+		ns := new(mockNS)
+		expectedErr := errors.New("expected error")
+		ns.updateNote = func(*Note) error { return expectedErr }
+		err := ApplyRecoveryPoint(store, ns, 1)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+		assert.Empty(*removed, "Recovery point should not be removed")
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		store, removed := setup()
+		err := DropRecoveryPoint(store, 2)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]string{"GUID2_2"}, *removed, "Wrong recovery point removed")
+	})
+}
@@ -0,0 +1,180 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2016-2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReminderDue limits the reminders listed by when they are due.
+type ReminderDue int
+
+const (
+	// ReminderDueAll includes all the open reminders.
+	ReminderDueAll ReminderDue = iota
+	// ReminderDueToday includes the reminders due today.
+	ReminderDueToday
+	// ReminderDueOverdue includes the reminders that are past their due time.
+	ReminderDueOverdue
+)
+
+// ReminderTimeFormat is the layout of the reminder times. The time of day
+// can be left out when a reminder is set.
+const ReminderTimeFormat = "2006-01-02 15:04"
+
+// reminderPageSize is the number of notes requested per page when the
+// reminders are listed.
+const reminderPageSize = 100
+
+var reminderDueNames = map[string]ReminderDue{
+	"":        ReminderDueAll,
+	"all":     ReminderDueAll,
+	"today":   ReminderDueToday,
+	"overdue": ReminderDueOverdue,
+}
+
+var (
+	// ErrNoReminder is returned if the note doesn't have a reminder.
+	ErrNoReminder = errors.New("the note doesn't have a reminder")
+	// ErrUnknownReminderDue is returned if the due filter name is not known.
+	ErrUnknownReminderDue = errors.New("unknown due filter")
+	// ErrInvalidReminderTime is returned if the reminder time can't be parsed.
+	ErrInvalidReminderTime = errors.New("invalid reminder time, expected \"" + ReminderTimeFormat + "\" or \"" + timeFormat + "\"")
+)
+
+// ParseReminderTime parses the time in ReminderTimeFormat, or only the date in
+// which case the reminder is due at the start of the day, in the location.
+func ParseReminderTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{ReminderTimeFormat, timeFormat} {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidReminderTime
+}
+
+// ParseReminderDue returns the ReminderDue value for the name. The valid names
+// are all, today and overdue.
+func ParseReminderDue(name string) (ReminderDue, error) {
+	due, ok := reminderDueNames[strings.ToLower(name)]
+	if !ok {
+		return ReminderDueAll, ErrUnknownReminderDue
+	}
+	return due, nil
+}
+
+// SetReminder sets a reminder on the note that is due at the time. If the
+// reminder has been completed, it's reopened.
+func SetReminder(db Storager, ns NotestoreClient, title string, at time.Time) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	if n.Attributes == nil {
+		n.Attributes = new(NoteAttributes)
+	}
+	if n.Attributes.ReminderOrder == 0 {
+		n.Attributes.ReminderOrder = toMilliseconds(time.Now())
+	}
+	n.Attributes.ReminderTime = toMilliseconds(at)
+	n.Attributes.ReminderDoneTime = 0
+	return saveChanges(ns, n, false, false)
+}
+
+// CompleteReminder marks the note's reminder as done.
+func CompleteReminder(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	if n.Attributes == nil || n.Attributes.ReminderOrder == 0 {
+		return ErrNoReminder
+	}
+	n.Attributes.ReminderDoneTime = toMilliseconds(time.Now())
+	return saveChanges(ns, n, false, false)
+}
+
+// ClearReminder removes the reminder from the note.
+func ClearReminder(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	if n.Attributes == nil || n.Attributes.ReminderOrder == 0 {
+		return ErrNoReminder
+	}
+	n.Attributes.ReminderOrder = 0
+	n.Attributes.ReminderTime = 0
+	n.Attributes.ReminderDoneTime = 0
+	return saveChanges(ns, n, false, false)
+}
+
+// GetReminders returns the notes with open reminders that match the due filter
+// relative to now. The notes are sorted by their due time. Reminders without a
+// due time are only included if all the reminders are requested and are
+// listed last.
+func GetReminders(ns NotestoreClient, due ReminderDue, now time.Time) ([]*Note, error) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dayStart, dayEnd := toMilliseconds(start), toMilliseconds(start.AddDate(0, 0, 1))
+	ts := toMilliseconds(now)
+	var notes []*Note
+	filter := &NoteFilter{Reminders: true}
+	err := FindAllNotes(ns, filter, 0, reminderPageSize, func(list *NoteList) error {
+		for _, n := range list.Notes {
+			if !hasOpenReminder(n) {
+				continue
+			}
+			at := n.Attributes.ReminderTime
+			switch {
+			case due == ReminderDueToday && (at < dayStart || at >= dayEnd):
+				continue
+			case due == ReminderDueOverdue && (at == 0 || at >= ts):
+				continue
+			}
+			notes = append(notes, n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		a, b := notes[i].Attributes, notes[j].Attributes
+		if a.ReminderTime == b.ReminderTime {
+			return a.ReminderOrder < b.ReminderOrder
+		}
+		if a.ReminderTime == 0 || b.ReminderTime == 0 {
+			return b.ReminderTime == 0
+		}
+		return a.ReminderTime < b.ReminderTime
+	})
+	return notes, nil
+}
+
+// hasOpenReminder returns true if the note has a reminder that isn't done.
+func hasOpenReminder(n *Note) bool {
+	return n.Attributes != nil && n.Attributes.ReminderOrder != 0 && n.Attributes.ReminderDoneTime == 0
+}
+
+// toMilliseconds returns the time in milliseconds since the Unix epoch.
+func toMilliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,145 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminder(t *testing.T) {
+	assert := assert.New(t)
+	at := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	newNS := func(attrs *NoteAttributes, saved **Note) *mockNS {
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, offset, count int) (*NoteList, error) {
+			n := &Note{Title: "Note", GUID: "GUID", Notebook: &Notebook{GUID: "Notebook"}, Attributes: attrs}
+			return &NoteList{Notes: []*Note{n}, TotalNotes: 1}, nil
+		}
+		ns.updateNote = func(n *Note) error { *saved = n; return nil }
+		return ns
+	}
+	store := &mockStore{getSearch: func() ([]*Note, error) { return nil, nil }}
+
+	t.Run("set reminder", func(t *testing.T) {
+		var saved *Note
+		err := SetReminder(store, newNS(nil, &saved), "Note", at)
+		assert.NoError(err, "Should not return an error")
+		if assert.NotNil(saved.Attributes, "Attributes should be set") {
+			assert.NotEqual(int64(0), saved.Attributes.ReminderOrder, "Reminder order should be set")
+			assert.Equal(toMilliseconds(at), saved.Attributes.ReminderTime, "Wrong reminder time")
+		}
+		assert.Equal("", saved.Body, "Content should not be updated")
+	})
+	t.Run("reopen done reminder", func(t *testing.T) {
+		var saved *Note
+		attrs := &NoteAttributes{ReminderOrder: 10, ReminderTime: 20, ReminderDoneTime: 30}
+		err := SetReminder(store, newNS(attrs, &saved), "Note", at)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(int64(10), saved.Attributes.ReminderOrder, "Reminder order should be kept")
+		assert.Equal(int64(0), saved.Attributes.ReminderDoneTime, "Done time should be cleared")
+	})
+	t.Run("complete reminder", func(t *testing.T) {
+		var saved *Note
+		err := CompleteReminder(store, newNS(&NoteAttributes{ReminderOrder: 10, ReminderTime: 20}, &saved), "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.NotEqual(int64(0), saved.Attributes.ReminderDoneTime, "Done time should be set")
+		assert.Equal(int64(20), saved.Attributes.ReminderTime, "Reminder time should be kept")
+	})
+	t.Run("clear reminder", func(t *testing.T) {
+		var saved *Note
+		err := ClearReminder(store, newNS(&NoteAttributes{ReminderOrder: 10, ReminderTime: 20}, &saved), "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(NoteAttributes{}, *saved.Attributes, "All the reminder attributes should be cleared")
+	})
+	t.Run("no reminder", func(t *testing.T) {
+		var saved *Note
+		assert.Equal(ErrNoReminder, CompleteReminder(store, newNS(nil, &saved), "Note"), "Wrong error returned")
+		assert.Equal(ErrNoReminder, ClearReminder(store, newNS(&NoteAttributes{}, &saved), "Note"), "Wrong error returned")
+		assert.Nil(saved, "Note should not be updated")
+	})
+}
+
+func TestGetReminders(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ms := func(d time.Duration) int64 { return toMilliseconds(now.Add(d)) }
+	notes := []*Note{
+		{Title: "Tomorrow", Attributes: &NoteAttributes{ReminderOrder: 1, ReminderTime: ms(24 * time.Hour)}},
+		{Title: "No due time", Attributes: &NoteAttributes{ReminderOrder: 2}},
+		{Title: "This evening", Attributes: &NoteAttributes{ReminderOrder: 3, ReminderTime: ms(6 * time.Hour)}},
+		{Title: "Yesterday", Attributes: &NoteAttributes{ReminderOrder: 4, ReminderTime: ms(-24 * time.Hour)}},
+		{Title: "This morning", Attributes: &NoteAttributes{ReminderOrder: 5, ReminderTime: ms(-3 * time.Hour)}},
+		{Title: "Done", Attributes: &NoteAttributes{ReminderOrder: 6, ReminderTime: ms(time.Hour), ReminderDoneTime: ms(0)}},
+		{Title: "No reminder"},
+	}
+	var filter *NoteFilter
+	ns := new(mockNS)
+	ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) {
+		filter = f
+		return &NoteList{Notes: notes, TotalNotes: len(notes)}, nil
+	}
+	tests := []struct {
+		name     string
+		due      ReminderDue
+		expected []string
+	}{
+		{"all", ReminderDueAll, []string{"Yesterday", "This morning", "This evening", "Tomorrow", "No due time"}},
+		{"today", ReminderDueToday, []string{"This morning", "This evening"}},
+		{"overdue", ReminderDueOverdue, []string{"Yesterday", "This morning"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := GetReminders(ns, test.due, now)
+			assert.NoError(err, "Should not return an error")
+			assert.True(filter.Reminders, "Should search for notes with reminders")
+			titles := make([]string, len(list))
+			for i, n := range list {
+				titles[i] = n.Title
+			}
+			assert.Equal(test.expected, titles, "Wrong reminders returned")
+		})
+	}
+}
+
+func TestParseReminderTime(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("date and time", func(t *testing.T) {
+		at, err := ParseReminderTime("2026-11-01 09:00", time.UTC)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC), at, "Wrong time")
+	})
+	t.Run("only date", func(t *testing.T) {
+		at, err := ParseReminderTime("2026-11-01", time.UTC)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), at, "Wrong time")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseReminderTime("tomorrow", time.UTC)
+		assert.Equal(ErrInvalidReminderTime, err, "Wrong error returned")
+	})
+	t.Run("due filter", func(t *testing.T) {
+		due, err := ParseReminderDue("Overdue")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(ReminderDueOverdue, due, "Wrong due filter")
+		_, err = ParseReminderDue("week")
+		assert.Equal(ErrUnknownReminderDue, err, "Wrong error returned")
+	})
+}
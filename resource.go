@@ -0,0 +1,224 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	noteEndTag           = "</en-note>"
+	defaultResourceMime  = "application/octet-stream"
+	resourceFilePrepend  = "resource_"
+	resourceFileMode     = 0600
+	resourceMediaElement = `<en-media type="%s" hash="%s"/>`
+)
+
+var (
+	// ErrResourceHashMismatch is returned if the downloaded data doesn't
+	// match the resource's hash.
+	ErrResourceHashMismatch = errors.New("resource data doesn't match the hash")
+	// ErrNoResourceFound is returned if no matching resource was found.
+	ErrNoResourceFound = errors.New("no resource found")
+	// ErrMalformedNoteContent is returned if the note content is not a valid ENML document.
+	ErrMalformedNoteContent = errors.New("malformed note content")
+)
+
+// Resource is a file attached to a note.
+type Resource struct {
+	// GUID is the unique identifier.
+	GUID string
+	// NoteGUID is the GUID of the note the resource belongs to.
+	NoteGUID string
+	// Filename is the original filename of the resource.
+	Filename string
+	// Mime is the resource's MIME type.
+	Mime string
+	// Hash is the hex encoded MD5 hash of the data. The hash is used
+	// by the en-media element in the note content to reference the resource.
+	Hash string
+	// Size of the data in bytes.
+	Size int
+	// Data is the resource content. It's only set when the resource is
+	// uploaded or has been downloaded.
+	Data []byte `json:"-"`
+}
+
+// NewResource creates a new resource from the data.
+func NewResource(filename, mimeType string, data []byte) *Resource {
+	return &Resource{
+		Filename: filename,
+		Mime:     mimeType,
+		Hash:     resourceHash(data),
+		Size:     len(data),
+		Data:     data,
+	}
+}
+
+// NewResourceFromFile creates a new resource from the file. The MIME type
+// is based on the file extension or if unknown, the file content.
+func NewResourceFromFile(fp string) (*Resource, error) {
+	data, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(fp))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	// Strip parameters like charset since Evernote only accepts the media type.
+	if i := strings.Index(mimeType, ";"); i != -1 {
+		mimeType = mimeType[:i]
+	}
+	return NewResource(filepath.Base(fp), mimeType, data), nil
+}
+
+// resourceHash returns the hex encoded MD5 hash of the data.
+func resourceHash(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetNoteResources returns the resources attached to the note.
+func GetNoteResources(db Storager, ns NotestoreClient, title string) ([]*Resource, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*Resource, len(n.Resources))
+	for i, r := range n.Resources {
+		// The search result may not include all the resource attributes,
+		// so the full metadata is requested for each resource.
+		resources[i], err = ns.GetResource(r.GUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+// FindResourceByHash returns the resource matching the hash used
+// by the en-media element.
+func FindResourceByHash(resources []*Resource, hash string) (*Resource, error) {
+	for _, r := range resources {
+		if strings.EqualFold(r.Hash, hash) {
+			return r, nil
+		}
+	}
+	return nil, ErrNoResourceFound
+}
+
+// GetResourceData downloads the resource's data from the notestore. The data is
+// verified against the resource hash.
+func GetResourceData(ns NotestoreClient, r *Resource) ([]byte, error) {
+	data, err := ns.GetResourceData(r.GUID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Hash != "" && !strings.EqualFold(r.Hash, resourceHash(data)) {
+		return nil, ErrResourceHashMismatch
+	}
+	return data, nil
+}
+
+// ExtractResource downloads the resource and saves it to the folder.
+// The path to the saved file is returned.
+func ExtractResource(ns NotestoreClient, r *Resource, dir string) (string, error) {
+	data, err := GetResourceData(ns, r)
+	if err != nil {
+		return "", err
+	}
+	fp := filepath.Join(dir, resourceFilename(r))
+	f, err := os.OpenFile(fp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, resourceFileMode)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return fp, err
+}
+
+// resourceFilename returns the filename for the resource. If the resource
+// doesn't have a filename, one is generated from the hash and MIME type.
+func resourceFilename(r *Resource) string {
+	if r.Filename != "" {
+		return filepath.Base(r.Filename)
+	}
+	name := resourceFilePrepend + r.Hash
+	exts, err := mime.ExtensionsByType(r.Mime)
+	if err == nil && len(exts) > 0 {
+		name += exts[0]
+	}
+	return name
+}
+
+// AttachResources adds the resources to the note and saves the note
+// to the notestore. Each resource is referenced at the end of the note content.
+func AttachResources(db Storager, ns NotestoreClient, title string, resources []*Resource) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	// The server replaces the note's resources with the ones sent, so the
+	// current resources are fetched by the GUID since the search result
+	// or the saved search may not have them all.
+	current, err := ns.GetNote(n.GUID)
+	if err != nil {
+		return err
+	}
+	content, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return err
+	}
+	body, err := addMediaElements(content, resources)
+	if err != nil {
+		return err
+	}
+	n.Body = body
+	n.Resources = append(current.Resources, resources...)
+	return ns.UpdateNote(n)
+}
+
+// addMediaElements adds an en-media element for each resource to the end
+// of the ENML content.
+func addMediaElements(content string, resources []*Resource) (string, error) {
+	i := strings.LastIndex(content, noteEndTag)
+	if i == -1 {
+		return "", ErrMalformedNoteContent
+	}
+	media := ""
+	for _, r := range resources {
+		m := r.Mime
+		if m == "" {
+			m = defaultResourceMime
+		}
+		media += fmt.Sprintf(resourceMediaElement, m, r.Hash)
+	}
+	return content[:i] + media + content[i:], nil
+}
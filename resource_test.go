@@ -0,0 +1,141 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceHash(t *testing.T) {
+	assert := assert.New(t)
+	r := NewResource("file.txt", "text/plain", []byte("content"))
+	assert.Equal("9a0364b9e99bb480dd25e1f0284c8555", r.Hash, "Wrong hash")
+	assert.Equal(7, r.Size, "Wrong size")
+
+	found, err := FindResourceByHash([]*Resource{&Resource{Hash: "other"}, r}, "9A0364B9E99BB480DD25E1F0284C8555")
+	assert.NoError(err, "Should find the resource")
+	assert.Equal(r, found, "Wrong resource returned")
+
+	_, err = FindResourceByHash([]*Resource{r}, "missing")
+	assert.Equal(ErrNoResourceFound, err, "Wrong error returned")
+}
+
+func TestGetResourceData(t *testing.T) {
+	assert := assert.New(t)
+	data := []byte("content")
+	r := &Resource{GUID: "GUID", Hash: resourceHash(data)}
+	t.Run("return data", func(t *testing.T) {
+		ns := &mockNS{getResourceData: func(string) ([]byte, error) { return data, nil }}
+		d, err := GetResourceData(ns, r)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(data, d, "Wrong data returned")
+	})
+	t.Run("detect hash mismatch", func(t *testing.T) {
+		ns := &mockNS{getResourceData: func(string) ([]byte, error) { return []byte("other"), nil }}
+		_, err := GetResourceData(ns, r)
+		assert.Equal(ErrResourceHashMismatch, err, "Wrong error returned")
+	})
+	t.Run("return error from notestore", func(t *testing.T) {
+		expectedErr := errors.New("expected")
+		ns := &mockNS{getResourceData: func(string) ([]byte, error) { return nil, expectedErr }}
+		_, err := GetResourceData(ns, r)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestExtractResource(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "clinote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data := []byte("content")
+	ns := &mockNS{getResourceData: func(string) ([]byte, error) { return data, nil }}
+
+	t.Run("use resource filename", func(t *testing.T) {
+		fp, err := ExtractResource(ns, &Resource{Filename: "file.txt", Hash: resourceHash(data)}, dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(filepath.Join(dir, "file.txt"), fp, "Wrong file path")
+		saved, err := ioutil.ReadFile(fp)
+		assert.NoError(err, "Should read the file")
+		assert.Equal(data, saved, "Wrong data saved")
+	})
+	t.Run("generate filename from hash", func(t *testing.T) {
+		fp, err := ExtractResource(ns, &Resource{Hash: resourceHash(data)}, dir)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(filepath.Join(dir, resourceFilePrepend+resourceHash(data)), fp, "Wrong file path")
+	})
+}
+
+func TestGetNoteResources(t *testing.T) {
+	assert := assert.New(t)
+	store := new(mockStore)
+	note := &Note{Title: "Note", Resources: []*Resource{&Resource{GUID: "GUID"}}}
+	expected := &Resource{GUID: "GUID", Filename: "file.txt"}
+	ns := nsWithNote(note)
+	ns.getResource = func(guid string) (*Resource, error) { return expected, nil }
+	resources, err := GetNoteResources(store, ns, "Note")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal([]*Resource{expected}, resources, "Wrong resources returned")
+}
+
+func TestAttachResources(t *testing.T) {
+	assert := assert.New(t)
+	store := new(mockStore)
+	r := NewResource("file.txt", "text/plain", []byte("content"))
+	t.Run("add resource to note", func(t *testing.T) {
+		note := &Note{Title: "Note", GUID: "GUID"}
+		ns := nsWithNote(note)
+		ns.getNoteContent = func(string) (string, error) { return XMLHeader + "<en-note><p>Body</p></en-note>", nil }
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		err := AttachResources(store, ns, "Note", []*Resource{r})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Resource{r}, saved.Resources, "Resource not added")
+		assert.Equal(XMLHeader+`<en-note><p>Body</p><en-media type="text/plain" hash="`+r.Hash+`"/></en-note>`, saved.Body, "Wrong content")
+	})
+	t.Run("keep resources missing from the search result", func(t *testing.T) {
+		existing := &Resource{GUID: "Resource GUID", Hash: "hash"}
+		note := &Note{Title: "Note", GUID: "GUID"}
+		ns := nsWithNote(note)
+		ns.getNote = func(guid string) (*Note, error) {
+			assert.Equal("GUID", guid, "Wrong note requested")
+			return &Note{GUID: guid, Resources: []*Resource{existing}}, nil
+		}
+		ns.getNoteContent = func(string) (string, error) { return XMLHeader + "<en-note><p>Body</p></en-note>", nil }
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		err := AttachResources(store, ns, "Note", []*Resource{r})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*Resource{existing, r}, saved.Resources, "Existing resources should be kept")
+	})
+	t.Run("return error on malformed content", func(t *testing.T) {
+		note := &Note{Title: "Note", GUID: "GUID"}
+		ns := nsWithNote(note)
+		ns.getNoteContent = func(string) (string, error) { return "<p>Body</p>", nil }
+		err := AttachResources(store, ns, "Note", []*Resource{r})
+		assert.Equal(ErrMalformedNoteContent, err, "Wrong error returned")
+	})
+}
@@ -0,0 +1,181 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+// maxNoteSnapshots is the number of snapshots kept for each note. The oldest
+// snapshots are removed when new snapshots are saved.
+const maxNoteSnapshots = 50
+
+// ErrNoSnapshotFound is returned if the note doesn't have a matching snapshot.
+var ErrNoSnapshotFound = errors.New("no snapshot found")
+
+// NoteSnapshot is a local copy of a note saved by clinote. A snapshot is saved
+// each time a note is edited so changes can be reverted without relying on the
+// note history kept by the server.
+type NoteSnapshot struct {
+	// ID identifies the snapshot. It's created from the note's GUID and the
+	// time the snapshot was saved.
+	ID string
+	// NoteGUID is the GUID of the note.
+	NoteGUID string
+	// Title is the note's title.
+	Title string
+	// Notebook is the name of the notebook the note belonged to.
+	Notebook string
+	// MD is the Markdown representation of the note body.
+	MD string
+	// Body is the note's ENML document.
+	Body string
+	// Hash is the hex encoded hash of the note's title, tags and Markdown body.
+	Hash string
+	// Saved is the time in milliseconds when the snapshot was saved.
+	Saved int64
+}
+
+// GetNoteSnapshots returns the snapshots of the note, the most recent first.
+func GetNoteSnapshots(db Storager, ns NotestoreClient, title string) ([]*NoteSnapshot, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	return getNoteSnapshots(db, n.GUID)
+}
+
+// getNoteSnapshots returns the snapshots of the note with the GUID, the most recent first.
+func getNoteSnapshots(db Storager, guid string) ([]*NoteSnapshot, error) {
+	snapshots, err := db.GetNoteSnapshots(guid)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(snapshots)-1; i < j; i, j = i+1, j-1 {
+		snapshots[i], snapshots[j] = snapshots[j], snapshots[i]
+	}
+	return snapshots, nil
+}
+
+// RevertNote replaces the note's title, notebook and content with the snapshot
+// with the index in the list returned by GetNoteSnapshots. The index starts at 1.
+// The reverted note is saved as a new snapshot so the revert can be undone. If the
+// server can't be reached, the change is queued and ErrChangeQueued is returned.
+func RevertNote(db Storager, ns NotestoreClient, title string, index int) error {
+	n, err := GetNoteWithContent(db, ns, title)
+	if err != nil {
+		return err
+	}
+	snapshots, err := getNoteSnapshots(db, n.GUID)
+	if err != nil {
+		return err
+	}
+	if index < 1 || index > len(snapshots) {
+		return ErrNoSnapshotFound
+	}
+	s := snapshots[index-1]
+	if n.Notebook, err = GetNotebook(ns, n.Notebook.GUID); err != nil {
+		return err
+	}
+	// Keep the current content in the history before it's replaced.
+	current, err := newNoteSnapshot(n, enmlDocument(n.Body))
+	if err != nil {
+		return err
+	}
+	if n.Notebook == nil || n.Notebook.Name != s.Notebook {
+		if n.Notebook, err = FindNotebook(db, ns, s.Notebook); err != nil {
+			return err
+		}
+	}
+	n.Title = s.Title
+	n.Body = s.Body
+	n.MD = s.MD
+	err = saveChanges(ns, n, false, false)
+	if err == ErrOffline {
+		err = queueOfflineChange(db, ChangeUpdate, n)
+	}
+	if err != nil && err != ErrChangeQueued {
+		return err
+	}
+	if snapErr := saveEditSnapshots(db, current, n); snapErr != nil {
+		return snapErr
+	}
+	return err
+}
+
+// newNoteSnapshot returns a snapshot of the note with the ENML document as its body.
+// The Markdown is created from the document so it matches the body even if the
+// note was edited in its raw format.
+func newNoteSnapshot(n *Note, body string) (*NoteSnapshot, error) {
+	tmp := &Note{Title: n.Title, Tags: n.Tags}
+	if err := decodeXML(body, tmp); err != nil {
+		return nil, err
+	}
+	md, err := markdown.FromENML(tmp.Body)
+	if err != nil {
+		return nil, err
+	}
+	tmp.MD = md
+	return &NoteSnapshot{
+		NoteGUID: n.GUID,
+		Title:    n.Title,
+		Notebook: getNotebookName(n),
+		MD:       md,
+		Body:     body,
+		Hash:     hex.EncodeToString(tmp.Hash(false)),
+	}, nil
+}
+
+// saveNoteSnapshots saves the snapshots to the database. A snapshot is skipped if
+// the note hasn't changed since its latest snapshot or if the note doesn't have a
+// GUID yet. Once saved, the oldest snapshots over the limit are removed.
+func saveNoteSnapshots(db Storager, snapshots ...*NoteSnapshot) error {
+	for _, s := range snapshots {
+		if s == nil || s.NoteGUID == "" {
+			continue
+		}
+		saved, err := db.GetNoteSnapshots(s.NoteGUID)
+		if err != nil {
+			return err
+		}
+		if len(saved) > 0 {
+			latest := saved[len(saved)-1]
+			if latest.Hash == s.Hash && latest.Notebook == s.Notebook {
+				continue
+			}
+		}
+		if err = db.SaveNoteSnapshot(s); err != nil {
+			return err
+		}
+		for i := 0; i < len(saved)+1-maxNoteSnapshots; i++ {
+			if err = db.RemoveNoteSnapshot(saved[i].ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// enmlDocument wraps the content of the en-note element in an ENML document.
+func enmlDocument(content string) string {
+	return fmt.Sprintf("%s<en-note>%s</en-note>", XMLHeader, content)
+}
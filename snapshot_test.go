@@ -0,0 +1,140 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteSnapshots(t *testing.T) {
+	assert := assert.New(t)
+	newStore := func(snapshots ...*NoteSnapshot) (*mockStore, *[]*NoteSnapshot) {
+		saved := snapshots
+		store := &mockStore{
+			getNotebookCache: func() (*NotebookCacheList, error) {
+				return &NotebookCacheList{
+					Timestamp: time.Now(),
+					Notebooks: []*Notebook{&Notebook{GUID: "NB1", Name: "Work"}, &Notebook{GUID: "NB2", Name: "Personal"}},
+					Limit:     time.Hour,
+				}, nil
+			},
+			getNoteSnapshots: func(guid string) ([]*NoteSnapshot, error) { return saved, nil },
+			saveNoteSnapshot: func(s *NoteSnapshot) error {
+				s.ID = s.NoteGUID + "_" + strconv.Itoa(len(saved))
+				saved = append(saved, s)
+				return nil
+			},
+			removeNoteSnapshot: func(id string) error {
+				for i, s := range saved {
+					if s.ID == id {
+						saved = append(saved[:i:i], saved[i+1:]...)
+						break
+					}
+				}
+				return nil
+			},
+		}
+		return store, &saved
+	}
+	newNS := func() *mockNS {
+		note := &Note{Title: "Note", GUID: "GUID", Notebook: &Notebook{GUID: "NB1"}}
+		ns := nsWithNote(note)
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Work"}, nil }
+		ns.getNoteContent = func(guid string) (string, error) {
+			return XMLHeader + "<en-note><p>Current</p></en-note>", nil
+		}
+		return ns
+	}
+	old := &NoteSnapshot{ID: "GUID_1", NoteGUID: "GUID", Title: "Old", Notebook: "Personal", MD: "Old", Body: XMLHeader + "<en-note><p>Old</p></en-note>", Hash: "1"}
+	latest := &NoteSnapshot{ID: "GUID_2", NoteGUID: "GUID", Title: "Note", Notebook: "Work", MD: "Latest", Body: XMLHeader + "<en-note><p>Latest</p></en-note>", Hash: "2"}
+
+	t.Run("list snapshots", func(t *testing.T) {
+		store, _ := newStore(old, latest)
+		snapshots, err := GetNoteSnapshots(store, newNS(), "Note")
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(snapshots, 2, "Wrong number of snapshots") {
+			assert.Equal(latest, snapshots[0], "Most recent snapshot should be first")
+			assert.Equal(old, snapshots[1], "Oldest snapshot should be last")
+		}
+	})
+	t.Run("skip unchanged note", func(t *testing.T) {
+		store, saved := newStore()
+		n := &Note{Title: "Note", GUID: "GUID", Notebook: &Notebook{Name: "Work"}}
+		s1, err := newNoteSnapshot(n, XMLHeader+"<en-note><p>Text</p></en-note>")
+		assert.NoError(err, "Should not return an error")
+		s2, err := newNoteSnapshot(n, XMLHeader+"<en-note><p>Text</p></en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.NoError(saveNoteSnapshots(store, s1, s2), "Should not return an error")
+		assert.Len(*saved, 1, "Unchanged note should not be saved again")
+		assert.Equal("Text", (*saved)[0].MD, "Markdown should be created from the body")
+	})
+	t.Run("skip note without GUID", func(t *testing.T) {
+		store, saved := newStore()
+		s, err := newNoteSnapshot(&Note{Title: "New"}, XMLHeader+"<en-note></en-note>")
+		assert.NoError(err, "Should not return an error")
+		assert.NoError(saveNoteSnapshots(store, s), "Should not return an error")
+		assert.Len(*saved, 0, "Note without a GUID should not be saved")
+	})
+	t.Run("remove oldest snapshots", func(t *testing.T) {
+		store, saved := newStore()
+		for i := 0; i < maxNoteSnapshots+2; i++ {
+			s := &NoteSnapshot{NoteGUID: "GUID", Hash: strconv.Itoa(i)}
+			assert.NoError(saveNoteSnapshots(store, s), "Should not return an error")
+		}
+		assert.Len(*saved, maxNoteSnapshots, "Wrong number of snapshots kept")
+		assert.Equal("2", (*saved)[0].Hash, "Oldest snapshots should be removed")
+	})
+	t.Run("revert note", func(t *testing.T) {
+		store, saved := newStore(old, latest)
+		ns := newNS()
+		var updated *Note
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		err := RevertNote(store, ns, "Note", 2)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("GUID", updated.GUID, "Wrong note updated")
+		assert.Equal("Old", updated.Title, "Title should be reverted")
+		assert.Equal(old.Body, updated.Body, "Content should be reverted")
+		assert.Equal("NB2", updated.Notebook.GUID, "Notebook should be reverted")
+		if assert.Len(*saved, 4, "Current and reverted note should be saved") {
+			assert.Equal("Current", (*saved)[2].MD, "Current content should be saved before the revert")
+			assert.Equal("Old", (*saved)[3].MD, "Reverted content should be saved last")
+		}
+	})
+	t.Run("queue revert if offline", func(t *testing.T) {
+		store, _ := newStore(old, latest)
+		var queued *Change
+		store.queueChange = func(c *Change) error { queued = c; return nil }
+		ns := newNS()
+		ns.updateNote = func(*Note) error { return ErrOffline }
+		err := RevertNote(store, ns, "Note", 1)
+		assert.Equal(ErrChangeQueued, err, "Wrong error returned")
+		assert.Equal("Latest", queued.Note.MD, "Reverted note should be queued")
+	})
+	t.Run("no snapshot found", func(t *testing.T) {
+		store, _ := newStore(old)
+		for _, index := range []int{0, 2} {
+			err := RevertNote(store, newNS(), "Note", index)
+			assert.Equal(ErrNoSnapshotFound, err, "Wrong error returned for index %d", index)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrNoStackFound is returned if no notebook belongs to the stack.
+var ErrNoStackFound = errors.New("no stack found")
+
+// Stack is a group of notebooks.
+type Stack struct {
+	// Name is the stack's name.
+	Name string
+	// Notebooks are the notebooks in the stack, sorted by name.
+	Notebooks []*Notebook
+}
+
+// GetStacks returns the stacks of the user's notebooks, sorted by name.
+func GetStacks(db Storager, ns NotestoreClient) ([]*Stack, error) {
+	bs, err := GetNotebooks(db, ns, false)
+	if err != nil {
+		return nil, err
+	}
+	var stacks []*Stack
+	for _, e := range notebookTree(bs) {
+		if e.Stack != nil {
+			stacks = append(stacks, e.Stack)
+		}
+	}
+	return stacks, nil
+}
+
+// RenameStack moves all the notebooks in the stack to the new stack.
+func RenameStack(db Storager, ns NotestoreClient, name, newName string) error {
+	return setNotebookStack(db, ns, name, newName)
+}
+
+// DissolveStack removes all the notebooks from the stack. The notebooks
+// are kept.
+func DissolveStack(db Storager, ns NotestoreClient, name string) error {
+	return setNotebookStack(db, ns, name, "")
+}
+
+// setNotebookStack updates the stack of all the notebooks in the stack. No notebook
+// is updated if the stack of any of them can't be changed. The notebook cache is
+// refreshed once the notebooks have been updated.
+func setNotebookStack(db Storager, ns NotestoreClient, name, stack string) error {
+	if name == "" {
+		return ErrNoStackFound
+	}
+	bs, err := GetNotebooks(db, ns, true)
+	if err != nil {
+		return err
+	}
+	var members []*Notebook
+	for _, b := range bs {
+		if b.Stack != name {
+			continue
+		}
+		if b.Restrictions != nil && (b.Restrictions.NoUpdateNotebook || b.Restrictions.NoSetNotebookStack) {
+			return ErrNotebookRestricted
+		}
+		members = append(members, b)
+	}
+	if len(members) == 0 {
+		return ErrNoStackFound
+	}
+	for _, b := range members {
+		updated := *b
+		updated.Stack = stack
+		if err = ns.UpdateNotebook(&updated); err != nil {
+			return err
+		}
+	}
+	_, err = GetNotebooks(db, ns, true)
+	return err
+}
+
+// notebookTreeEntry is either a stack or a notebook without a stack.
+type notebookTreeEntry struct {
+	Stack    *Stack
+	Notebook *Notebook
+}
+
+func (e *notebookTreeEntry) name() string {
+	if e.Stack != nil {
+		return e.Stack.Name
+	}
+	return e.Notebook.Name
+}
+
+// notebookTree groups the notebooks by stack. The stacks and the notebooks
+// without a stack are sorted by name.
+func notebookTree(bs []*Notebook) []*notebookTreeEntry {
+	var entries []*notebookTreeEntry
+	stacks := make(map[string]*Stack)
+	for _, b := range bs {
+		if b.Stack == "" {
+			entries = append(entries, &notebookTreeEntry{Notebook: b})
+			continue
+		}
+		s, ok := stacks[b.Stack]
+		if !ok {
+			s = &Stack{Name: b.Stack}
+			stacks[b.Stack] = s
+			entries = append(entries, &notebookTreeEntry{Stack: s})
+		}
+		s.Notebooks = append(s.Notebooks, b)
+	}
+	for _, s := range stacks {
+		sort.SliceStable(s.Notebooks, func(i, j int) bool { return lessName(s.Notebooks[i].Name, s.Notebooks[j].Name) })
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return lessName(entries[i].name(), entries[j].name()) })
+	return entries
+}
+
+// lessName compares the names case-insensitively.
+func lessName(a, b string) bool {
+	return strings.ToLower(a) < strings.ToLower(b)
+}
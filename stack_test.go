@@ -0,0 +1,125 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStacks(t *testing.T) {
+	assert := assert.New(t)
+	newMocks := func() (*mockStore, *mockNS, *[]*Notebook) {
+		var updated []*Notebook
+		store := &mockStore{
+			getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+			storeNotebookList: func(list *NotebookCacheList) error { return nil },
+		}
+		ns := &mockNS{
+			getAllNotebooks: func() ([]*Notebook, error) {
+				return []*Notebook{
+					&Notebook{Name: "work", GUID: "1", Stack: "Projects"},
+					&Notebook{Name: "Inbox", GUID: "2"},
+					&Notebook{Name: "Clients", GUID: "3", Stack: "Projects"},
+					&Notebook{Name: "Recipes", GUID: "4", Stack: "Home"},
+				}, nil
+			},
+			updateNotebook: func(b *Notebook) error { updated = append(updated, b); return nil },
+		}
+		return store, ns, &updated
+	}
+
+	t.Run("list stacks", func(t *testing.T) {
+		store, ns, _ := newMocks()
+		stacks, err := GetStacks(store, ns)
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(stacks, 2, "Wrong number of stacks") {
+			assert.Equal("Home", stacks[0].Name, "Stacks should be sorted by name")
+			assert.Equal("Projects", stacks[1].Name, "Stacks should be sorted by name")
+			if assert.Len(stacks[1].Notebooks, 2, "Wrong number of notebooks in the stack") {
+				assert.Equal("Clients", stacks[1].Notebooks[0].Name, "Notebooks should be sorted by name")
+				assert.Equal("work", stacks[1].Notebooks[1].Name, "Notebooks should be sorted by name")
+			}
+		}
+	})
+	t.Run("rename stack", func(t *testing.T) {
+		store, ns, updated := newMocks()
+		err := RenameStack(store, ns, "Projects", "Work")
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(*updated, 2, "All the notebooks in the stack should be updated") {
+			assert.Equal("1", (*updated)[0].GUID, "Wrong notebook updated")
+			assert.Equal("Work", (*updated)[0].Stack, "Stack should be renamed")
+			assert.Equal("3", (*updated)[1].GUID, "Wrong notebook updated")
+			assert.Equal("Work", (*updated)[1].Stack, "Stack should be renamed")
+		}
+	})
+	t.Run("dissolve stack", func(t *testing.T) {
+		store, ns, updated := newMocks()
+		err := DissolveStack(store, ns, "Home")
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(*updated, 1, "All the notebooks in the stack should be updated") {
+			assert.Equal("Recipes", (*updated)[0].Name, "Notebook name should be kept")
+			assert.Equal("", (*updated)[0].Stack, "Notebook should be removed from the stack")
+		}
+	})
+	t.Run("no stack found", func(t *testing.T) {
+		for _, name := range []string{"Missing", ""} {
+			store, ns, updated := newMocks()
+			err := DissolveStack(store, ns, name)
+			assert.Equal(ErrNoStackFound, err, "Wrong error returned for %q", name)
+			assert.Len(*updated, 0, "No notebook should be updated for %q", name)
+		}
+	})
+	t.Run("return error if restricted", func(t *testing.T) {
+		store, ns, updated := newMocks()
+		getAll := ns.getAllNotebooks
+		ns.getAllNotebooks = func() ([]*Notebook, error) {
+			bs, err := getAll()
+			bs[2].Restrictions = &NotebookRestrictions{NoSetNotebookStack: true}
+			return bs, err
+		}
+		err := DissolveStack(store, ns, "Projects")
+		assert.Equal(ErrNotebookRestricted, err, "Wrong error returned")
+		assert.Len(*updated, 0, "No notebook should be updated")
+	})
+	t.Run("return error from notestore", func(t *testing.T) {
+		store, ns, _ := newMocks()
+		expectedErr := errors.New("expected error")
+		ns.updateNotebook = func(*Notebook) error { return expectedErr }
+		err := RenameStack(store, ns, "Projects", "Work")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestDeleteNotebook(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	var expunged string
+	ns := &mockNS{
+		getAllNotebooks: func() ([]*Notebook, error) { return []*Notebook{&Notebook{Name: "Book", GUID: "GUID"}}, nil },
+		expungeNotebook: func(guid string) error { expunged = guid; return nil },
+	}
+	assert.NoError(DeleteNotebook(store, ns, "Book"), "Should not return an error")
+	assert.Equal("GUID", expunged, "Wrong notebook removed")
+	assert.Equal(ErrNoNotebookFound, DeleteNotebook(store, ns, "Missing"), "Wrong error returned")
+}
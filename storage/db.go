@@ -18,10 +18,14 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,16 +39,24 @@ const (
 
 // 0: Initial version of the database.
 // 1: Added credential store, migration of OAuth token.
-var softwareDBVersion = uint64(1)
+// 2: Added recovery bucket, migration of the single recovery point.
+var softwareDBVersion = uint64(2)
 
 // This is what the current wait time before the database is closed.
 var currentWaitTime = 5 * time.Second
 
 // List of buckets
 var (
-	dbBucket       = []byte("db_data")
-	settingsBucket = []byte("settings")
-	cacheBucket    = []byte("cache")
+	dbBucket        = []byte("db_data")
+	settingsBucket  = []byte("settings")
+	cacheBucket     = []byte("cache")
+	syncBucket      = []byte("sync")
+	notesBucket     = []byte("notes")
+	notebooksBucket = []byte("notebooks")
+	tagsBucket      = []byte("tags")
+	queueBucket     = []byte("queue")
+	recoveryBucket  = []byte("recovery")
+	historyBucket   = []byte("history")
 )
 
 // List of keys
@@ -55,6 +67,7 @@ var (
 	searchCacheKey      = []byte("note_search_cache")
 	noteRecoverCacheKey = []byte("note_recover_cache")
 	dbVersionKey        = []byte("dbVersion")
+	syncStateKey        = []byte("sync_state")
 )
 
 var (
@@ -243,6 +256,83 @@ func (d *Database) storeData(bucket, key, data []byte) error {
 	})
 }
 
+// getAllData returns the values of all the keys in the bucket.
+func (d *Database) getAllData(bucket []byte) ([][]byte, error) {
+	var data [][]byte
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return data, err
+	}
+	err = db.View(func(t *bolt.Tx) error {
+		b := t.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			// The value is only valid during the transaction.
+			data = append(data, append([]byte(nil), v...))
+			return nil
+		})
+	})
+	return data, err
+}
+
+// getPrefixData returns the values of the keys in the bucket that start with the prefix.
+func (d *Database) getPrefixData(bucket, prefix []byte) ([][]byte, error) {
+	var data [][]byte
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return data, err
+	}
+	err = db.View(func(t *bolt.Tx) error {
+		b := t.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			// The value is only valid during the transaction.
+			data = append(data, append([]byte(nil), v...))
+		}
+		return nil
+	})
+	return data, err
+}
+
+func (d *Database) removeData(bucket, key []byte) error {
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(t *bolt.Tx) error {
+		b := t.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+		return b.Delete(key)
+	})
+}
+
+func (d *Database) removeBuckets(buckets ...[]byte) error {
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(t *bolt.Tx) error {
+		for _, bucket := range buckets {
+			err := t.DeleteBucket(bucket)
+			if err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetSettings returns the settings from the database.
 func (d *Database) GetSettings() (*clinote.Settings, error) {
 	var settings clinote.Settings
@@ -300,24 +390,90 @@ func (d *Database) GetSearch() ([]*clinote.Note, error) {
 	return notes, err
 }
 
-// SaveNoteRecoveryPoint saves the note to the database so it can be
-// recovered in the case something fails.
-func (d *Database) SaveNoteRecoveryPoint(note *clinote.Note) error {
-	data, err := json.Marshal(note)
+// SaveNoteRecoveryPoint saves the note to the database as a new recovery
+// point so it can be recovered in the case something fails. Raw is true if
+// the note's content was edited as ENML instead of Markdown.
+func (d *Database) SaveNoteRecoveryPoint(note *clinote.Note, raw bool) error {
+	now := time.Now()
+	rp := &clinote.RecoveryPoint{
+		ID:    note.GUID + "_" + strconv.FormatInt(now.UnixNano(), 10),
+		Note:  note,
+		Saved: now.UnixNano() / int64(time.Millisecond),
+		Raw:   raw,
+	}
+	data, err := json.Marshal(rp)
 	if err != nil {
 		return err
 	}
-	return d.storeData(cacheBucket, noteRecoverCacheKey, data)
+	return d.storeData(recoveryBucket, []byte(rp.ID), data)
 }
 
-// GetNoteRecoveryPoint returns the saved note that failed to save.
-func (d *Database) GetNoteRecoveryPoint() (*clinote.Note, error) {
-	var note clinote.Note
-	data, err := d.getData(cacheBucket, noteRecoverCacheKey)
-	if err == nil && data != nil {
-		err = json.Unmarshal(data, &note)
+// GetNoteRecoveryPoints returns all the recovery points, the oldest first.
+func (d *Database) GetNoteRecoveryPoints() ([]*clinote.RecoveryPoint, error) {
+	values, err := d.getAllData(recoveryBucket)
+	if err != nil {
+		return nil, err
+	}
+	rps := make([]*clinote.RecoveryPoint, len(values))
+	for i, data := range values {
+		rps[i] = new(clinote.RecoveryPoint)
+		if err = json.Unmarshal(data, rps[i]); err != nil {
+			return nil, err
+		}
 	}
-	return &note, err
+	// The keys are sorted by the note's GUID so the points are sorted by the
+	// timestamp in the key, which has a higher precision than Saved.
+	sort.SliceStable(rps, func(i, j int) bool { return recoveryPointTime(rps[i].ID) < recoveryPointTime(rps[j].ID) })
+	return rps, nil
+}
+
+// recoveryPointTime returns the timestamp in nanoseconds from the recovery point ID.
+func recoveryPointTime(id string) int64 {
+	ts, _ := strconv.ParseInt(id[strings.LastIndex(id, "_")+1:], 10, 64)
+	return ts
+}
+
+// RemoveNoteRecoveryPoint removes the recovery point.
+func (d *Database) RemoveNoteRecoveryPoint(id string) error {
+	return d.removeData(recoveryBucket, []byte(id))
+}
+
+// SaveNoteSnapshot saves the snapshot to the note history. The snapshot's ID and
+// saved time are set when it's saved.
+func (d *Database) SaveNoteSnapshot(s *clinote.NoteSnapshot) error {
+	now := time.Now()
+	s.ID = s.NoteGUID + "_" + strconv.FormatInt(now.UnixNano(), 10)
+	s.Saved = now.UnixNano() / int64(time.Millisecond)
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return d.storeData(historyBucket, []byte(s.ID), data)
+}
+
+// GetNoteSnapshots returns all the snapshots of the note, the oldest first.
+func (d *Database) GetNoteSnapshots(guid string) ([]*clinote.NoteSnapshot, error) {
+	values, err := d.getPrefixData(historyBucket, []byte(guid+"_"))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*clinote.NoteSnapshot, len(values))
+	for i, data := range values {
+		snapshots[i] = new(clinote.NoteSnapshot)
+		if err = json.Unmarshal(data, snapshots[i]); err != nil {
+			return nil, err
+		}
+	}
+	// Sorted by the timestamp in the key, which has a higher precision than Saved.
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return recoveryPointTime(snapshots[i].ID) < recoveryPointTime(snapshots[j].ID)
+	})
+	return snapshots, nil
+}
+
+// RemoveNoteSnapshot removes the snapshot from the note history.
+func (d *Database) RemoveNoteSnapshot(id string) error {
+	return d.removeData(historyBucket, []byte(id))
 }
 
 // Close shuts down the connection to the database.
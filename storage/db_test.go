@@ -18,6 +18,7 @@
 package storage
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -104,17 +105,85 @@ func TestRecoveryPoint(t *testing.T) {
 	db, tmpDir := setupTestDB(t)
 	defer os.RemoveAll(tmpDir)
 	defer db.Close()
-	expectedNote := &clinote.Note{Title: "Test note"}
+	expectedNotes := []*clinote.Note{
+		&clinote.Note{Title: "Test note", GUID: "GUID1"},
+		&clinote.Note{Title: "Test note 2", GUID: "GUID2"},
+		&clinote.Note{Title: "Test note", GUID: "GUID1"},
+	}
 
 	t.Run("Store", func(t *testing.T) {
-		err := db.SaveNoteRecoveryPoint(expectedNote)
-		assert.NoError(err, "Should not fail to save")
+		for i, n := range expectedNotes {
+			err := db.SaveNoteRecoveryPoint(n, i == 1)
+			assert.NoError(err, "Should not fail to save")
+		}
 	})
 
 	t.Run("Get", func(t *testing.T) {
-		actual, err := db.GetNoteRecoveryPoint()
-		assert.NoError(err, "Should not fail to return recovery point")
-		assert.Equal(expectedNote, actual, "Wrong note returned")
+		actual, err := db.GetNoteRecoveryPoints()
+		assert.NoError(err, "Should not fail to return recovery points")
+		if !assert.Len(actual, len(expectedNotes), "Wrong number of recovery points") {
+			return
+		}
+		for i, rp := range actual {
+			assert.Equal(expectedNotes[i], rp.Note, "Wrong note returned or wrong order")
+			assert.NotEmpty(rp.ID, "ID should be set")
+			assert.NotZero(rp.Saved, "Saved time should be set")
+			assert.Equal(i == 1, rp.Raw, "Wrong content format")
+		}
+		assert.NotEqual(actual[0].ID, actual[2].ID, "Each recovery point should have an unique ID")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		rps, err := db.GetNoteRecoveryPoints()
+		assert.NoError(err, "Should not fail to return recovery points")
+		err = db.RemoveNoteRecoveryPoint(rps[1].ID)
+		assert.NoError(err, "Should not fail to remove")
+		actual, err := db.GetNoteRecoveryPoints()
+		assert.NoError(err, "Should not fail to return recovery points")
+		if assert.Len(actual, 2, "Recovery point not removed") {
+			assert.Equal(expectedNotes[0], actual[0].Note)
+			assert.Equal(expectedNotes[2], actual[1].Note)
+		}
+	})
+}
+
+func TestNoteSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	snapshots := []*clinote.NoteSnapshot{
+		&clinote.NoteSnapshot{NoteGUID: "GUID1", Title: "First", MD: "First"},
+		&clinote.NoteSnapshot{NoteGUID: "GUID10", Title: "Other note"},
+		&clinote.NoteSnapshot{NoteGUID: "GUID1", Title: "Second", MD: "Second"},
+	}
+
+	t.Run("Store", func(t *testing.T) {
+		for _, s := range snapshots {
+			err := db.SaveNoteSnapshot(s)
+			assert.NoError(err, "Should not fail to save")
+			assert.NotEmpty(s.ID, "ID should be set")
+			assert.NotZero(s.Saved, "Saved time should be set")
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		actual, err := db.GetNoteSnapshots("GUID1")
+		assert.NoError(err, "Should not fail to return snapshots")
+		if assert.Len(actual, 2, "Only the note's snapshots should be returned") {
+			assert.Equal(snapshots[0], actual[0], "Wrong snapshot returned or wrong order")
+			assert.Equal(snapshots[2], actual[1], "Wrong snapshot returned or wrong order")
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		err := db.RemoveNoteSnapshot(snapshots[0].ID)
+		assert.NoError(err, "Should not fail to remove")
+		actual, err := db.GetNoteSnapshots("GUID1")
+		assert.NoError(err, "Should not fail to return snapshots")
+		if assert.Len(actual, 1, "Snapshot not removed") {
+			assert.Equal(snapshots[2], actual[0])
+		}
 	})
 }
 
@@ -177,6 +246,109 @@ func TestCredentialStore(t *testing.T) {
 	})
 }
 
+func TestLocalStore(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	notes := []*clinote.Note{
+		&clinote.Note{GUID: "Note 1", Title: "Note 1", Body: "Content", USN: 1},
+		&clinote.Note{GUID: "Note 2", Title: "Note 2", USN: 2},
+	}
+	notebook := &clinote.Notebook{GUID: "Notebook", Name: "Notebook"}
+	tag := &clinote.Tag{GUID: "Tag", Name: "Tag"}
+
+	t.Run("Empty store", func(t *testing.T) {
+		state, err := db.GetLocalSyncState()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(int32(0), state.USN, "Should not be synced")
+		all, err := db.GetLocalNotes()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(all, 0, "No notes should be returned")
+		_, err = db.GetLocalNote("Note 1")
+		assert.Equal(clinote.ErrNoNoteFound, err, "Wrong error returned")
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		for _, n := range notes {
+			assert.NoError(db.SaveLocalNote(n), "Should not fail to save note")
+		}
+		assert.NoError(db.SaveLocalNotebook(notebook), "Should not fail to save notebook")
+		assert.NoError(db.SaveLocalTag(tag), "Should not fail to save tag")
+		assert.NoError(db.SaveLocalSyncState(&clinote.LocalSyncState{USN: 2, LastSync: 1000}), "Should not fail to save state")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		n, err := db.GetLocalNote("Note 1")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(notes[0], n, "Wrong note returned")
+		all, err := db.GetLocalNotes()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(notes, all, "Wrong notes returned")
+		books, err := db.GetLocalNotebooks()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*clinote.Notebook{notebook}, books, "Wrong notebooks returned")
+		tags, err := db.GetLocalTags()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]*clinote.Tag{tag}, tags, "Wrong tags returned")
+		state, err := db.GetLocalSyncState()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(&clinote.LocalSyncState{USN: 2, LastSync: 1000}, state, "Wrong state returned")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		assert.NoError(db.RemoveLocalNote("Note 1"), "Should not fail to remove note")
+		assert.NoError(db.RemoveLocalNote("Missing"), "Should not fail to remove a missing note")
+		all, err := db.GetLocalNotes()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(notes[1:], all, "Wrong notes returned")
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		assert.NoError(db.ClearLocalStore(), "Should not fail to clear")
+		all, err := db.GetLocalNotes()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(all, 0, "No notes should be returned")
+		books, err := db.GetLocalNotebooks()
+		assert.NoError(err, "Should not return an error")
+		assert.Len(books, 0, "No notebooks should be returned")
+		state, err := db.GetLocalSyncState()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(int32(0), state.USN, "Sync state should be reset")
+	})
+}
+
+func TestChangeQueue(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	changes := make([]*clinote.Change, 0)
+	for i := 0; i < 300; i++ {
+		changes = append(changes, &clinote.Change{Type: clinote.ChangeUpdate, Note: &clinote.Note{GUID: fmt.Sprintf("Note %d", i)}})
+	}
+
+	t.Run("Queue", func(t *testing.T) {
+		for i, c := range changes {
+			assert.NoError(db.QueueChange(c), "Should not fail to queue")
+			assert.Equal(uint64(i+1), c.ID, "ID should be set")
+		}
+	})
+
+	t.Run("Get in order", func(t *testing.T) {
+		actual, err := db.GetQueuedChanges()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(changes, actual, "Wrong changes returned")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		assert.NoError(db.RemoveQueuedChange(changes[0].ID), "Should not fail to remove")
+		actual, err := db.GetQueuedChanges()
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(changes[1:], actual, "Wrong changes returned")
+	})
+}
+
 func compareCacheList(assert *assert.Assertions, expected *clinote.NotebookCacheList, actual *clinote.NotebookCacheList) {
 	assert.Equal(expected.Limit, actual.Limit)
 	assert.Equal(expected.Notebooks, actual.Notebooks)
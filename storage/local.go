@@ -0,0 +1,156 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/TcM1911/clinote"
+)
+
+// GetLocalSyncState returns the sync state of the local note store.
+func (d *Database) GetLocalSyncState() (*clinote.LocalSyncState, error) {
+	var state clinote.LocalSyncState
+	data, err := d.getData(syncBucket, syncStateKey)
+	if err == nil && data != nil {
+		err = json.Unmarshal(data, &state)
+	}
+	return &state, err
+}
+
+// SaveLocalSyncState saves the sync state of the local note store.
+func (d *Database) SaveLocalSyncState(state *clinote.LocalSyncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return d.storeData(syncBucket, syncStateKey, data)
+}
+
+// SaveLocalNote saves the note to the local note store.
+func (d *Database) SaveLocalNote(note *clinote.Note) error {
+	data, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	return d.storeData(notesBucket, []byte(note.GUID), data)
+}
+
+// GetLocalNote returns the note from the local note store.
+func (d *Database) GetLocalNote(guid string) (*clinote.Note, error) {
+	data, err := d.getData(notesBucket, []byte(guid))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, clinote.ErrNoNoteFound
+	}
+	var note clinote.Note
+	err = json.Unmarshal(data, &note)
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// GetLocalNotes returns all the notes in the local note store.
+func (d *Database) GetLocalNotes() ([]*clinote.Note, error) {
+	values, err := d.getAllData(notesBucket)
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]*clinote.Note, len(values))
+	for i, data := range values {
+		notes[i] = new(clinote.Note)
+		if err = json.Unmarshal(data, notes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return notes, nil
+}
+
+// RemoveLocalNote removes the note from the local note store.
+func (d *Database) RemoveLocalNote(guid string) error {
+	return d.removeData(notesBucket, []byte(guid))
+}
+
+// SaveLocalNotebook saves the notebook to the local note store.
+func (d *Database) SaveLocalNotebook(notebook *clinote.Notebook) error {
+	data, err := json.Marshal(notebook)
+	if err != nil {
+		return err
+	}
+	return d.storeData(notebooksBucket, []byte(notebook.GUID), data)
+}
+
+// GetLocalNotebooks returns all the notebooks in the local note store.
+func (d *Database) GetLocalNotebooks() ([]*clinote.Notebook, error) {
+	values, err := d.getAllData(notebooksBucket)
+	if err != nil {
+		return nil, err
+	}
+	notebooks := make([]*clinote.Notebook, len(values))
+	for i, data := range values {
+		notebooks[i] = new(clinote.Notebook)
+		if err = json.Unmarshal(data, notebooks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return notebooks, nil
+}
+
+// RemoveLocalNotebook removes the notebook from the local note store.
+func (d *Database) RemoveLocalNotebook(guid string) error {
+	return d.removeData(notebooksBucket, []byte(guid))
+}
+
+// SaveLocalTag saves the tag to the local note store.
+func (d *Database) SaveLocalTag(tag *clinote.Tag) error {
+	data, err := json.Marshal(tag)
+	if err != nil {
+		return err
+	}
+	return d.storeData(tagsBucket, []byte(tag.GUID), data)
+}
+
+// GetLocalTags returns all the tags in the local note store.
+func (d *Database) GetLocalTags() ([]*clinote.Tag, error) {
+	values, err := d.getAllData(tagsBucket)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*clinote.Tag, len(values))
+	for i, data := range values {
+		tags[i] = new(clinote.Tag)
+		if err = json.Unmarshal(data, tags[i]); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// RemoveLocalTag removes the tag from the local note store.
+func (d *Database) RemoveLocalTag(guid string) error {
+	return d.removeData(tagsBucket, []byte(guid))
+}
+
+// ClearLocalStore removes all the notes, notebooks and tags and resets the sync
+// state of the local note store.
+func (d *Database) ClearLocalStore() error {
+	return d.removeBuckets(notesBucket, notebooksBucket, tagsBucket, syncBucket)
+}
@@ -31,9 +31,33 @@ func migrate(db *Database, currVersion uint64) error {
 			return err
 		}
 	}
+	if currVersion < uint64(2) {
+		err := migrateRecoveryPoint(db)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// migrateRecoveryPoint moves the single recovery point to the recovery bucket.
+func migrateRecoveryPoint(db *Database) error {
+	data, err := db.getData(cacheBucket, noteRecoverCacheKey)
+	if err != nil || data == nil {
+		return err
+	}
+	var note clinote.Note
+	if err = json.Unmarshal(data, &note); err != nil {
+		return err
+	}
+	if note.GUID != "" {
+		if err = db.SaveNoteRecoveryPoint(&note, false); err != nil {
+			return err
+		}
+	}
+	return db.removeData(cacheBucket, noteRecoverCacheKey)
+}
+
 func migrateOAuthCredential(db *Database) error {
 	var data []byte
 	d, err := db.getDBHandler()
@@ -18,6 +18,7 @@
 package storage
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -25,6 +26,29 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestRecoveryPointMigration(t *testing.T) {
+	assert := assert.New(t)
+	db, tmpDir := setupTestDB(t)
+	defer os.RemoveAll(tmpDir)
+	defer db.Close()
+	expected := &clinote.Note{GUID: "GUID", Title: "Test note"}
+	data, err := json.Marshal(expected)
+	assert.NoError(err, "Failed to setup database")
+	err = db.storeData(cacheBucket, noteRecoverCacheKey, data)
+	assert.NoError(err, "Failed to setup database")
+
+	err = migrate(db, uint64(1))
+	assert.NoError(err)
+	rps, err := db.GetNoteRecoveryPoints()
+	assert.NoError(err, "Error when getting the recovery points")
+	if assert.Len(rps, 1, "Recovery point should be migrated") {
+		assert.Equal(expected, rps[0].Note)
+	}
+	data, err = db.getData(cacheBucket, noteRecoverCacheKey)
+	assert.NoError(err)
+	assert.Nil(data, "Old recovery point should be removed")
+}
+
 func TestCredentialMigration(t *testing.T) {
 	assert := assert.New(t)
 	db, tmpDir := setupTestDB(t)
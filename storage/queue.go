@@ -0,0 +1,81 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/TcM1911/clinote"
+	"github.com/boltdb/bolt"
+)
+
+// QueueChange adds the change to the end of the outbound change queue.
+// The change's ID is set to its position in the queue.
+func (d *Database) QueueChange(c *clinote.Change) error {
+	db, err := d.getDBHandler()
+	defer d.releaseDBHandler()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(t *bolt.Tx) error {
+		b, err := t.CreateBucketIfNotExists(queueBucket)
+		if err != nil {
+			return err
+		}
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		c.ID = id
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		return b.Put(queueKey(id), data)
+	})
+}
+
+// GetQueuedChanges returns the queued changes in the order they were added.
+func (d *Database) GetQueuedChanges() ([]*clinote.Change, error) {
+	values, err := d.getAllData(queueBucket)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*clinote.Change, len(values))
+	for i, data := range values {
+		changes[i] = new(clinote.Change)
+		if err = json.Unmarshal(data, changes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// RemoveQueuedChange removes the change from the queue.
+func (d *Database) RemoveQueuedChange(id uint64) error {
+	return d.removeData(queueBucket, queueKey(id))
+}
+
+// queueKey encodes the ID in big endian so the changes are iterated in
+// the order they were added.
+func queueKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
@@ -0,0 +1,147 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+// syncChunkSize is the maximum number of entries requested per sync chunk.
+const syncChunkSize = 100
+
+// SyncState is the state of the user's account on the server.
+type SyncState struct {
+	// CurrentTime is the server's time in milliseconds.
+	CurrentTime int64
+	// FullSyncBefore is the cutoff time for incremental syncs. If the last
+	// sync happened before this time, a full sync is needed.
+	FullSyncBefore int64
+	// UpdateCount is the highest update sequence number in the account.
+	UpdateCount int32
+}
+
+// SyncChunk is a set of changes to the account.
+type SyncChunk struct {
+	// ChunkHighUSN is the highest update sequence number in the chunk.
+	ChunkHighUSN int32
+	// UpdateCount is the highest update sequence number in the account.
+	UpdateCount int32
+	// Notes that have been created or changed. The notes don't include the content.
+	Notes []*Note
+	// Notebooks that have been created or changed.
+	Notebooks []*Notebook
+	// Tags that have been created or changed.
+	Tags []*Tag
+	// ExpungedNotes are the GUIDs of permanently removed notes.
+	ExpungedNotes []string
+	// ExpungedNotebooks are the GUIDs of permanently removed notebooks.
+	ExpungedNotebooks []string
+	// ExpungedTags are the GUIDs of permanently removed tags.
+	ExpungedTags []string
+}
+
+// LocalSyncState is the state of the local note store.
+type LocalSyncState struct {
+	// USN is the highest update sequence number included in the local copy.
+	USN int32
+	// LastSync is the server time in milliseconds of the last completed sync.
+	LastSync int64
+}
+
+// Sync updates the local copy of the user's notes with the changes on the server.
+// The resume point is saved after each chunk so an interrupted sync continues where
+// it stopped. The number of notes that were updated is returned.
+func Sync(db Storager, ns NotestoreClient) (int, error) {
+	local, err := db.GetLocalSyncState()
+	if err != nil {
+		return 0, err
+	}
+	state, err := ns.GetSyncState()
+	if err != nil {
+		return 0, err
+	}
+	// The server has expunged data the incremental sync can't represent.
+	if local.USN > 0 && state.FullSyncBefore > local.LastSync {
+		if err = db.ClearLocalStore(); err != nil {
+			return 0, err
+		}
+		local = new(LocalSyncState)
+	}
+	updated := 0
+	for local.USN < state.UpdateCount {
+		chunk, err := ns.GetSyncChunk(local.USN, syncChunkSize)
+		if err != nil {
+			return updated, err
+		}
+		n, err := applySyncChunk(db, ns, chunk)
+		updated += n
+		if err != nil {
+			return updated, err
+		}
+		if chunk.ChunkHighUSN <= local.USN {
+			break
+		}
+		local.USN = chunk.ChunkHighUSN
+		if err = db.SaveLocalSyncState(local); err != nil {
+			return updated, err
+		}
+	}
+	local.LastSync = state.CurrentTime
+	return updated, db.SaveLocalSyncState(local)
+}
+
+// applySyncChunk saves the changes in the chunk to the local note store.
+// The content is downloaded for each active note.
+func applySyncChunk(db Storager, ns NotestoreClient, chunk *SyncChunk) (int, error) {
+	for _, b := range chunk.Notebooks {
+		if err := db.SaveLocalNotebook(b); err != nil {
+			return 0, err
+		}
+	}
+	for _, t := range chunk.Tags {
+		if err := db.SaveLocalTag(t); err != nil {
+			return 0, err
+		}
+	}
+	updated := 0
+	for _, n := range chunk.Notes {
+		if !n.Deleted {
+			content, err := ns.GetNoteContent(n.GUID)
+			if err != nil {
+				return updated, err
+			}
+			n.Body = content
+		}
+		if err := db.SaveLocalNote(n); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	for _, guid := range chunk.ExpungedNotes {
+		if err := db.RemoveLocalNote(guid); err != nil {
+			return updated, err
+		}
+	}
+	for _, guid := range chunk.ExpungedNotebooks {
+		if err := db.RemoveLocalNotebook(guid); err != nil {
+			return updated, err
+		}
+	}
+	for _, guid := range chunk.ExpungedTags {
+		if err := db.RemoveLocalTag(guid); err != nil {
+			return updated, err
+		}
+	}
+	return updated, nil
+}
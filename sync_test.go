@@ -0,0 +1,132 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSync(t *testing.T) {
+	assert := assert.New(t)
+	chunks := []*SyncChunk{
+		&SyncChunk{
+			ChunkHighUSN: 2,
+			UpdateCount:  4,
+			Notebooks:    []*Notebook{&Notebook{GUID: "Notebook GUID", Name: "Notebook"}},
+			Tags:         []*Tag{&Tag{GUID: "Tag GUID", Name: "Tag"}},
+		},
+		&SyncChunk{
+			ChunkHighUSN:  4,
+			UpdateCount:   4,
+			Notes:         []*Note{&Note{GUID: "Note GUID", USN: 3}, &Note{GUID: "Deleted GUID", USN: 4, Deleted: true}},
+			ExpungedNotes: []string{"Expunged GUID"},
+			ExpungedTags:  []string{"Old tag GUID"},
+		},
+	}
+	newStore := func(local *LocalSyncState) (*mockStore, map[string]*Note, *[]string, *[]int32) {
+		notes := make(map[string]*Note)
+		removed := make([]string, 0)
+		saved := make([]int32, 0)
+		store := &mockStore{
+			getLocalSyncState:  func() (*LocalSyncState, error) { return local, nil },
+			saveLocalSyncState: func(s *LocalSyncState) error { saved = append(saved, s.USN); return nil },
+			saveLocalNote:      func(n *Note) error { notes[n.GUID] = n; return nil },
+			saveLocalNotebook:  func(b *Notebook) error { return nil },
+			saveLocalTag:       func(t *Tag) error { return nil },
+			removeLocalNote:    func(guid string) error { removed = append(removed, guid); return nil },
+			removeLocalTag:     func(guid string) error { removed = append(removed, guid); return nil },
+		}
+		return store, notes, &removed, &saved
+	}
+	newNS := func(state *SyncState) *mockNS {
+		return &mockNS{
+			getSyncState: func() (*SyncState, error) { return state, nil },
+			getSyncChunk: func(afterUSN int32, max int) (*SyncChunk, error) {
+				for _, c := range chunks {
+					if c.ChunkHighUSN > afterUSN {
+						return c, nil
+					}
+				}
+				return &SyncChunk{UpdateCount: 4}, nil
+			},
+			getNoteContent: func(guid string) (string, error) { return "Content " + guid, nil },
+		}
+	}
+
+	t.Run("full sync", func(t *testing.T) {
+		store, notes, removed, saved := newStore(new(LocalSyncState))
+		n, err := Sync(store, newNS(&SyncState{UpdateCount: 4, CurrentTime: 1000}))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(2, n, "Wrong number of notes updated")
+		assert.Equal("Content Note GUID", notes["Note GUID"].Body, "Content should be downloaded")
+		assert.Equal("", notes["Deleted GUID"].Body, "Content should not be downloaded for deleted notes")
+		assert.Equal([]string{"Expunged GUID", "Old tag GUID"}, *removed, "Expunged entries should be removed")
+		assert.Equal([]int32{2, 4, 4}, *saved, "Resume point should be saved after each chunk")
+	})
+
+	t.Run("resume from saved USN", func(t *testing.T) {
+		local := &LocalSyncState{USN: 2, LastSync: 500}
+		store, notes, _, _ := newStore(local)
+		ns := newNS(&SyncState{UpdateCount: 4, CurrentTime: 1000})
+		requested := make([]int32, 0)
+		getChunk := ns.getSyncChunk
+		ns.getSyncChunk = func(afterUSN int32, max int) (*SyncChunk, error) {
+			requested = append(requested, afterUSN)
+			return getChunk(afterUSN, max)
+		}
+		_, err := Sync(store, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal([]int32{2}, requested, "Should only request changes after the resume point")
+		assert.Len(notes, 2, "Notes should be saved")
+		assert.Equal(int32(4), local.USN, "Wrong USN")
+		assert.Equal(int64(1000), local.LastSync, "Last sync time should be updated")
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		store, notes, _, _ := newStore(&LocalSyncState{USN: 4, LastSync: 500})
+		ns := newNS(&SyncState{UpdateCount: 4, CurrentTime: 1000})
+		ns.getSyncChunk = nil
+		n, err := Sync(store, ns)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(0, n, "No notes should be updated")
+		assert.Len(notes, 0, "No notes should be saved")
+	})
+
+	t.Run("full sync required", func(t *testing.T) {
+		store, notes, _, _ := newStore(&LocalSyncState{USN: 4, LastSync: 500})
+		cleared := false
+		store.clearLocalStore = func() error { cleared = true; return nil }
+		_, err := Sync(store, newNS(&SyncState{UpdateCount: 4, FullSyncBefore: 800, CurrentTime: 1000}))
+		assert.NoError(err, "Should not return an error")
+		assert.True(cleared, "Local store should be cleared")
+		assert.Len(notes, 2, "All notes should be downloaded again")
+	})
+
+	t.Run("keep resume point on error", func(t *testing.T) {
+		store, _, _, saved := newStore(new(LocalSyncState))
+		ns := newNS(&SyncState{UpdateCount: 4})
+		expectedErr := errors.New("expected")
+		ns.getNoteContent = func(string) (string, error) { return "", expectedErr }
+		_, err := Sync(store, ns)
+		assert.Equal(expectedErr, err, "Wrong error returned")
+		assert.Equal([]int32{2}, *saved, "Only the first chunk should be saved")
+	})
+}
@@ -0,0 +1,135 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNoTagFound is returned if no matching tag was found.
+	ErrNoTagFound = errors.New("no tag found")
+)
+
+// tagSep separates the tag names in the note header.
+const tagSep = ","
+
+// Tag is a struct for the tag.
+type Tag struct {
+	// Name is the tag's name.
+	Name string
+	// GUID is the tag's GUID.
+	GUID string
+	// ParentGUID is the GUID of the parent tag, if any.
+	ParentGUID string
+}
+
+// GetTags returns all the user's tags.
+func GetTags(ns NotestoreClient) ([]*Tag, error) {
+	return ns.ListTags()
+}
+
+// FindTag gets the tag matching with the name.
+func FindTag(ns NotestoreClient, name string) (*Tag, error) {
+	tags, err := ns.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, ErrNoTagFound
+}
+
+// CreateTag creates a new tag.
+func CreateTag(ns NotestoreClient, tag *Tag) error {
+	return ns.CreateTag(tag)
+}
+
+// RenameTag changes the name of the tag.
+func RenameTag(ns NotestoreClient, old, newName string) error {
+	t, err := FindTag(ns, old)
+	if err != nil {
+		return err
+	}
+	t.Name = newName
+	return ns.UpdateTag(t)
+}
+
+// DeleteTag permanently removes the tag. The tag is removed from
+// all notes.
+func DeleteTag(ns NotestoreClient, name string) error {
+	t, err := FindTag(ns, name)
+	if err != nil {
+		return err
+	}
+	return ns.ExpungeTag(t.GUID)
+}
+
+// populateTagNames fills in the name for the note's tags
+// that only has the GUID set.
+func populateTagNames(ns NotestoreClient, n *Note) error {
+	missing := false
+	for _, t := range n.Tags {
+		if t.Name == "" {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return nil
+	}
+	tags, err := ns.ListTags()
+	if err != nil {
+		return err
+	}
+	for _, t := range n.Tags {
+		for _, tag := range tags {
+			if t.GUID == tag.GUID {
+				t.Name = tag.Name
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// tagNames returns a list of the tag names.
+func tagNames(tags []*Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// parseTags parses a comma separated list of tag names.
+func parseTags(s string) []*Tag {
+	tags := make([]*Tag, 0)
+	for _, name := range strings.Split(s, tagSep) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tags = append(tags, &Tag{Name: name})
+	}
+	return tags
+}
@@ -0,0 +1,93 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindTag(t *testing.T) {
+	assert := assert.New(t)
+	tags := []*Tag{&Tag{Name: "Other", GUID: "GUID1"}, &Tag{Name: "Tag", GUID: "GUID2"}}
+	t.Run("return tag", func(t *testing.T) {
+		ns := &mockNS{listTags: func() ([]*Tag, error) { return tags, nil }}
+		tag, err := FindTag(ns, "Tag")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(tags[1], tag, "Wrong tag returned")
+	})
+	t.Run("return error if no tag", func(t *testing.T) {
+		ns := &mockNS{listTags: func() ([]*Tag, error) { return tags, nil }}
+		_, err := FindTag(ns, "Missing")
+		assert.Equal(ErrNoTagFound, err, "Wrong error returned")
+	})
+}
+
+func TestRenameTag(t *testing.T) {
+	assert := assert.New(t)
+	expectedErr := errors.New("expected error")
+	t.Run("rename tag", func(t *testing.T) {
+		var saved *Tag
+		ns := &mockNS{
+			listTags:  func() ([]*Tag, error) { return []*Tag{&Tag{Name: "Old", GUID: "GUID"}}, nil },
+			updateTag: func(t *Tag) error { saved = t; return nil },
+		}
+		err := RenameTag(ns, "Old", "New")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(&Tag{Name: "New", GUID: "GUID"}, saved, "Wrong tag saved")
+	})
+	t.Run("return error from UpdateTag", func(t *testing.T) {
+		ns := &mockNS{
+			listTags:  func() ([]*Tag, error) { return []*Tag{&Tag{Name: "Old", GUID: "GUID"}}, nil },
+			updateTag: func(t *Tag) error { return expectedErr },
+		}
+		err := RenameTag(ns, "Old", "New")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestDeleteTag(t *testing.T) {
+	assert := assert.New(t)
+	var expunged string
+	ns := &mockNS{
+		listTags:   func() ([]*Tag, error) { return []*Tag{&Tag{Name: "Tag", GUID: "GUID"}}, nil },
+		expungeTag: func(guid string) error { expunged = guid; return nil },
+	}
+	err := DeleteTag(ns, "Tag")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("GUID", expunged, "Wrong tag expunged")
+}
+
+func TestPopulateTagNames(t *testing.T) {
+	assert := assert.New(t)
+	t.Run("skip lookup if names are known", func(t *testing.T) {
+		ns := &mockNS{listTags: func() ([]*Tag, error) { return nil, errors.New("should not be called") }}
+		n := &Note{Tags: []*Tag{&Tag{Name: "Tag", GUID: "GUID"}}}
+		assert.NoError(populateTagNames(ns, n), "Should not return an error")
+	})
+	t.Run("set names", func(t *testing.T) {
+		ns := &mockNS{listTags: func() ([]*Tag, error) {
+			return []*Tag{&Tag{Name: "Tag1", GUID: "GUID1"}, &Tag{Name: "Tag2", GUID: "GUID2"}}, nil
+		}}
+		n := &Note{Tags: []*Tag{&Tag{GUID: "GUID2"}}}
+		assert.NoError(populateTagNames(ns, n), "Should not return an error")
+		assert.Equal("Tag2", n.Tags[0].Name, "Wrong tag name")
+	})
+}
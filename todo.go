@@ -0,0 +1,137 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"strings"
+
+	"github.com/TcM1911/clinote/markdown"
+)
+
+// todoPageSize is the number of notes requested per page when the checkboxes
+// are listed.
+const todoPageSize = 100
+
+// ErrNoTodoFound is returned if the note doesn't have a checkbox with the index.
+var ErrNoTodoFound = markdown.ErrNoTodoFound
+
+// TodoItem is a checkbox in a note.
+type TodoItem struct {
+	// Note is the note the checkbox is in.
+	Note *Note
+	// Index is the position of the checkbox in the note. The index starts at 1.
+	Index int
+	// Text is the text after the checkbox.
+	Text string
+	// Checked is true if the checkbox is checked.
+	Checked bool
+}
+
+// GetTodos returns the checkboxes in the notes, the most recently updated notes
+// first. If notebook isn't an empty string, only the notes in the notebook are
+// included. If open is true, only the checkboxes that aren't checked are returned.
+func GetTodos(db Storager, ns NotestoreClient, notebook string, open bool) ([]*TodoItem, error) {
+	filter := &NoteFilter{Todos: true, Order: NoteFilterOrderUpdated}
+	if notebook != "" {
+		nb, err := findNotebook(db, ns, notebook)
+		if err != nil {
+			return nil, err
+		}
+		filter.NotebookGUID = nb.GUID
+	}
+	var items []*TodoItem
+	err := FindAllNotes(ns, filter, 0, todoPageSize, func(list *NoteList) error {
+		for _, n := range list.Notes {
+			content, err := ns.GetNoteContent(n.GUID)
+			if err != nil {
+				return err
+			}
+			todos, err := markdown.Todos(content)
+			if err != nil {
+				return err
+			}
+			for i, t := range todos {
+				if open && t.Checked {
+					continue
+				}
+				items = append(items, &TodoItem{Note: n, Index: i + 1, Text: t.Text, Checked: t.Checked})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CheckTodo checks the checkbox with the index in the note, or unchecks it if
+// it's already checked. The index starts at 1. Only the checkbox is changed in
+// the note's content. The note before and after the change is saved to the note
+// history. If the server can't be reached, the change is queued and
+// ErrChangeQueued is returned together with the checkbox.
+func CheckTodo(db Storager, ns NotestoreClient, title string, index int) (*TodoItem, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	content, err := ns.GetNoteContent(n.GUID)
+	if err != nil {
+		return nil, err
+	}
+	todos, err := markdown.Todos(content)
+	if err != nil {
+		return nil, err
+	}
+	if index < 1 || index > len(todos) {
+		return nil, ErrNoTodoFound
+	}
+	item := &TodoItem{Note: n, Index: index, Text: todos[index-1].Text, Checked: !todos[index-1].Checked}
+	body, err := markdown.SetTodo(content, index-1, item.Checked)
+	if err != nil {
+		return nil, err
+	}
+	// The tag and notebook names are needed for the note history.
+	if err = populateTagNames(ns, n); err != nil {
+		return nil, err
+	}
+	if n.Notebook, err = GetNotebook(ns, n.Notebook.GUID); err != nil {
+		return nil, err
+	}
+	current, err := newNoteSnapshot(n, content)
+	if err != nil {
+		return nil, err
+	}
+	n.Body = body
+	err = saveChanges(ns, n, false, false)
+	if err == ErrOffline {
+		err = queueOfflineChange(db, ChangeUpdate, n)
+	}
+	if err != nil && err != ErrChangeQueued {
+		return nil, err
+	}
+	if snapErr := saveEditSnapshots(db, current, n); snapErr != nil {
+		return nil, snapErr
+	}
+	return item, err
+}
+
+// hasTodos returns true if the note's content has a checkbox.
+func hasTodos(n *Note) bool {
+	return strings.Contains(n.Body, "<en-todo")
+}
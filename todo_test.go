@@ -0,0 +1,106 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodos(t *testing.T) {
+	assert := assert.New(t)
+	contents := map[string]string{
+		"GUID1": XMLHeader + "<en-note><h1>Shopping</h1><div><en-todo checked=\"true\"/>Milk</div><div><en-todo/>Bread</div></en-note>",
+		"GUID2": XMLHeader + "<en-note><ul><li><en-todo/>Call Bob</li></ul></en-note>",
+	}
+	notes := []*Note{
+		&Note{Title: "Shopping list", GUID: "GUID1", Notebook: &Notebook{GUID: "NB1"}},
+		&Note{Title: "Work", GUID: "GUID2", Notebook: &Notebook{GUID: "NB1"}},
+	}
+	var filter *NoteFilter
+	newNS := func() *mockNS {
+		ns := new(mockNS)
+		ns.findNotes = func(f *NoteFilter, offset, count int) (*NoteList, error) {
+			filter = f
+			return &NoteList{Notes: notes, TotalNotes: len(notes)}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) { return contents[guid], nil }
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.getNotebook = func(guid string) (*Notebook, error) { return &Notebook{GUID: "NB1", Name: "Notebook"}, nil }
+		return ns
+	}
+	var snapshots []*NoteSnapshot
+	store := &mockStore{
+		getSearch: func() ([]*Note, error) { return nil, nil },
+		getNotebookCache: func() (*NotebookCacheList, error) {
+			return &NotebookCacheList{Timestamp: time.Now(), Notebooks: []*Notebook{&Notebook{GUID: "NB1", Name: "Notebook"}}, Limit: time.Hour}, nil
+		},
+		getNoteSnapshots: func(guid string) ([]*NoteSnapshot, error) { return snapshots, nil },
+		saveNoteSnapshot: func(s *NoteSnapshot) error { snapshots = append(snapshots, s); return nil },
+	}
+	texts := func(items []*TodoItem) []string {
+		a := make([]string, len(items))
+		for i, item := range items {
+			a[i] = item.Note.Title + ":" + item.Text
+		}
+		return a
+	}
+
+	t.Run("list all", func(t *testing.T) {
+		items, err := GetTodos(store, newNS(), "", false)
+		assert.NoError(err, "Should not return an error")
+		assert.True(filter.Todos, "Should search for notes with checkboxes")
+		assert.Equal([]string{"Shopping list:Milk", "Shopping list:Bread", "Work:Call Bob"}, texts(items), "Wrong checkboxes")
+		assert.Equal(2, items[1].Index, "Index should be the position in the note")
+	})
+	t.Run("list open in notebook", func(t *testing.T) {
+		items, err := GetTodos(store, newNS(), "Notebook", true)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("NB1", filter.NotebookGUID, "Should search in the notebook")
+		assert.Equal([]string{"Shopping list:Bread", "Work:Call Bob"}, texts(items), "Checked boxes should not be included")
+	})
+	t.Run("check", func(t *testing.T) {
+		snapshots = nil
+		ns := newNS()
+		var updated *Note
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		item, err := CheckTodo(store, ns, "Shopping list", 2)
+		assert.NoError(err, "Should not return an error")
+		assert.True(item.Checked, "Checkbox should be checked")
+		assert.Equal("Bread", item.Text, "Wrong checkbox")
+		expected := strings.Replace(contents["GUID1"], "<en-todo/>Bread", "<en-todo checked=\"true\"/>Bread", 1)
+		assert.Equal(expected, updated.Body, "Only the checkbox should be changed")
+		assert.Len(snapshots, 2, "The note before and after the change should be saved to the history")
+	})
+	t.Run("uncheck", func(t *testing.T) {
+		ns := newNS()
+		var updated *Note
+		ns.updateNote = func(n *Note) error { updated = n; return nil }
+		item, err := CheckTodo(store, ns, "Shopping list", 1)
+		assert.NoError(err, "Should not return an error")
+		assert.False(item.Checked, "Checkbox should be unchecked")
+		assert.Contains(updated.Body, "<en-todo checked=\"false\"/>Milk", "Checkbox should be unchecked in the content")
+	})
+	t.Run("no checkbox", func(t *testing.T) {
+		_, err := CheckTodo(store, newNS(), "Work", 2)
+		assert.Equal(ErrNoTodoFound, err, "Wrong error returned")
+	})
+}
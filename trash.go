@@ -0,0 +1,59 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+// GetTrashNote returns the note in the trash with the title. If the title is
+// a number, the note with the index in the last listing is returned if it's
+// in the trash.
+func GetTrashNote(db Storager, ns NotestoreClient, title string) (*Note, error) {
+	return getNote(db, ns, title, "", true)
+}
+
+// RestoreNote moves the note from the trash back to its notebook.
+func RestoreNote(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetTrashNote(db, ns, title)
+	if err != nil {
+		return err
+	}
+	n.Deleted = false
+	return saveChanges(ns, n, false, false)
+}
+
+// ExpungeNote permanently removes the note in the trash.
+func ExpungeNote(db Storager, ns NotestoreClient, title string) error {
+	n, err := GetTrashNote(db, ns, title)
+	if err != nil {
+		return err
+	}
+	return ExpungeTrashNote(ns, n)
+}
+
+// ExpungeTrashNote permanently removes the note returned by GetTrashNote. The
+// note is removed by its GUID so it's the same note even if the search results
+// have changed since it was found.
+func ExpungeTrashNote(ns NotestoreClient, n *Note) error {
+	if n.GUID == "" {
+		return ErrNoNoteFound
+	}
+	return ns.ExpungeNote(n.GUID)
+}
+
+// EmptyTrash permanently removes all the notes in the trash.
+func EmptyTrash(ns NotestoreClient) error {
+	return ns.ExpungeInactiveNotes()
+}
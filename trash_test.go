@@ -0,0 +1,99 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	assert := assert.New(t)
+	newNS := func(filters *[]*NoteFilter) *mockNS {
+		note := &Note{Title: "Note", GUID: "GUID", Deleted: true, Notebook: &Notebook{GUID: "Notebook"}}
+		ns := new(mockNS)
+		ns.findNotes = func(filter *NoteFilter, offset, count int) (*NoteList, error) {
+			*filters = append(*filters, filter)
+			return &NoteList{Notes: []*Note{note}}, nil
+		}
+		return ns
+	}
+	newStore := func(search ...*Note) *mockStore {
+		return &mockStore{getSearch: func() ([]*Note, error) { return search, nil }}
+	}
+
+	t.Run("restore note", func(t *testing.T) {
+		var filters []*NoteFilter
+		ns := newNS(&filters)
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		err := RestoreNote(newStore(), ns, "Note")
+		assert.NoError(err, "Should not return an error")
+		if assert.Len(filters, 1, "Should search for the note") {
+			assert.True(filters[0].Inactive, "Should search the trash")
+		}
+		assert.Equal("GUID", saved.GUID, "Wrong note restored")
+		assert.False(saved.Deleted, "Note should not be marked as deleted")
+		assert.Equal("", saved.Body, "Content should not be updated")
+	})
+	t.Run("restore note by index", func(t *testing.T) {
+		var filters []*NoteFilter
+		ns := newNS(&filters)
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		store := newStore(&Note{Title: "Indexed", GUID: "Indexed GUID", Deleted: true, Notebook: &Notebook{}})
+		err := RestoreNote(store, ns, "1")
+		assert.NoError(err, "Should not return an error")
+		assert.Len(filters, 0, "Should not search for the note")
+		assert.Equal("Indexed GUID", saved.GUID, "Wrong note restored")
+	})
+	t.Run("ignore active note in listing", func(t *testing.T) {
+		var filters []*NoteFilter
+		ns := newNS(&filters)
+		ns.findNotes = func(filter *NoteFilter, offset, count int) (*NoteList, error) { return &NoteList{}, nil }
+		store := newStore(&Note{Title: "Active", GUID: "Active GUID"})
+		err := RestoreNote(store, ns, "1")
+		assert.Equal(ErrNoNoteFound, err, "Active note should not be restored")
+	})
+	t.Run("expunge note", func(t *testing.T) {
+		var filters []*NoteFilter
+		ns := newNS(&filters)
+		var expunged string
+		ns.expungeNote = func(guid string) error { expunged = guid; return nil }
+		err := ExpungeNote(newStore(), ns, "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("GUID", expunged, "Wrong note expunged")
+	})
+	t.Run("expunge found note", func(t *testing.T) {
+		var expunged string
+		ns := new(mockNS)
+		ns.expungeNote = func(guid string) error { expunged = guid; return nil }
+		err := ExpungeTrashNote(ns, &Note{Title: "Note", GUID: "Found GUID", Deleted: true})
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Found GUID", expunged, "Wrong note expunged")
+		assert.Equal(ErrNoNoteFound, ExpungeTrashNote(ns, &Note{Title: "Note"}), "Note without a GUID should not be expunged")
+	})
+	t.Run("empty trash", func(t *testing.T) {
+		expectedErr := errors.New("expected error")
+		ns := new(mockNS)
+		ns.expungeInactive = func() error { return expectedErr }
+		assert.Equal(expectedErr, EmptyTrash(ns), "Wrong error returned")
+	})
+}
@@ -32,10 +32,51 @@ type Storager interface {
 	SaveSearch([]*Note) error
 	// GetSearch returns a saved note search from the database.
 	GetSearch() ([]*Note, error)
-	// SaveNoteRecoveryPoint saves the note as a recovery point.
-	SaveNoteRecoveryPoint(*Note) error
-	// GetNoteREcoveryPoint returns the saved note.
-	GetNoteRecoveryPoint() (*Note, error)
+	// SaveNoteRecoveryPoint saves the note as a new recovery point. Raw is
+	// true if the note's content was edited as ENML instead of Markdown.
+	SaveNoteRecoveryPoint(note *Note, raw bool) error
+	// GetNoteRecoveryPoints returns all the recovery points, the oldest first.
+	GetNoteRecoveryPoints() ([]*RecoveryPoint, error)
+	// RemoveNoteRecoveryPoint removes the recovery point.
+	RemoveNoteRecoveryPoint(id string) error
+	// SaveNoteSnapshot saves the snapshot to the note history.
+	SaveNoteSnapshot(*NoteSnapshot) error
+	// GetNoteSnapshots returns all the snapshots of the note, the oldest first.
+	GetNoteSnapshots(guid string) ([]*NoteSnapshot, error)
+	// RemoveNoteSnapshot removes the snapshot from the note history.
+	RemoveNoteSnapshot(id string) error
+	// GetLocalSyncState returns the sync state of the local note store.
+	GetLocalSyncState() (*LocalSyncState, error)
+	// SaveLocalSyncState saves the sync state of the local note store.
+	SaveLocalSyncState(*LocalSyncState) error
+	// SaveLocalNote saves the note to the local note store.
+	SaveLocalNote(*Note) error
+	// GetLocalNote returns the note from the local note store.
+	GetLocalNote(guid string) (*Note, error)
+	// GetLocalNotes returns all the notes in the local note store.
+	GetLocalNotes() ([]*Note, error)
+	// RemoveLocalNote removes the note from the local note store.
+	RemoveLocalNote(guid string) error
+	// SaveLocalNotebook saves the notebook to the local note store.
+	SaveLocalNotebook(*Notebook) error
+	// GetLocalNotebooks returns all the notebooks in the local note store.
+	GetLocalNotebooks() ([]*Notebook, error)
+	// RemoveLocalNotebook removes the notebook from the local note store.
+	RemoveLocalNotebook(guid string) error
+	// SaveLocalTag saves the tag to the local note store.
+	SaveLocalTag(*Tag) error
+	// GetLocalTags returns all the tags in the local note store.
+	GetLocalTags() ([]*Tag, error)
+	// RemoveLocalTag removes the tag from the local note store.
+	RemoveLocalTag(guid string) error
+	// ClearLocalStore removes everything from the local note store.
+	ClearLocalStore() error
+	// QueueChange adds the change to the end of the outbound change queue.
+	QueueChange(*Change) error
+	// GetQueuedChanges returns the queued changes in the order they were added.
+	GetQueuedChanges() ([]*Change, error)
+	// RemoveQueuedChange removes the change from the queue.
+	RemoveQueuedChange(id uint64) error
 }
 
 // UserCredentialStore provides an interface to a backend that stores
@@ -57,6 +98,10 @@ type Settings struct {
 	APIKey string
 	// Credential holds the user's credential data.
 	Credential *Credential
+	// JournalTitle is the template for the title of the journal notes.
+	JournalTitle string
+	// JournalNotebook is the notebook the journal notes are created in.
+	JournalNotebook string
 }
 
 // Credential is a struct that holds credential information.
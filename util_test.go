@@ -22,21 +22,83 @@ import (
 )
 
 type mockNS struct {
-	findNotes       func(*NoteFilter, int, int) ([]*Note, error)
+	findNotes       func(*NoteFilter, int, int) (*NoteList, error)
 	getAllNotebooks func() ([]*Notebook, error)
 	getNoteContent  func(guid string) (string, error)
 	updateNote      func(n *Note) error
 	deleteNote      func(guid string) error
+	expungeNote     func(guid string) error
+	expungeInactive func() error
 	saveNewNote     func(n *Note) error
 	createNote      func(n *Note) error
 	updateNotebook  func(b *Notebook) error
+	expungeNotebook func(guid string) error
 	getNotebook     func(guid string) (*Notebook, error)
+	listTags        func() ([]*Tag, error)
+	createTag       func(t *Tag) error
+	updateTag       func(t *Tag) error
+	expungeTag      func(guid string) error
+	getResource     func(guid string) (*Resource, error)
+	getResourceData func(guid string) ([]byte, error)
+	getSyncState    func() (*SyncState, error)
+	getNote         func(guid string) (*Note, error)
+	getSyncChunk    func(afterUSN int32, maxEntries int) (*SyncChunk, error)
+	listVersions    func(guid string) ([]*NoteVersion, error)
+	getNoteVersion  func(guid string, usn int32) (*Note, error)
+}
+
+func (s *mockNS) ListNoteVersions(guid string) ([]*NoteVersion, error) {
+	return s.listVersions(guid)
+}
+
+func (s *mockNS) GetNoteVersion(guid string, usn int32) (*Note, error) {
+	return s.getNoteVersion(guid, usn)
+}
+
+func (s *mockNS) GetNote(guid string) (*Note, error) {
+	return s.getNote(guid)
+}
+
+func (s *mockNS) GetSyncState() (*SyncState, error) {
+	return s.getSyncState()
+}
+
+func (s *mockNS) GetSyncChunk(afterUSN int32, maxEntries int) (*SyncChunk, error) {
+	return s.getSyncChunk(afterUSN, maxEntries)
+}
+
+func (s *mockNS) GetResource(guid string) (*Resource, error) {
+	return s.getResource(guid)
+}
+
+func (s *mockNS) GetResourceData(guid string) ([]byte, error) {
+	return s.getResourceData(guid)
+}
+
+func (s *mockNS) ListTags() ([]*Tag, error) {
+	return s.listTags()
+}
+
+func (s *mockNS) CreateTag(t *Tag) error {
+	return s.createTag(t)
+}
+
+func (s *mockNS) UpdateTag(t *Tag) error {
+	return s.updateTag(t)
+}
+
+func (s *mockNS) ExpungeTag(guid string) error {
+	return s.expungeTag(guid)
 }
 
 func (s *mockNS) UpdateNotebook(b *Notebook) error {
 	return s.updateNotebook(b)
 }
 
+func (s *mockNS) ExpungeNotebook(guid string) error {
+	return s.expungeNotebook(guid)
+}
+
 func (s *mockNS) CreateNote(n *Note) error {
 	return s.createNote(n)
 }
@@ -49,6 +111,14 @@ func (s *mockNS) DeleteNote(guid string) error {
 	return s.deleteNote(guid)
 }
 
+func (s *mockNS) ExpungeNote(guid string) error {
+	return s.expungeNote(guid)
+}
+
+func (s *mockNS) ExpungeInactiveNotes() error {
+	return s.expungeInactive()
+}
+
 func (s *mockNS) UpdateNote(n *Note) error {
 	return s.updateNote(n)
 }
@@ -57,7 +127,7 @@ func (s *mockNS) GetNoteContent(guid string) (string, error) {
 	return s.getNoteContent(guid)
 }
 
-func (s *mockNS) FindNotes(filter *NoteFilter, offset int, count int) ([]*Note, error) {
+func (s *mockNS) FindNotes(filter *NoteFilter, offset int, count int) (*NoteList, error) {
 	return s.findNotes(filter, offset, count)
 }
 
@@ -74,23 +144,125 @@ func (s *mockNS) GetNotebook(guid string) (*Notebook, error) {
 }
 
 type mockStore struct {
-	getNotebookCache      func() (*NotebookCacheList, error)
-	storeNotebookList     func(list *NotebookCacheList) error
-	getSearch             func() ([]*Note, error)
-	saveNoteRecoveryPoint func(*Note) error
-	getNoteRecoveryPoint  func() (*Note, error)
+	getNotebookCache        func() (*NotebookCacheList, error)
+	storeNotebookList       func(list *NotebookCacheList) error
+	getSearch               func() ([]*Note, error)
+	saveSearch              func([]*Note) error
+	saveNoteRecoveryPoint   func(*Note, bool) error
+	getNoteRecoveryPoints   func() ([]*RecoveryPoint, error)
+	removeNoteRecoveryPoint func(string) error
+	saveNoteSnapshot        func(*NoteSnapshot) error
+	getNoteSnapshots        func(guid string) ([]*NoteSnapshot, error)
+	removeNoteSnapshot      func(id string) error
+	getLocalSyncState       func() (*LocalSyncState, error)
+	saveLocalSyncState      func(*LocalSyncState) error
+	saveLocalNote           func(*Note) error
+	getLocalNote            func(guid string) (*Note, error)
+	getLocalNotes           func() ([]*Note, error)
+	removeLocalNote         func(guid string) error
+	saveLocalNotebook       func(*Notebook) error
+	getLocalNotebooks       func() ([]*Notebook, error)
+	removeLocalNotebook     func(guid string) error
+	saveLocalTag            func(*Tag) error
+	getLocalTags            func() ([]*Tag, error)
+	removeLocalTag          func(guid string) error
+	clearLocalStore         func() error
+	queueChange             func(*Change) error
+	getQueuedChanges        func() ([]*Change, error)
+	removeQueuedChange      func(id uint64) error
+	getSettings             func() (*Settings, error)
 }
 
-func (m *mockStore) SaveNoteRecoveryPoint(n *Note) error {
-	return m.saveNoteRecoveryPoint(n)
+func (m *mockStore) QueueChange(c *Change) error {
+	return m.queueChange(c)
 }
 
-func (m *mockStore) GetNoteRecoveryPoint() (*Note, error) {
-	return m.getNoteRecoveryPoint()
+func (m *mockStore) GetQueuedChanges() ([]*Change, error) {
+	return m.getQueuedChanges()
 }
 
-func (m *mockStore) SaveSearch([]*Note) error {
-	panic("not implemented")
+func (m *mockStore) RemoveQueuedChange(id uint64) error {
+	return m.removeQueuedChange(id)
+}
+
+func (m *mockStore) GetLocalSyncState() (*LocalSyncState, error) {
+	return m.getLocalSyncState()
+}
+
+func (m *mockStore) SaveLocalSyncState(s *LocalSyncState) error {
+	return m.saveLocalSyncState(s)
+}
+
+func (m *mockStore) SaveLocalNote(n *Note) error {
+	return m.saveLocalNote(n)
+}
+
+func (m *mockStore) GetLocalNote(guid string) (*Note, error) {
+	return m.getLocalNote(guid)
+}
+
+func (m *mockStore) GetLocalNotes() ([]*Note, error) {
+	return m.getLocalNotes()
+}
+
+func (m *mockStore) RemoveLocalNote(guid string) error {
+	return m.removeLocalNote(guid)
+}
+
+func (m *mockStore) SaveLocalNotebook(b *Notebook) error {
+	return m.saveLocalNotebook(b)
+}
+
+func (m *mockStore) GetLocalNotebooks() ([]*Notebook, error) {
+	return m.getLocalNotebooks()
+}
+
+func (m *mockStore) RemoveLocalNotebook(guid string) error {
+	return m.removeLocalNotebook(guid)
+}
+
+func (m *mockStore) SaveLocalTag(t *Tag) error {
+	return m.saveLocalTag(t)
+}
+
+func (m *mockStore) GetLocalTags() ([]*Tag, error) {
+	return m.getLocalTags()
+}
+
+func (m *mockStore) RemoveLocalTag(guid string) error {
+	return m.removeLocalTag(guid)
+}
+
+func (m *mockStore) ClearLocalStore() error {
+	return m.clearLocalStore()
+}
+
+func (m *mockStore) SaveNoteRecoveryPoint(n *Note, raw bool) error {
+	return m.saveNoteRecoveryPoint(n, raw)
+}
+
+func (m *mockStore) GetNoteRecoveryPoints() ([]*RecoveryPoint, error) {
+	return m.getNoteRecoveryPoints()
+}
+
+func (m *mockStore) RemoveNoteRecoveryPoint(id string) error {
+	return m.removeNoteRecoveryPoint(id)
+}
+
+func (m *mockStore) SaveNoteSnapshot(s *NoteSnapshot) error {
+	return m.saveNoteSnapshot(s)
+}
+
+func (m *mockStore) GetNoteSnapshots(guid string) ([]*NoteSnapshot, error) {
+	return m.getNoteSnapshots(guid)
+}
+
+func (m *mockStore) RemoveNoteSnapshot(id string) error {
+	return m.removeNoteSnapshot(id)
+}
+
+func (m *mockStore) SaveSearch(notes []*Note) error {
+	return m.saveSearch(notes)
 }
 
 func (m *mockStore) GetSearch() ([]*Note, error) {
@@ -102,7 +274,7 @@ func (m *mockStore) Close() error {
 }
 
 func (m *mockStore) GetSettings() (*Settings, error) {
-	panic("not implemented")
+	return m.getSettings()
 }
 
 func (m *mockStore) StoreSettings(*Settings) error {
@@ -126,11 +298,12 @@ func (m *mockEditor) Edit(file CacheFile) error {
 }
 
 type mockCacheFile struct {
-	buffer *bytes.Buffer
-	write  func([]byte) (int, error)
-	read   func([]byte) (int, error)
-	close  func() error
-	reopen func() error
+	buffer  *bytes.Buffer
+	write   func([]byte) (int, error)
+	read    func([]byte) (int, error)
+	close   func() error
+	reopen  func() error
+	removed bool
 }
 
 func (m *mockCacheFile) Read(p []byte) (n int, err error) {
@@ -166,6 +339,7 @@ func (m *mockCacheFile) ReOpen() error {
 }
 
 func (m *mockCacheFile) CloseAndRemove() error {
+	m.removed = true
 	return nil
 }
 
@@ -0,0 +1,209 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TcM1911/clinote/markdown"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// CurrentVersion refers to the current version of the note.
+	CurrentVersion int32 = 0
+	// PreviousVersion refers to the most recent version saved by the server.
+	PreviousVersion int32 = -1
+	// diffContext is the number of unchanged lines shown around a change.
+	diffContext = 3
+)
+
+// ErrNoVersionFound is returned if the note doesn't have a matching version.
+var ErrNoVersionFound = errors.New("no note version found")
+
+// NoteVersion is a prior version of a note saved by the server.
+type NoteVersion struct {
+	// USN is the update sequence number of the note when the version was saved.
+	// It's used to identify the version.
+	USN int32
+	// Title is the note's title in the version.
+	Title string
+	// Updated is the time in milliseconds when the note was updated.
+	Updated int64
+	// Saved is the time in milliseconds when the version was saved.
+	Saved int64
+}
+
+// GetNoteVersions returns the prior versions of the note, the most recent first.
+// Evernote only keeps the versions for premium accounts.
+func GetNoteVersions(db Storager, ns NotestoreClient, title string) ([]*NoteVersion, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	return getNoteVersions(ns, n.GUID)
+}
+
+func getNoteVersions(ns NotestoreClient, guid string) ([]*NoteVersion, error) {
+	versions, err := ns.ListNoteVersions(guid)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].USN > versions[j].USN })
+	return versions, nil
+}
+
+// GetNoteVersion returns the version of the note with its content. The current
+// note is returned for CurrentVersion and the most recent saved version for PreviousVersion.
+func GetNoteVersion(db Storager, ns NotestoreClient, title string, usn int32) (*Note, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return nil, err
+	}
+	return getNoteVersion(ns, n, usn)
+}
+
+func getNoteVersion(ns NotestoreClient, n *Note, usn int32) (*Note, error) {
+	var content string
+	var err error
+	switch usn {
+	case CurrentVersion:
+		note := *n
+		n = &note
+		content, err = ns.GetNoteContent(n.GUID)
+	case PreviousVersion:
+		versions, err := getNoteVersions(ns, n.GUID)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, ErrNoVersionFound
+		}
+		return getNoteVersion(ns, n, versions[0].USN)
+	default:
+		n, err = ns.GetNoteVersion(n.GUID, usn)
+		if n != nil {
+			content = n.Body
+			n.USN = usn
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = decodeXML(content, n); err != nil {
+		return nil, err
+	}
+	if n.MD, err = markdown.FromENML(n.Body); err != nil {
+		return nil, err
+	}
+	if err = populateTagNames(ns, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// DiffNoteVersions returns a unified diff of the note between the two versions.
+// The versions are compared using the note header and the markdown content.
+func DiffNoteVersions(db Storager, ns NotestoreClient, title string, from, to int32) (string, error) {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return "", err
+	}
+	a, err := getNoteVersion(ns, n, from)
+	if err != nil {
+		return "", err
+	}
+	b, err := getNoteVersion(ns, n, to)
+	if err != nil {
+		return "", err
+	}
+	return UnifiedDiff(noteVersionText(a), noteVersionText(b), versionName(a, from), versionName(b, to))
+}
+
+// noteVersionText returns the note as it's shown when the note is edited.
+func noteVersionText(n *Note) string {
+	buf := new(bytes.Buffer)
+	writeNoteHeader(buf, n)
+	buf.WriteString(n.MD)
+	return buf.String()
+}
+
+// versionName returns the name of the version used in the diff.
+func versionName(n *Note, usn int32) string {
+	if usn == CurrentVersion {
+		return n.Title + " (current)"
+	}
+	return fmt.Sprintf("%s (version %d)", n.Title, n.USN)
+}
+
+// UnifiedDiff returns the unified diff between the texts. An empty string is
+// returned if the texts are equal.
+func UnifiedDiff(a, b, fromName, toName string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(a),
+		B:        diffLines(b),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  diffContext,
+	})
+}
+
+// diffLines splits the text into lines that all end with a new line.
+func diffLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// RestoreNoteVersion replaces the note's title and content with the version saved
+// by the server. Attachments that have been removed from the note since the version
+// was saved are added back.
+func RestoreNoteVersion(db Storager, ns NotestoreClient, title string, usn int32) error {
+	n, err := GetNote(db, ns, title, "")
+	if err != nil {
+		return err
+	}
+	if usn == CurrentVersion {
+		return ErrNoVersionFound
+	}
+	v, err := getNoteVersion(ns, n, usn)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(n.Resources))
+	for _, r := range n.Resources {
+		current[r.GUID] = true
+	}
+	for _, r := range v.Resources {
+		// Resources not in the note are uploaded again with the data from the version.
+		if !current[r.GUID] {
+			r.GUID = ""
+		}
+	}
+	n.Title = v.Title
+	n.Body = enmlDocument(v.Body)
+	n.Resources = v.Resources
+	return ns.UpdateNote(n)
+}
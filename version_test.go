@@ -0,0 +1,134 @@
+/*
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, see <http://www.gnu.org/licenses/>.
+ *
+ * Copyright (C) Joakim Kennedy, 2018
+ */
+
+package clinote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteVersions(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockStore{
+		getNotebookCache:  func() (*NotebookCacheList, error) { return &NotebookCacheList{Notebooks: []*Notebook{}}, nil },
+		storeNotebookList: func(list *NotebookCacheList) error { return nil },
+	}
+	newNS := func() *mockNS {
+		note := &Note{Title: "Note", GUID: "GUID", Notebook: &Notebook{GUID: "Notebook"}, Resources: []*Resource{&Resource{GUID: "Res1"}}}
+		ns := nsWithNote(note)
+		ns.listTags = func() ([]*Tag, error) { return nil, nil }
+		ns.listVersions = func(guid string) ([]*NoteVersion, error) {
+			return []*NoteVersion{&NoteVersion{USN: 10, Title: "Old"}, &NoteVersion{USN: 20, Title: "Note"}}, nil
+		}
+		ns.getNoteContent = func(guid string) (string, error) {
+			return XMLHeader + "<en-note><p>Line 1</p><p>Line 2</p><p>Line 3</p></en-note>", nil
+		}
+		ns.getNoteVersion = func(guid string, usn int32) (*Note, error) {
+			content := "<p>Line 1</p><p>Line 2</p>"
+			title := "Note"
+			if usn == 10 {
+				content = "<p>Line 1</p>"
+				title = "Old"
+			}
+			return &Note{
+				Title:     title,
+				GUID:      guid,
+				Body:      XMLHeader + "<en-note>" + content + "</en-note>",
+				Resources: []*Resource{&Resource{GUID: "Res1"}, &Resource{GUID: "Res2", Data: []byte("data")}},
+			}, nil
+		}
+		return ns
+	}
+
+	t.Run("list versions", func(t *testing.T) {
+		versions, err := GetNoteVersions(store, newNS(), "Note")
+		assert.NoError(err, "Should not return an error")
+		assert.Len(versions, 2, "Wrong number of versions")
+		assert.Equal(int32(20), versions[0].USN, "Most recent version should be first")
+	})
+	t.Run("get version", func(t *testing.T) {
+		n, err := GetNoteVersion(store, newNS(), "Note", 10)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Old", n.Title, "Wrong title")
+		assert.Equal("<p>Line 1</p>", n.Body, "Wrong content")
+		assert.Equal("Line 1", n.MD, "Wrong markdown")
+		assert.Equal(int32(10), n.USN, "Wrong version")
+	})
+	t.Run("diff with current note", func(t *testing.T) {
+		diff, err := DiffNoteVersions(store, newNS(), "Note", PreviousVersion, CurrentVersion)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal(`--- Note (version 20)
++++ Note (current)
+@@ -4,3 +4,5 @@
+ Line 1
+ 
+ Line 2
++
++Line 3
+`, diff, "Wrong diff")
+	})
+	t.Run("diff between versions", func(t *testing.T) {
+		diff, err := DiffNoteVersions(store, newNS(), "Note", 10, 20)
+		assert.NoError(err, "Should not return an error")
+		assert.Contains(diff, "-title: Old\n+title: Note\n", "Should include the title change")
+		assert.Contains(diff, "+Line 2\n", "Should include the added line")
+	})
+	t.Run("no prior versions", func(t *testing.T) {
+		ns := newNS()
+		ns.listVersions = func(string) ([]*NoteVersion, error) { return []*NoteVersion{}, nil }
+		_, err := DiffNoteVersions(store, ns, "Note", PreviousVersion, CurrentVersion)
+		assert.Equal(ErrNoVersionFound, err, "Wrong error returned")
+	})
+	t.Run("restore version", func(t *testing.T) {
+		ns := newNS()
+		var saved *Note
+		ns.updateNote = func(n *Note) error { saved = n; return nil }
+		err := RestoreNoteVersion(store, ns, "Note", 10)
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("GUID", saved.GUID, "Wrong note updated")
+		assert.Equal("Old", saved.Title, "Title should be restored")
+		assert.Equal(XMLHeader+"<en-note><p>Line 1</p></en-note>", saved.Body, "Content should be restored")
+		assert.Equal("Notebook", saved.Notebook.GUID, "Notebook should not be changed")
+		assert.Len(saved.Resources, 2, "Wrong number of resources")
+		assert.Equal("Res1", saved.Resources[0].GUID, "Existing resource should be kept")
+		assert.Equal("", saved.Resources[1].GUID, "Removed resource should be uploaded again")
+	})
+	t.Run("restore requires a version", func(t *testing.T) {
+		err := RestoreNoteVersion(store, newNS(), "Note", CurrentVersion)
+		assert.Equal(ErrNoVersionFound, err, "Wrong error returned")
+	})
+	t.Run("return error from notestore", func(t *testing.T) {
+		ns := newNS()
+		expectedErr := errors.New("expected error")
+		ns.listVersions = func(string) ([]*NoteVersion, error) { return nil, expectedErr }
+		_, err := GetNoteVersions(store, ns, "Note")
+		assert.Equal(expectedErr, err, "Wrong error returned")
+	})
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	assert := assert.New(t)
+	diff, err := UnifiedDiff("a\nb\n", "a\nc\n", "from", "to")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("--- from\n+++ to\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n", diff, "Wrong diff")
+	diff, err = UnifiedDiff("a\n", "a\n", "from", "to")
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("", diff, "Equal texts should not have a diff")
+}
@@ -18,50 +18,204 @@
 package clinote
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/olekukonko/tablewriter"
+	yaml "gopkg.in/yaml.v2"
 )
 
-const timeFormat = "2006-01-02"
+const (
+	timeFormat         = "2006-01-02"
+	recoveryTimeFormat = "2006-01-02 15:04:05"
+)
+
+// Output formats supported by the formatters.
+const (
+	// TableOutput is the default format, an ASCII table.
+	TableOutput = "table"
+	// JSONOutput is a JSON array of records, or an object for a single record.
+	JSONOutput = "json"
+	// JSONLinesOutput is one JSON object per line.
+	JSONLinesOutput = "jsonl"
+	// CSVOutput is comma separated values with a header line.
+	CSVOutput = "csv"
+	// TSVOutput is tab separated values with a header line.
+	TSVOutput = "tsv"
+	// YAMLOutput is a YAML sequence of records, or a mapping for a single record.
+	YAMLOutput = "yaml"
+)
+
+// ErrUnknownOutputFormat is returned if the output format is not supported.
+var ErrUnknownOutputFormat = errors.New("unknown output format")
 
 var (
 	noteListingHeader     = []string{"#", "Title", "Notebook", "Modified", "Created"}
 	notebookListingHeader = []string{"#", "Name"}
+	tagListingHeader      = []string{"#", "Name"}
+	resourceHeader        = []string{"#", "Filename", "Type", "Size", "Hash"}
+	recoveryHeader        = []string{"#", "Title", "Saved"}
+	versionHeader         = []string{"Version", "Title", "Updated", "Saved"}
+	snapshotHeader        = []string{"#", "Hash", "Title", "Notebook", "Saved"}
+	stackHeader           = []string{"#", "Stack", "Notebooks"}
+	reminderHeader        = []string{"#", "Title", "Notebook", "Due"}
+	todoHeader            = []string{"Note", "#", "Done", "Text"}
 	credentialHeader      = append(notebookListingHeader, "Type")
 	settingsHeader        = []string{"Setting", "Arguments", "Description"}
 )
 
 // WriteNoteListing creates and writes a note listing table using the writer.
 func WriteNoteListing(w io.Writer, ns []*Note, nbs []*Notebook) {
-	table := tablewriter.NewWriter(w)
-	table.SetHeader(noteListingHeader)
+	tableFormatter{}.Write(w, NewNoteListing(ns, 0, nbs))
+}
 
-	for i, n := range ns {
-		index := strconv.Itoa(i + 1)
-		created := time.Unix(int64(n.Created)/1000, 0).Format(timeFormat)
-		modified := time.Unix(int64(n.Updated)/1000, 0).Format(timeFormat)
-		notebook := ""
-		for _, nb := range nbs {
-			if nb.GUID == n.Notebook.GUID {
-				notebook = nb.Name
-				break
+// NoteListingWriter writes a note listing table one page at a time, so the notes
+// can be shown while the next page is requested from the notestore.
+type NoteListingWriter struct {
+	w      io.Writer
+	nbs    []*Notebook
+	widths []int
+}
+
+// NewNoteListingWriter returns a NoteListingWriter that writes to the writer.
+func NewNoteListingWriter(w io.Writer, nbs []*Notebook) *NoteListingWriter {
+	return &NoteListingWriter{w: w, nbs: nbs}
+}
+
+// WritePage writes the notes in the page as rows in the table. The header is
+// written with the first page. The columns are at least as wide as in the
+// earlier pages so the rows line up.
+func (lw *NoteListingWriter) WritePage(list *NoteList) {
+	table := tablewriter.NewWriter(lw.w)
+	if lw.widths == nil {
+		table.SetHeader(noteListingHeader)
+		lw.widths = make([]int, len(noteListingHeader))
+		lw.updateWidths(noteListingHeader)
+	} else {
+		table.SetBorders(tablewriter.Border{Left: true, Right: true, Bottom: true})
+	}
+	for i, w := range lw.widths {
+		table.SetColMinWidth(i, w)
+	}
+	for i, n := range list.Notes {
+		row := noteListingRow(list.StartIndex+i+1, n, lw.nbs)
+		lw.updateWidths(row)
+		table.Append(row)
+	}
+	table.Render()
+}
+
+func (lw *NoteListingWriter) updateWidths(row []string) {
+	for i, cell := range row {
+		// Long cells are wrapped by the table.
+		lines, _ := tablewriter.WrapString(cell, tablewriter.MAX_ROW_WIDTH)
+		for _, l := range lines {
+			if w := tablewriter.DisplayWidth(l); w > lw.widths[i] {
+				lw.widths[i] = w
 			}
 		}
-		table.Append([]string{index, n.Title, notebook, modified, created})
 	}
-	table.Render()
+}
+
+// withNotebook returns a copy of the note with the notebook replaced by the
+// matching notebook in the list. Search results only include the notebook GUID.
+func withNotebook(n *Note, nbs []*Notebook) *Note {
+	note := *n
+	if n.Notebook == nil {
+		return &note
+	}
+	for _, nb := range nbs {
+		if nb.GUID == n.Notebook.GUID {
+			note.Notebook = nb
+			break
+		}
+	}
+	return &note
+}
+
+// noteListingRow returns the row in the note listing for the note.
+func noteListingRow(index int, n *Note, nbs []*Notebook) []string {
+	created := time.Unix(int64(n.Created)/1000, 0).Format(timeFormat)
+	modified := time.Unix(int64(n.Updated)/1000, 0).Format(timeFormat)
+	notebook := ""
+	for _, nb := range nbs {
+		if nb.GUID == n.Notebook.GUID {
+			notebook = nb.Name
+			break
+		}
+	}
+	return []string{strconv.Itoa(index), n.Title, notebook, modified, created}
 }
 
 // WriteNotebookListing creates and writes a notebook listing table using the writer.
 func WriteNotebookListing(w io.Writer, nbs []*Notebook) {
+	tableFormatter{}.Write(w, NewNotebookListing(nbs))
+}
+
+// WriteNotebookTree writes the notebooks as a tree with the stacks as parents
+// of their notebooks.
+func WriteNotebookTree(w io.Writer, nbs []*Notebook) error {
+	for _, e := range notebookTree(nbs) {
+		if e.Stack == nil {
+			if _, err := fmt.Fprintln(w, e.Notebook.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintln(w, e.Stack.Name); err != nil {
+			return err
+		}
+		for i, nb := range e.Stack.Notebooks {
+			branch := "├── "
+			if i == len(e.Stack.Notebooks)-1 {
+				branch = "└── "
+			}
+			if _, err := fmt.Fprintln(w, branch+nb.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteTagListing creates and writes a tag listing table using the writer.
+func WriteTagListing(w io.Writer, tags []*Tag) {
 	table := tablewriter.NewWriter(w)
-	table.SetHeader(notebookListingHeader)
-	for i, nb := range nbs {
+	table.SetHeader(tagListingHeader)
+	for i, t := range tags {
+		index := strconv.Itoa(i + 1)
+		table.Append([]string{index, t.Name})
+	}
+	table.Render()
+}
+
+// WriteResourceListing creates and writes a resource listing table using the writer.
+func WriteResourceListing(w io.Writer, resources []*Resource) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(resourceHeader)
+	for i, r := range resources {
+		index := strconv.Itoa(i + 1)
+		table.Append([]string{index, r.Filename, r.Mime, strconv.Itoa(r.Size), r.Hash})
+	}
+	table.Render()
+}
+
+// WriteRecoveryListing creates and writes a recovery point listing table using the writer.
+func WriteRecoveryListing(w io.Writer, rps []*RecoveryPoint) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(recoveryHeader)
+	for i, rp := range rps {
 		index := strconv.Itoa(i + 1)
-		table.Append([]string{index, nb.Name})
+		saved := time.Unix(rp.Saved/1000, 0).Format(recoveryTimeFormat)
+		table.Append([]string{index, rp.Note.Title, saved})
 	}
 	table.Render()
 }
@@ -77,22 +231,7 @@ func WriteCredentialListingWithSecret(w io.Writer, creds []*Credential) {
 }
 
 func writeCredentialList(w io.Writer, creds []*Credential, includeToken bool) {
-	table := tablewriter.NewWriter(w)
-	header := credentialHeader
-	if includeToken {
-		header = append(header, "Secret")
-	}
-	table.SetHeader(header)
-
-	for i, cred := range creds {
-		index := strconv.Itoa(i + 1)
-		line := []string{index, cred.Name, cred.CredType.String()}
-		if includeToken {
-			line = append(line, cred.Secret)
-		}
-		table.Append(line)
-	}
-	table.Render()
+	tableFormatter{}.Write(w, NewCredentialListing(creds, includeToken))
 }
 
 // WriteSettingsListing writes the settings table to writer.
@@ -107,3 +246,565 @@ func WriteSettingsListing(w io.Writer, vals, args, desc []string) {
 	}
 	table.Render()
 }
+
+// Record is an entry in a listing. The record is encoded with its json and
+// yaml tags by the JSON and YAML formatters.
+type Record interface {
+	// Values returns the record's values in the same order as the listing's fields.
+	Values() []string
+}
+
+// Listing is the data written by a Formatter.
+type Listing struct {
+	// Header is the header of the table. The fields are used if it's not set.
+	Header []string
+	// Rows are the rows of the table. The record values are used if it's not set.
+	Rows [][]string
+	// Fields are the names of the record fields, used as the header of the
+	// delimited formats.
+	Fields []string
+	// Records are the entries in the listing.
+	Records []Record
+	// Single is set if the listing holds one record that should be written
+	// as an object instead of a list.
+	Single bool
+	// Items are the values the template formatter executes the template on.
+	Items []interface{}
+}
+
+// Formatter writes a listing in an output format.
+type Formatter interface {
+	// Write writes the listing to the writer.
+	Write(w io.Writer, l *Listing) error
+}
+
+// NewFormatter returns the formatter for the output format.
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case TableOutput:
+		return tableFormatter{}, nil
+	case JSONOutput:
+		return jsonFormatter{}, nil
+	case JSONLinesOutput:
+		return jsonLinesFormatter{}, nil
+	case CSVOutput:
+		return delimitedFormatter{comma: ','}, nil
+	case TSVOutput:
+		return delimitedFormatter{comma: '\t'}, nil
+	case YAMLOutput:
+		return yamlFormatter{}, nil
+	}
+	return nil, ErrUnknownOutputFormat
+}
+
+// templateFormatter executes the template for each item in the listing.
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+// templateEscapes are the escape sequences interpreted in the output template.
+var templateEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// templateDateFormat is the date format used if no format is given to the date function.
+const templateDateFormat = "2006-01-02"
+
+// templateFuncs are the helper functions available in the output template.
+var templateFuncs = template.FuncMap{
+	"date":     templateDate,
+	"truncate": templateTruncate,
+	"join":     templateJoin,
+}
+
+// NewTemplateFormatter returns a formatter that executes the Go template for each
+// item in the listing, for example a Note or a Notebook. Each item is written on
+// its own line and the escape sequences \t and \n can be used in the template.
+// The template can use the date, truncate and join functions:
+//
+//	{{.Updated | date}}, {{date "2006-01-02 15:04" .Updated}}
+//	{{.Title | truncate 20}}
+//	{{.Tags | join ", "}}
+func NewTemplateFormatter(text string) (Formatter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(templateEscapes.Replace(text))
+	if err != nil {
+		return nil, err
+	}
+	return templateFormatter{tmpl: tmpl}, nil
+}
+
+func (f templateFormatter) Write(w io.Writer, l *Listing) error {
+	for _, item := range l.Items {
+		if err := f.tmpl.Execute(w, item); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateDate formats the timestamp in milliseconds as a date in the local
+// time zone. The layout can be given before the timestamp.
+func templateDate(args ...interface{}) (string, error) {
+	layout := templateDateFormat
+	switch len(args) {
+	case 1:
+	case 2:
+		s, ok := args[0].(string)
+		if !ok {
+			return "", fmt.Errorf("date layout has to be a string, got %T", args[0])
+		}
+		layout = s
+	default:
+		return "", fmt.Errorf("date expects a timestamp and an optional layout, got %d arguments", len(args))
+	}
+	var ts int64
+	switch v := args[len(args)-1].(type) {
+	case int64:
+		ts = v
+	case int:
+		ts = int64(v)
+	default:
+		return "", fmt.Errorf("date expects a timestamp, got %T", v)
+	}
+	if ts == 0 {
+		return "", nil
+	}
+	return time.Unix(ts/1000, (ts%1000)*int64(time.Millisecond)).Format(layout), nil
+}
+
+// templateTruncate shortens the string to at most n characters. An ellipsis
+// replaces the end of the string if it's shortened.
+func templateTruncate(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	if n <= len(ellipsis) {
+		return string(r[:n])
+	}
+	return string(r[:n-len(ellipsis)]) + ellipsis
+}
+
+const ellipsis = "..."
+
+// templateJoin joins the strings or the names of the tags with the separator.
+func templateJoin(sep string, v interface{}) (string, error) {
+	switch a := v.(type) {
+	case []string:
+		return strings.Join(a, sep), nil
+	case []*Tag:
+		return strings.Join(tagNames(a), sep), nil
+	}
+	return "", fmt.Errorf("join expects a list of strings or tags, got %T", v)
+}
+
+type tableFormatter struct{}
+
+func (tableFormatter) Write(w io.Writer, l *Listing) error {
+	table := tablewriter.NewWriter(w)
+	header, rows := l.Header, l.Rows
+	if header == nil {
+		header = l.Fields
+		rows = make([][]string, len(l.Records))
+		for i, r := range l.Records {
+			rows[i] = r.Values()
+		}
+	}
+	table.SetHeader(header)
+	table.AppendBulk(rows)
+	table.Render()
+	return nil
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Write(w io.Writer, l *Listing) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l.data())
+}
+
+type jsonLinesFormatter struct{}
+
+func (jsonLinesFormatter) Write(w io.Writer, l *Listing) error {
+	enc := json.NewEncoder(w)
+	for _, r := range l.Records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type delimitedFormatter struct {
+	comma rune
+}
+
+func (f delimitedFormatter) Write(w io.Writer, l *Listing) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = f.comma
+	if err := cw.Write(l.Fields); err != nil {
+		return err
+	}
+	for _, r := range l.Records {
+		if err := cw.Write(r.Values()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) Write(w io.Writer, l *Listing) error {
+	data, err := yaml.Marshal(l.data())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// data returns the value encoded by the structured formats.
+func (l *Listing) data() interface{} {
+	if l.Single && len(l.Records) == 1 {
+		return l.Records[0]
+	}
+	if l.Records == nil {
+		return []Record{}
+	}
+	return l.Records
+}
+
+// formatRecordTime converts the timestamp in milliseconds to RFC 3339 in UTC.
+func formatRecordTime(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts/1000, (ts%1000)*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
+
+var noteRecordFields = []string{"index", "guid", "title", "notebook", "notebook_guid", "created", "updated"}
+
+// noteRecord is a note in the note listing.
+type noteRecord struct {
+	Index        int    `json:"index" yaml:"index"`
+	GUID         string `json:"guid" yaml:"guid"`
+	Title        string `json:"title" yaml:"title"`
+	Notebook     string `json:"notebook" yaml:"notebook"`
+	NotebookGUID string `json:"notebook_guid" yaml:"notebook_guid"`
+	Created      string `json:"created" yaml:"created"`
+	Updated      string `json:"updated" yaml:"updated"`
+}
+
+func (r *noteRecord) Values() []string {
+	return []string{strconv.Itoa(r.Index), r.GUID, r.Title, r.Notebook, r.NotebookGUID, r.Created, r.Updated}
+}
+
+// NewNoteListing returns the listing of the notes. The index of the first
+// note is start + 1. The notebooks are used to look up the notebook names.
+func NewNoteListing(ns []*Note, start int, nbs []*Notebook) *Listing {
+	l := &Listing{Header: noteListingHeader, Fields: noteRecordFields}
+	for i, n := range ns {
+		l.Items = append(l.Items, withNotebook(n, nbs))
+		row := noteListingRow(start+i+1, n, nbs)
+		l.Rows = append(l.Rows, row)
+		r := &noteRecord{
+			Index:    start + i + 1,
+			GUID:     n.GUID,
+			Title:    n.Title,
+			Notebook: row[2],
+			Created:  formatRecordTime(n.Created),
+			Updated:  formatRecordTime(n.Updated),
+		}
+		if n.Notebook != nil {
+			r.NotebookGUID = n.Notebook.GUID
+		}
+		l.Records = append(l.Records, r)
+	}
+	return l
+}
+
+var notebookRecordFields = []string{"index", "guid", "name", "stack"}
+
+// notebookRecord is a notebook in the notebook listing.
+type notebookRecord struct {
+	Index int    `json:"index" yaml:"index"`
+	GUID  string `json:"guid" yaml:"guid"`
+	Name  string `json:"name" yaml:"name"`
+	Stack string `json:"stack" yaml:"stack"`
+}
+
+func (r *notebookRecord) Values() []string {
+	return []string{strconv.Itoa(r.Index), r.GUID, r.Name, r.Stack}
+}
+
+// NewNotebookListing returns the listing of the notebooks.
+func NewNotebookListing(nbs []*Notebook) *Listing {
+	l := &Listing{Header: notebookListingHeader, Fields: notebookRecordFields}
+	for i, nb := range nbs {
+		l.Items = append(l.Items, nb)
+		l.Rows = append(l.Rows, []string{strconv.Itoa(i + 1), nb.Name})
+		l.Records = append(l.Records, &notebookRecord{Index: i + 1, GUID: nb.GUID, Name: nb.Name, Stack: nb.Stack})
+	}
+	return l
+}
+
+var stackRecordFields = []string{"index", "name", "notebooks"}
+
+// stackRecord is a stack in the stack listing.
+type stackRecord struct {
+	Index     int      `json:"index" yaml:"index"`
+	Name      string   `json:"name" yaml:"name"`
+	Notebooks []string `json:"notebooks" yaml:"notebooks"`
+}
+
+func (r *stackRecord) Values() []string {
+	return []string{strconv.Itoa(r.Index), r.Name, strings.Join(r.Notebooks, tagSep)}
+}
+
+// NewStackListing returns the listing of the stacks.
+func NewStackListing(stacks []*Stack) *Listing {
+	l := &Listing{Header: stackHeader, Fields: stackRecordFields}
+	for i, s := range stacks {
+		names := make([]string, len(s.Notebooks))
+		for j, nb := range s.Notebooks {
+			names[j] = nb.Name
+		}
+		l.Items = append(l.Items, s)
+		l.Rows = append(l.Rows, []string{strconv.Itoa(i + 1), s.Name, strings.Join(names, ", ")})
+		l.Records = append(l.Records, &stackRecord{Index: i + 1, Name: s.Name, Notebooks: names})
+	}
+	return l
+}
+
+var credentialRecordFields = []string{"index", "name", "type"}
+
+// credentialRecord is a credential in the credential listing.
+type credentialRecord struct {
+	Index  int    `json:"index" yaml:"index"`
+	Name   string `json:"name" yaml:"name"`
+	Type   string `json:"type" yaml:"type"`
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	// withSecret is set if the secret is included in the listing.
+	withSecret bool
+}
+
+func (r *credentialRecord) Values() []string {
+	v := []string{strconv.Itoa(r.Index), r.Name, r.Type}
+	if r.withSecret {
+		v = append(v, r.Secret)
+	}
+	return v
+}
+
+// NewCredentialListing returns the listing of the credentials. The secrets
+// are only included if includeSecret is true.
+func NewCredentialListing(creds []*Credential, includeSecret bool) *Listing {
+	l := &Listing{Header: credentialHeader, Fields: credentialRecordFields}
+	if includeSecret {
+		l.Header = append(l.Header, "Secret")
+		l.Fields = append(l.Fields, "secret")
+	}
+	for i, cred := range creds {
+		r := &credentialRecord{Index: i + 1, Name: cred.Name, Type: cred.CredType.String()}
+		if includeSecret {
+			r.Secret = cred.Secret
+			r.withSecret = true
+		}
+		l.Rows = append(l.Rows, r.Values())
+		l.Records = append(l.Records, r)
+	}
+	return l
+}
+
+var noteVersionRecordFields = []string{"version", "title", "updated", "saved"}
+
+// noteVersionRecord is a version in the note history listing.
+type noteVersionRecord struct {
+	Version int32  `json:"version" yaml:"version"`
+	Title   string `json:"title" yaml:"title"`
+	Updated string `json:"updated" yaml:"updated"`
+	Saved   string `json:"saved" yaml:"saved"`
+}
+
+func (r *noteVersionRecord) Values() []string {
+	return []string{strconv.Itoa(int(r.Version)), r.Title, r.Updated, r.Saved}
+}
+
+// NewNoteVersionListing returns the listing of the note versions.
+func NewNoteVersionListing(versions []*NoteVersion) *Listing {
+	l := &Listing{Header: versionHeader, Fields: noteVersionRecordFields}
+	for _, v := range versions {
+		l.Items = append(l.Items, v)
+		l.Rows = append(l.Rows, []string{
+			strconv.Itoa(int(v.USN)),
+			v.Title,
+			time.Unix(v.Updated/1000, 0).Format(recoveryTimeFormat),
+			time.Unix(v.Saved/1000, 0).Format(recoveryTimeFormat),
+		})
+		l.Records = append(l.Records, &noteVersionRecord{
+			Version: v.USN,
+			Title:   v.Title,
+			Updated: formatRecordTime(v.Updated),
+			Saved:   formatRecordTime(v.Saved),
+		})
+	}
+	return l
+}
+
+// shortHashLength is the number of characters of the hash shown in the table.
+const shortHashLength = 7
+
+var noteSnapshotRecordFields = []string{"index", "hash", "title", "notebook", "saved"}
+
+// noteSnapshotRecord is a snapshot in the note log listing.
+type noteSnapshotRecord struct {
+	Index    int    `json:"index" yaml:"index"`
+	Hash     string `json:"hash" yaml:"hash"`
+	Title    string `json:"title" yaml:"title"`
+	Notebook string `json:"notebook" yaml:"notebook"`
+	Saved    string `json:"saved" yaml:"saved"`
+}
+
+func (r *noteSnapshotRecord) Values() []string {
+	return []string{strconv.Itoa(r.Index), r.Hash, r.Title, r.Notebook, r.Saved}
+}
+
+// NewNoteSnapshotListing returns the listing of the note snapshots.
+func NewNoteSnapshotListing(snapshots []*NoteSnapshot) *Listing {
+	l := &Listing{Header: snapshotHeader, Fields: noteSnapshotRecordFields}
+	for i, s := range snapshots {
+		hash := s.Hash
+		if len(hash) > shortHashLength {
+			hash = hash[:shortHashLength]
+		}
+		l.Items = append(l.Items, s)
+		l.Rows = append(l.Rows, []string{
+			strconv.Itoa(i + 1),
+			hash,
+			s.Title,
+			s.Notebook,
+			time.Unix(s.Saved/1000, 0).Format(recoveryTimeFormat),
+		})
+		l.Records = append(l.Records, &noteSnapshotRecord{
+			Index:    i + 1,
+			Hash:     s.Hash,
+			Title:    s.Title,
+			Notebook: s.Notebook,
+			Saved:    formatRecordTime(s.Saved),
+		})
+	}
+	return l
+}
+
+var noteDetailsFields = []string{"guid", "title", "notebook", "notebook_guid", "tags", "created", "updated", "content"}
+
+// noteDetails is a note with its metadata and content.
+type noteDetails struct {
+	GUID         string   `json:"guid" yaml:"guid"`
+	Title        string   `json:"title" yaml:"title"`
+	Notebook     string   `json:"notebook" yaml:"notebook"`
+	NotebookGUID string   `json:"notebook_guid" yaml:"notebook_guid"`
+	Tags         []string `json:"tags" yaml:"tags"`
+	Created      string   `json:"created" yaml:"created"`
+	Updated      string   `json:"updated" yaml:"updated"`
+	Content      string   `json:"content" yaml:"content"`
+}
+
+func (r *noteDetails) Values() []string {
+	return []string{r.GUID, r.Title, r.Notebook, r.NotebookGUID, strings.Join(r.Tags, tagSep), r.Created, r.Updated, r.Content}
+}
+
+// NewNoteDetails returns a listing with the note's metadata and content. The
+// content is the markdown unless the RawNote option is set.
+func NewNoteDetails(n *Note, opts NoteOption) *Listing {
+	r := &noteDetails{
+		GUID:    n.GUID,
+		Title:   n.Title,
+		Tags:    tagNames(n.Tags),
+		Created: formatRecordTime(n.Created),
+		Updated: formatRecordTime(n.Updated),
+		Content: n.MD,
+	}
+	if n.Notebook != nil {
+		r.Notebook = n.Notebook.Name
+		r.NotebookGUID = n.Notebook.GUID
+	}
+	if opts&RawNote != 0 {
+		r.Content = n.Body
+	}
+	return &Listing{Fields: noteDetailsFields, Records: []Record{r}, Single: true}
+}
+
+var reminderRecordFields = []string{"index", "guid", "title", "notebook", "notebook_guid", "due"}
+
+// reminderRecord is a note in the reminder listing.
+type reminderRecord struct {
+	Index        int    `json:"index" yaml:"index"`
+	GUID         string `json:"guid" yaml:"guid"`
+	Title        string `json:"title" yaml:"title"`
+	Notebook     string `json:"notebook" yaml:"notebook"`
+	NotebookGUID string `json:"notebook_guid" yaml:"notebook_guid"`
+	Due          string `json:"due" yaml:"due"`
+}
+
+func (r *reminderRecord) Values() []string {
+	return []string{strconv.Itoa(r.Index), r.GUID, r.Title, r.Notebook, r.NotebookGUID, r.Due}
+}
+
+// NewReminderListing returns the listing of the notes with reminders.
+func NewReminderListing(ns []*Note, nbs []*Notebook) *Listing {
+	l := &Listing{Header: reminderHeader, Fields: reminderRecordFields}
+	for i, n := range ns {
+		note := withNotebook(n, nbs)
+		r := &reminderRecord{Index: i + 1, GUID: n.GUID, Title: n.Title}
+		if note.Notebook != nil {
+			r.Notebook = note.Notebook.Name
+			r.NotebookGUID = note.Notebook.GUID
+		}
+		due := ""
+		if n.Attributes != nil && n.Attributes.ReminderTime != 0 {
+			ts := n.Attributes.ReminderTime
+			due = time.Unix(ts/1000, 0).Format(ReminderTimeFormat)
+			r.Due = formatRecordTime(ts)
+		}
+		l.Items = append(l.Items, note)
+		l.Rows = append(l.Rows, []string{strconv.Itoa(i + 1), n.Title, r.Notebook, due})
+		l.Records = append(l.Records, r)
+	}
+	return l
+}
+
+var todoRecordFields = []string{"note", "note_guid", "index", "checked", "text"}
+
+// todoRecord is a checkbox in the checkbox listing.
+type todoRecord struct {
+	Note     string `json:"note" yaml:"note"`
+	NoteGUID string `json:"note_guid" yaml:"note_guid"`
+	Index    int    `json:"index" yaml:"index"`
+	Checked  bool   `json:"checked" yaml:"checked"`
+	Text     string `json:"text" yaml:"text"`
+}
+
+func (r *todoRecord) Values() []string {
+	return []string{r.Note, r.NoteGUID, strconv.Itoa(r.Index), strconv.FormatBool(r.Checked), r.Text}
+}
+
+// NewTodoListing returns the listing of the checkboxes. The index is the
+// checkbox's position in its note.
+func NewTodoListing(items []*TodoItem) *Listing {
+	l := &Listing{Header: todoHeader, Fields: todoRecordFields}
+	for _, t := range items {
+		done := ""
+		if t.Checked {
+			done = "x"
+		}
+		l.Items = append(l.Items, t)
+		l.Rows = append(l.Rows, []string{t.Note.Title, strconv.Itoa(t.Index), done, t.Text})
+		l.Records = append(l.Records, &todoRecord{Note: t.Note.Title, NoteGUID: t.Note.GUID, Index: t.Index, Checked: t.Checked, Text: t.Text})
+	}
+	return l
+}
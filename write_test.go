@@ -19,7 +19,9 @@ package clinote
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -50,6 +52,87 @@ func TestWritingNoteAndNotebookTables(t *testing.T) {
 	})
 }
 
+func TestNotebookTree(t *testing.T) {
+	assert := assert.New(t)
+	nbs := []*Notebook{
+		&Notebook{Name: "Work", Stack: "Projects"},
+		&Notebook{Name: "Inbox"},
+		&Notebook{Name: "Clients", Stack: "Projects"},
+		&Notebook{Name: "Recipes", Stack: "Home"},
+	}
+	buf := new(bytes.Buffer)
+	err := WriteNotebookTree(buf, nbs)
+	assert.NoError(err, "Should not return an error")
+	assert.Equal("Home\n└── Recipes\nInbox\nProjects\n├── Clients\n└── Work\n", buf.String(), "Wrong tree")
+}
+
+func TestNoteListingWriter(t *testing.T) {
+	assert := assert.New(t)
+	nbs := []*Notebook{&Notebook{GUID: "GUID1", Name: "Notebook1"}}
+	note := func(title string) *Note {
+		return &Note{Title: title, Notebook: &Notebook{GUID: "GUID1"}}
+	}
+	buf := new(bytes.Buffer)
+	w := NewNoteListingWriter(buf, nbs)
+	w.WritePage(&NoteList{Notes: []*Note{note("Note1"), note("Note2")}, StartIndex: 8, TotalNotes: 11})
+	w.WritePage(&NoteList{Notes: []*Note{note("Longer note title")}, StartIndex: 10, TotalNotes: 12})
+	w.WritePage(&NoteList{Notes: []*Note{note("Note4")}, StartIndex: 11, TotalNotes: 12})
+	out := buf.String()
+	assert.Equal(1, strings.Count(out, "| TITLE"), "Header should only be written once")
+	lines := strings.Split(out, "\n")
+	assert.Len(lines, 11, "Wrong number of lines")
+	assert.True(strings.HasPrefix(lines[3], "|  9 | Note1 | Notebook1 |"), "Missing first note")
+	assert.True(strings.HasPrefix(lines[4], "| 10 | Note2 | Notebook1 |"), "Missing second note")
+	assert.True(strings.HasPrefix(lines[6], "| 11 | Longer note title | Notebook1 |"), "Second page should continue the table")
+	assert.True(strings.HasPrefix(lines[8], "| 12 | Note4             | Notebook1 |"), "Columns should keep the width")
+}
+
+func TestTagTable(t *testing.T) {
+	assert := assert.New(t)
+	buf := new(bytes.Buffer)
+	WriteTagListing(buf, []*Tag{&Tag{Name: "Tag1"}, &Tag{Name: "Tag2"}})
+	assert.Equal(expectedTagList, buf.String(), "Tag list table doesn't match")
+}
+
+func TestRecoveryTable(t *testing.T) {
+	assert := assert.New(t)
+	buf := new(bytes.Buffer)
+	WriteRecoveryListing(buf, []*RecoveryPoint{
+		&RecoveryPoint{Note: &Note{Title: "Note1"}, Saved: 1000},
+		&RecoveryPoint{Note: &Note{Title: "Note2"}, Saved: 2000},
+	})
+	out := buf.String()
+	assert.Contains(out, "| # | TITLE |", "Missing header")
+	assert.Contains(out, "| 1 | Note1 |", "Missing first recovery point")
+	assert.Contains(out, "| 2 | Note2 |", "Missing second recovery point")
+}
+
+func TestReminderListing(t *testing.T) {
+	assert := assert.New(t)
+	due := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	nbs := []*Notebook{&Notebook{GUID: "GUID", Name: "Notebook"}}
+	notes := []*Note{
+		&Note{Title: "Note1", Notebook: &Notebook{GUID: "GUID"}, Attributes: &NoteAttributes{ReminderOrder: 1, ReminderTime: due.UnixNano() / int64(time.Millisecond)}},
+		&Note{Title: "Note2", Notebook: &Notebook{GUID: "GUID"}, Attributes: &NoteAttributes{ReminderOrder: 2}},
+	}
+	l := NewReminderListing(notes, nbs)
+	assert.Equal([]string{"1", "Note1", "Notebook", due.Local().Format(ReminderTimeFormat)}, l.Rows[0], "Wrong first row")
+	assert.Equal([]string{"2", "Note2", "Notebook", ""}, l.Rows[1], "Wrong second row")
+	assert.Equal("2026-11-01T09:00:00Z", l.Records[0].(*reminderRecord).Due, "Wrong due time in the record")
+	assert.Equal(nbs[0], l.Items[0].(*Note).Notebook, "Notebook should be resolved")
+}
+
+func TestTodoListing(t *testing.T) {
+	assert := assert.New(t)
+	n := &Note{Title: "Shopping list", GUID: "GUID"}
+	l := NewTodoListing([]*TodoItem{
+		&TodoItem{Note: n, Index: 1, Text: "Milk", Checked: true},
+		&TodoItem{Note: n, Index: 2, Text: "Bread"},
+	})
+	assert.Equal([][]string{{"Shopping list", "1", "x", "Milk"}, {"Shopping list", "2", "", "Bread"}}, l.Rows, "Wrong rows")
+	assert.Equal([]string{"Shopping list", "GUID", "2", "false", "Bread"}, l.Records[1].Values(), "Wrong record")
+}
+
 func TestCredentialTable(t *testing.T) {
 	assert := assert.New(t)
 	creds := []*Credential{
@@ -94,6 +177,13 @@ const expectedNotelist = `+---+-------+-----------+------------+------------+
 | 3 | Note3 | Notebook3 | 1970-01-01 | 1970-01-01 |
 +---+-------+-----------+------------+------------+
 `
+const expectedTagList = `+---+------+
+| # | NAME |
++---+------+
+| 1 | Tag1 |
+| 2 | Tag2 |
++---+------+
+`
 const expectedCredentialList = `+---+-------+------------------+
 | # | NAME  |       TYPE       |
 +---+-------+------------------+
@@ -117,3 +207,201 @@ const expectedSettingList = `+------------+-----------------+-------------------
 |            |                 | the user.                      |
 +------------+-----------------+--------------------------------+
 `
+
+func TestFormatters(t *testing.T) {
+	assert := assert.New(t)
+	nbs := []*Notebook{&Notebook{GUID: "GUID1", Name: "Notebook1", Stack: "Stack"}}
+	notes := []*Note{
+		&Note{GUID: "Note1", Title: "Note, one", Notebook: &Notebook{GUID: "GUID1"}, Created: 1500000000000, Updated: 1500000001000},
+		&Note{GUID: "Note2", Title: "Note two", Notebook: &Notebook{GUID: "GUID1"}},
+	}
+	write := func(format string, l *Listing) string {
+		f, err := NewFormatter(format)
+		assert.NoError(err, "Should not return an error for %s", format)
+		buf := new(bytes.Buffer)
+		assert.NoError(f.Write(buf, l), "Should not return an error for %s", format)
+		return buf.String()
+	}
+
+	t.Run("note listing", func(t *testing.T) {
+		l := NewNoteListing(notes, 10, nbs)
+		assert.Equal(`[
+  {
+    "index": 11,
+    "guid": "Note1",
+    "title": "Note, one",
+    "notebook": "Notebook1",
+    "notebook_guid": "GUID1",
+    "created": "2017-07-14T02:40:00Z",
+    "updated": "2017-07-14T02:40:01Z"
+  },
+  {
+    "index": 12,
+    "guid": "Note2",
+    "title": "Note two",
+    "notebook": "Notebook1",
+    "notebook_guid": "GUID1",
+    "created": "",
+    "updated": ""
+  }
+]
+`, write(JSONOutput, l), "Wrong JSON")
+		assert.Equal(`{"index":11,"guid":"Note1","title":"Note, one","notebook":"Notebook1","notebook_guid":"GUID1","created":"2017-07-14T02:40:00Z","updated":"2017-07-14T02:40:01Z"}
+{"index":12,"guid":"Note2","title":"Note two","notebook":"Notebook1","notebook_guid":"GUID1","created":"","updated":""}
+`, write(JSONLinesOutput, l), "Wrong JSON lines")
+		assert.Equal(`index,guid,title,notebook,notebook_guid,created,updated
+11,Note1,"Note, one",Notebook1,GUID1,2017-07-14T02:40:00Z,2017-07-14T02:40:01Z
+12,Note2,Note two,Notebook1,GUID1,,
+`, write(CSVOutput, l), "Wrong CSV")
+		assert.Equal("index\tguid\ttitle\tnotebook\tnotebook_guid\tcreated\tupdated\n"+
+			"11\tNote1\tNote, one\tNotebook1\tGUID1\t2017-07-14T02:40:00Z\t2017-07-14T02:40:01Z\n"+
+			"12\tNote2\tNote two\tNotebook1\tGUID1\t\t\n", write(TSVOutput, l), "Wrong TSV")
+		assert.Equal(`- index: 11
+  guid: Note1
+  title: Note, one
+  notebook: Notebook1
+  notebook_guid: GUID1
+  created: "2017-07-14T02:40:00Z"
+  updated: "2017-07-14T02:40:01Z"
+- index: 12
+  guid: Note2
+  title: Note two
+  notebook: Notebook1
+  notebook_guid: GUID1
+  created: ""
+  updated: ""
+`, write(YAMLOutput, l), "Wrong YAML")
+		assert.Contains(write(TableOutput, l), "| 11 | Note, one | Notebook1 |", "Wrong table")
+	})
+
+	t.Run("empty listing", func(t *testing.T) {
+		l := NewNoteListing(nil, 0, nbs)
+		assert.Equal("[]\n", write(JSONOutput, l), "Wrong JSON")
+		assert.Equal("", write(JSONLinesOutput, l), "Wrong JSON lines")
+		assert.Equal("index,guid,title,notebook,notebook_guid,created,updated\n", write(CSVOutput, l), "Wrong CSV")
+		assert.Equal("[]\n", write(YAMLOutput, l), "Wrong YAML")
+	})
+
+	t.Run("notebook listing", func(t *testing.T) {
+		l := NewNotebookListing(nbs)
+		assert.Equal(`[
+  {
+    "index": 1,
+    "guid": "GUID1",
+    "name": "Notebook1",
+    "stack": "Stack"
+  }
+]
+`, write(JSONOutput, l), "Wrong JSON")
+		assert.Equal("index,guid,name,stack\n1,GUID1,Notebook1,Stack\n", write(CSVOutput, l), "Wrong CSV")
+	})
+
+	t.Run("credential listing", func(t *testing.T) {
+		creds := []*Credential{&Credential{Name: "Cred1", Secret: "secret", CredType: EvernoteCredential}}
+		l := NewCredentialListing(creds, false)
+		assert.Equal(`{"index":1,"name":"Cred1","type":"Evernote"}`+"\n", write(JSONLinesOutput, l), "Wrong JSON lines")
+		assert.Equal("index,name,type\n1,Cred1,Evernote\n", write(CSVOutput, l), "Wrong CSV")
+		l = NewCredentialListing(creds, true)
+		assert.Equal(`{"index":1,"name":"Cred1","type":"Evernote","secret":"secret"}`+"\n", write(JSONLinesOutput, l), "Wrong JSON lines")
+		assert.Equal("index,name,type,secret\n1,Cred1,Evernote,secret\n", write(CSVOutput, l), "Wrong CSV")
+	})
+
+	t.Run("note details", func(t *testing.T) {
+		n := &Note{
+			GUID:     "Note1",
+			Title:    "Note",
+			Notebook: &Notebook{GUID: "GUID1", Name: "Notebook1"},
+			Tags:     []*Tag{&Tag{Name: "Tag1"}, &Tag{Name: "Tag2"}},
+			Created:  1500000000000,
+			Body:     "<p>Content</p>",
+			MD:       "Content",
+		}
+		assert.Equal(`{
+  "guid": "Note1",
+  "title": "Note",
+  "notebook": "Notebook1",
+  "notebook_guid": "GUID1",
+  "tags": [
+    "Tag1",
+    "Tag2"
+  ],
+  "created": "2017-07-14T02:40:00Z",
+  "updated": "",
+  "content": "Content"
+}
+`, write(JSONOutput, NewNoteDetails(n, DefaultNoteOption)), "Wrong JSON")
+		assert.Equal(`guid: Note1
+title: Note
+notebook: Notebook1
+notebook_guid: GUID1
+tags:
+- Tag1
+- Tag2
+created: "2017-07-14T02:40:00Z"
+updated: ""
+content: <p>Content</p>
+`, write(YAMLOutput, NewNoteDetails(n, RawNote)), "Wrong YAML")
+		assert.Equal("guid,title,notebook,notebook_guid,tags,created,updated,content\n"+
+			"Note1,Note,Notebook1,GUID1,\"Tag1,Tag2\",2017-07-14T02:40:00Z,,Content\n",
+			write(CSVOutput, NewNoteDetails(n, DefaultNoteOption)), "Wrong CSV")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := NewFormatter("xml")
+		assert.Equal(ErrUnknownOutputFormat, err, "Wrong error returned")
+	})
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	assert := assert.New(t)
+	nbs := []*Notebook{&Notebook{GUID: "GUID1", Name: "Notebook1", Stack: "Stack"}}
+	created := time.Date(2018, 5, 1, 12, 30, 0, 0, time.Local).UnixNano() / int64(time.Millisecond)
+	notes := []*Note{
+		&Note{Title: "A very long note title", Notebook: &Notebook{GUID: "GUID1"}, Created: created, Tags: []*Tag{&Tag{Name: "Tag1"}, &Tag{Name: "Tag2"}}},
+		&Note{Title: "Note", Notebook: &Notebook{GUID: "GUID1"}},
+	}
+	write := func(text string, l *Listing) (string, error) {
+		f, err := NewTemplateFormatter(text)
+		if err != nil {
+			return "", err
+		}
+		buf := new(bytes.Buffer)
+		err = f.Write(buf, l)
+		return buf.String(), err
+	}
+
+	t.Run("notes", func(t *testing.T) {
+		out, err := write(`{{.Title | truncate 10}}\t{{.Notebook.Name}}\t{{.Created | date}}\t{{.Tags | join ", "}}`, NewNoteListing(notes, 0, nbs))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("A very ...\tNotebook1\t2018-05-01\tTag1, Tag2\nNote\tNotebook1\t\t\n", out, "Wrong output")
+		assert.Equal("GUID1", notes[0].Notebook.GUID, "Notes should not be changed")
+		assert.Equal("", notes[0].Notebook.Name, "Notes should not be changed")
+	})
+	t.Run("date layout", func(t *testing.T) {
+		out, err := write(`{{date "2006-01-02 15:04" .Created}}`, NewNoteListing(notes[:1], 0, nbs))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("2018-05-01 12:30\n", out, "Wrong output")
+	})
+	t.Run("notebooks", func(t *testing.T) {
+		out, err := write(`{{.Name}}\t{{.Stack}}`, NewNotebookListing(nbs))
+		assert.NoError(err, "Should not return an error")
+		assert.Equal("Notebook1\tStack\n", out, "Wrong output")
+	})
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := write(`{{.Title`, NewNotebookListing(nbs))
+		assert.Error(err, "Should return a parse error")
+	})
+	t.Run("invalid function argument", func(t *testing.T) {
+		_, err := write(`{{.Name | date}}`, NewNotebookListing(nbs))
+		assert.Error(err, "Should return an error for a non timestamp")
+		_, err = write(`{{.Name | join ","}}`, NewNotebookListing(nbs))
+		assert.Error(err, "Should return an error for a non list")
+	})
+}
+
+func TestTemplateTruncate(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("short", templateTruncate(10, "short"), "Short string should not be changed")
+	assert.Equal("åäöåä...", templateTruncate(8, "åäöåäöåäö"), "Wrong truncated string")
+	assert.Equal("ab", templateTruncate(2, "abcdef"), "Wrong truncated string without room for the ellipsis")
+}